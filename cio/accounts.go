@@ -0,0 +1,200 @@
+package cio
+
+// Api functions that support: accounts
+
+import (
+	"fmt"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// GetAccountsParams query values data struct.
+// Optional: Email, Status, StatusOK, Limit, Offset.
+type GetAccountsParams struct {
+	// Optional:
+	Email    string `json:"email,omitempty" valid:"email"`
+	Status   string `json:"status,omitempty"`
+	StatusOK string `json:"status_ok,omitempty" valid:"in(0|1)"`
+	Limit    int    `json:"limit,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+}
+
+// GetAccountsResponse data struct
+type GetAccountsResponse struct {
+	ID             string   `json:"id,omitempty"`
+	Username       string   `json:"username,omitempty"`
+	EmailAddresses []string `json:"email_addresses,omitempty"`
+	FirstName      string   `json:"first_name,omitempty"`
+	LastName       string   `json:"last_name,omitempty"`
+	ResourceURL    string   `json:"resource_url,omitempty"`
+
+	Sources []GetAccountSourcesResponse `json:"sources,omitempty"`
+
+	Created         ciolite.UnixTime `json:"created,omitempty"`
+	Suspended       ciolite.UnixTime `json:"suspended,omitempty"`
+	PasswordExpired int              `json:"password_expired,omitempty"`
+	NbMessages      int              `json:"nb_messages,omitempty"`
+	NbFiles         int              `json:"nb_files,omitempty"`
+}
+
+// CreateAccountParams form values data struct.
+// Requires: Email.
+// Optional: FirstName, LastName, MigrateAccountID,
+// and (if creating a source at the same time) the CreateAccountSourceParams.
+type CreateAccountParams struct {
+	// Requires:
+	Email string `json:"email" valid:"required,email"`
+
+	// Optional:
+	FirstName        string `json:"first_name,omitempty"`
+	LastName         string `json:"last_name,omitempty"`
+	MigrateAccountID string `json:"migrate_account_id,omitempty"`
+
+	// Optional, but Required for creating a Source at the same time:
+	Server   string `json:"server,omitempty"`
+	Username string `json:"username,omitempty"`
+	Type     string `json:"type,omitempty"`
+	UseSSL   bool   `json:"use_ssl,omitempty"`
+	Port     int    `json:"port,omitempty"`
+
+	// Optional, but Required for OAUTH:
+	ProviderRefreshToken string `json:"provider_refresh_token,omitempty"`
+	ProviderConsumerKey  string `json:"provider_consumer_key,omitempty"`
+
+	// Optional, but Required for non-OAUTH:
+	Password string `json:"password,omitempty"`
+
+	// Optional:
+	CallbackURL string `json:"callback_url,omitempty" valid:"url"`
+}
+
+// CreateAccountResponse data struct
+type CreateAccountResponse struct {
+	Success     bool   `json:"success,omitempty"`
+	ID          string `json:"id,omitempty"`
+	ResourceURL string `json:"resource_url,omitempty"`
+
+	Source CreateAccountSourceResponse `json:"source,omitempty"`
+}
+
+// ModifyAccountParams form values data struct.
+// Optional: FirstName, LastName.
+type ModifyAccountParams struct {
+	// Optional:
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+// ModifyAccountResponse data struct
+type ModifyAccountResponse struct {
+	Success     bool   `json:"success,omitempty"`
+	ResourceURL string `json:"resource_url,omitempty"`
+}
+
+// DeleteAccountResponse data struct
+type DeleteAccountResponse struct {
+	Success     bool   `json:"success,omitempty"`
+	ResourceURL string `json:"resource_url,omitempty"`
+}
+
+// GetAccounts gets a list of accounts.
+// queryValues may optionally contain Email, Status, StatusOK, Limit, Offset
+func (cio Cio) GetAccounts(queryValues GetAccountsParams) ([]GetAccountsResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        "/2.0/accounts",
+		QueryValues: queryValues,
+	}
+
+	// Make response
+	var response []GetAccountsResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// GetAccount gets details about a given account.
+func (cio Cio) GetAccount(accountID string) (GetAccountsResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method: "GET",
+		Path:   fmt.Sprintf("/2.0/accounts/%s", accountID),
+		UserID: accountID,
+	}
+
+	// Make response
+	var response GetAccountsResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// CreateAccount creates a new account.
+// formValues requires Email, and may optionally contain FirstName, LastName, MigrateAccountID,
+// and (if creating a source at the same time) Server, Username, UseSSL, Port, Type,
+// and (if OAUTH) ProviderRefreshToken and ProviderConsumerKey,
+// and (if not OAUTH) Password, and may optionally contain CallbackURL
+func (cio Cio) CreateAccount(formValues CreateAccountParams) (CreateAccountResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:     "POST",
+		Path:       "/2.0/accounts",
+		FormValues: formValues,
+	}
+
+	// Make response
+	var response CreateAccountResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// ModifyAccount modifies a given account.
+// formValues may optionally contain FirstName, LastName
+func (cio Cio) ModifyAccount(accountID string, formValues ModifyAccountParams) (ModifyAccountResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:     "POST",
+		Path:       fmt.Sprintf("/2.0/accounts/%s", accountID),
+		FormValues: formValues,
+		UserID:     accountID,
+	}
+
+	// Make response
+	var response ModifyAccountResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// DeleteAccount removes a given account.
+func (cio Cio) DeleteAccount(accountID string) (DeleteAccountResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/2.0/accounts/%s", accountID),
+		UserID: accountID,
+	}
+
+	// Make response
+	var response DeleteAccountResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
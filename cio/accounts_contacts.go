@@ -0,0 +1,95 @@
+package cio
+
+// Api functions that support: accounts/contacts
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// GetAccountContactsParams query values data struct.
+// Optional: Search, ActiveBefore, ActiveAfter, SortBy, SortOrder, Limit, Offset.
+type GetAccountContactsParams struct {
+	// Optional:
+	Search       string `json:"search,omitempty"`
+	ActiveBefore int    `json:"active_before,omitempty"`
+	ActiveAfter  int    `json:"active_after,omitempty"`
+	SortBy       string `json:"sort_by,omitempty" valid:"in(email|count|received_count|sent_count)"`
+	SortOrder    string `json:"sort_order,omitempty" valid:"in(asc|desc)"`
+	Limit        int    `json:"limit,omitempty"`
+	Offset       int    `json:"offset,omitempty"`
+}
+
+// GetAccountContactsResponse data struct
+type GetAccountContactsResponse struct {
+	Query GetAccountContactsQuery `json:"query,omitempty"`
+
+	Matches []GetAccountContactResponse `json:"matches,omitempty"`
+}
+
+// GetAccountContactsQuery embedded data struct within GetAccountContactsResponse
+type GetAccountContactsQuery struct {
+	Search string `json:"search,omitempty"`
+
+	ActiveBefore int `json:"active_before,omitempty"`
+	ActiveAfter  int `json:"active_after,omitempty"`
+	Limit        int `json:"limit,omitempty"`
+	Offset       int `json:"offset,omitempty"`
+}
+
+// GetAccountContactResponse data struct
+type GetAccountContactResponse struct {
+	Email       string   `json:"email,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Thumbnail   string   `json:"thumbnail,omitempty"`
+	ResourceURL string   `json:"resource_url,omitempty"`
+	Emails      []string `json:"emails,omitempty"`
+
+	Count         int              `json:"count,omitempty"`
+	SentCount     int              `json:"sent_count,omitempty"`
+	ReceivedCount int              `json:"received_count,omitempty"`
+	LastReceived  ciolite.UnixTime `json:"last_received,omitempty"`
+	LastSent      ciolite.UnixTime `json:"last_sent,omitempty"`
+}
+
+// GetAccountContacts gets a list of the contacts of an account.
+// queryValues may optionally contain Search, ActiveBefore, ActiveAfter, SortBy, SortOrder, Limit, Offset
+func (cio Cio) GetAccountContacts(accountID string, queryValues GetAccountContactsParams) (GetAccountContactsResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        fmt.Sprintf("/2.0/accounts/%s/contacts", accountID),
+		QueryValues: queryValues,
+		UserID:      accountID,
+	}
+
+	// Make response
+	var response GetAccountContactsResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// GetAccountContact gets a given contact of an account, by email address.
+func (cio Cio) GetAccountContact(accountID string, email string) (GetAccountContactResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method: "GET",
+		Path:   fmt.Sprintf("/2.0/accounts/%s/contacts/%s", accountID, url.QueryEscape(email)),
+		UserID: accountID,
+	}
+
+	// Make response
+	var response GetAccountContactResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
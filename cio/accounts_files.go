@@ -0,0 +1,102 @@
+package cio
+
+// Api functions that support: accounts/files
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// GetAccountFilesParams query values data struct.
+// Optional: FileName, FileSize (ex: ">1000"), Email, To, From, Cc, Bcc, DateBefore, DateAfter,
+// IndexedBefore, IndexedAfter, Source, SortOrder, Limit, Offset.
+type GetAccountFilesParams struct {
+	// Optional:
+	FileName string `json:"file_name,omitempty"`
+	FileSize string `json:"file_size,omitempty"`
+	Email    string `json:"email,omitempty"`
+	To       string `json:"to,omitempty"`
+	From     string `json:"from,omitempty"`
+	Cc       string `json:"cc,omitempty"`
+	Bcc      string `json:"bcc,omitempty"`
+	Source   string `json:"source,omitempty"`
+
+	DateBefore    int `json:"date_before,omitempty"`
+	DateAfter     int `json:"date_after,omitempty"`
+	IndexedBefore int `json:"indexed_before,omitempty"`
+	IndexedAfter  int `json:"indexed_after,omitempty"`
+
+	SortOrder string `json:"sort_order,omitempty" valid:"in(asc|desc)"`
+	Limit     int    `json:"limit,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+}
+
+// GetAccountFilesResponse data struct
+type GetAccountFilesResponse struct {
+	FileID         string `json:"file_id,omitempty"`
+	FileName       string `json:"file_name,omitempty"`
+	Type           string `json:"type,omitempty"`
+	Subject        string `json:"subject,omitempty"`
+	MessageID      string `json:"message_id,omitempty"`
+	EmailMessageID string `json:"email_message_id,omitempty"`
+	GmailMessageID string `json:"gmail_message_id,omitempty"`
+	GmailThreadID  string `json:"gmail_thread_id,omitempty"`
+	BodySection    string `json:"body_section,omitempty"`
+	ResourceURL    string `json:"resource_url,omitempty"`
+
+	FileNameStructure [][]string `json:"file_name_structure,omitempty"`
+
+	Addresses ciolite.WebhookMessageDataAddresses `json:"addresses,omitempty"`
+
+	PersonInfo ciolite.PersonInfo `json:"person_info,omitempty"`
+
+	Size        int              `json:"size,omitempty"`
+	Date        ciolite.UnixTime `json:"date,omitempty"`
+	DateIndexed ciolite.UnixTime `json:"date_indexed,omitempty"`
+
+	IsEmbedded       bool `json:"is_embedded,omitempty"`
+	IsTNEFAttachment bool `json:"is_tnef_attachment,omitempty"`
+}
+
+// GetAccountFiles gets a list of the files (attachments) of an account.
+// queryValues may optionally contain FileName, FileSize, Email, To, From, Cc, Bcc, DateBefore, DateAfter,
+// IndexedBefore, IndexedAfter, Source, SortOrder, Limit, Offset
+func (cio Cio) GetAccountFiles(accountID string, queryValues GetAccountFilesParams) ([]GetAccountFilesResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        fmt.Sprintf("/2.0/accounts/%s/files", accountID),
+		QueryValues: queryValues,
+		UserID:      accountID,
+	}
+
+	// Make response
+	var response []GetAccountFilesResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// GetAccountFile gets details about a given file of an account.
+func (cio Cio) GetAccountFile(accountID string, fileID string) (GetAccountFilesResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method: "GET",
+		Path:   fmt.Sprintf("/2.0/accounts/%s/files/%s", accountID, url.QueryEscape(fileID)),
+		UserID: accountID,
+	}
+
+	// Make response
+	var response GetAccountFilesResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
@@ -0,0 +1,146 @@
+package cio
+
+// Api functions that support: accounts/messages
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// GetAccountMessagesParams query values data struct.
+// Optional: Subject, Email, To, From, Cc, Bcc, FolderID, Source, FileName, FileSize (ex: ">1000"),
+// DateBefore, DateAfter, IndexedBefore, IndexedAfter, IncludeThreadSize, IncludeBody, BodyType,
+// IncludeHeaders, IncludeFlags, IncludeSource, SortOrder, Limit, Offset.
+type GetAccountMessagesParams struct {
+	// Optional:
+	Subject  string `json:"subject,omitempty"`
+	Email    string `json:"email,omitempty"`
+	To       string `json:"to,omitempty"`
+	From     string `json:"from,omitempty"`
+	Cc       string `json:"cc,omitempty"`
+	Bcc      string `json:"bcc,omitempty"`
+	FolderID string `json:"folder,omitempty"`
+	Source   string `json:"source,omitempty"`
+	FileName string `json:"file_name,omitempty"`
+	FileSize string `json:"file_size,omitempty"`
+
+	DateBefore    int `json:"date_before,omitempty"`
+	DateAfter     int `json:"date_after,omitempty"`
+	IndexedBefore int `json:"indexed_before,omitempty"`
+	IndexedAfter  int `json:"indexed_after,omitempty"`
+
+	IncludeThreadSize bool   `json:"include_thread_size,omitempty"`
+	IncludeBody       bool   `json:"include_body,omitempty"`
+	BodyType          string `json:"body_type,omitempty" valid:"in(text/plain|text/html)"`
+	IncludeFlags      bool   `json:"include_flags,omitempty"`
+	IncludeSource     bool   `json:"include_source,omitempty"`
+
+	// IncludeHeaders can be "0", "1", or "raw"
+	IncludeHeaders string `json:"include_headers,omitempty" valid:"in(0|1|raw)"`
+
+	SortOrder string `json:"sort_order,omitempty" valid:"in(asc|desc)"`
+	Limit     int    `json:"limit,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+}
+
+// GetAccountMessageParams query values data struct.
+// Optional: IncludeThreadSize, IncludeBody, BodyType, IncludeHeaders, IncludeFlags, IncludeSource.
+type GetAccountMessageParams struct {
+	// Optional:
+	IncludeThreadSize bool   `json:"include_thread_size,omitempty"`
+	IncludeBody       bool   `json:"include_body,omitempty"`
+	BodyType          string `json:"body_type,omitempty" valid:"in(text/plain|text/html)"`
+	IncludeFlags      bool   `json:"include_flags,omitempty"`
+	IncludeSource     bool   `json:"include_source,omitempty"`
+
+	// IncludeHeaders can be "0", "1", or "raw"
+	IncludeHeaders string `json:"include_headers,omitempty" valid:"in(0|1|raw)"`
+}
+
+// GetAccountMessagesResponse data struct.
+// The addresses, person info, flags, sources, files, and bodies have the same shape as in CIO Lite webhooks.
+type GetAccountMessagesResponse struct {
+	MessageID      string `json:"message_id,omitempty"`
+	EmailMessageID string `json:"email_message_id,omitempty"`
+	GmailMessageID string `json:"gmail_message_id,omitempty"`
+	GmailThreadID  string `json:"gmail_thread_id,omitempty"`
+	Subject        string `json:"subject,omitempty"`
+	InReplyTo      string `json:"in_reply_to,omitempty"`
+	ResourceURL    string `json:"resource_url,omitempty"`
+
+	Folders    []string `json:"folders,omitempty"`
+	References []string `json:"references,omitempty"`
+
+	ListHeaders ciolite.ListHeaders `json:"list_headers,omitempty"`
+
+	Addresses ciolite.WebhookMessageDataAddresses `json:"addresses,omitempty"`
+
+	PersonInfo ciolite.PersonInfo `json:"person_info,omitempty"`
+
+	// Flags is only present if IncludeFlags was set
+	Flags ciolite.WebhookMessageDataFlags `json:"flags,omitempty"`
+
+	Sources []ciolite.WebhookMessageDataAccount `json:"sources,omitempty"`
+
+	Files []ciolite.WebhookMessageDataFile `json:"files,omitempty"`
+
+	// Body is only present if IncludeBody was set
+	Body []ciolite.WebhookBody `json:"body,omitempty"`
+
+	// Headers is only present if IncludeHeaders was set (raw headers are returned as a string)
+	Headers interface{} `json:"headers,omitempty"`
+
+	Date         ciolite.UnixTime `json:"date,omitempty"`
+	DateIndexed  ciolite.UnixTime `json:"date_indexed,omitempty"`
+	DateReceived ciolite.UnixTime `json:"date_received,omitempty"`
+
+	// ThreadSize is only present if IncludeThreadSize was set
+	ThreadSize int `json:"thread_size,omitempty"`
+}
+
+// GetAccountMessages gets a list of the messages of an account.
+// queryValues may optionally contain Subject, Email, To, From, Cc, Bcc, FolderID, Source, FileName, FileSize,
+// DateBefore, DateAfter, IndexedBefore, IndexedAfter, IncludeThreadSize, IncludeBody, BodyType,
+// IncludeHeaders, IncludeFlags, IncludeSource, SortOrder, Limit, Offset
+func (cio Cio) GetAccountMessages(accountID string, queryValues GetAccountMessagesParams) ([]GetAccountMessagesResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        fmt.Sprintf("/2.0/accounts/%s/messages", accountID),
+		QueryValues: queryValues,
+		UserID:      accountID,
+	}
+
+	// Make response
+	var response []GetAccountMessagesResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// GetAccountMessage gets a given message of an account.
+// messageID can be the message_id, or the email_message_id.
+// queryValues may optionally contain IncludeThreadSize, IncludeBody, BodyType, IncludeHeaders, IncludeFlags, IncludeSource
+func (cio Cio) GetAccountMessage(accountID string, messageID string, queryValues GetAccountMessageParams) (GetAccountMessagesResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        fmt.Sprintf("/2.0/accounts/%s/messages/%s", accountID, url.QueryEscape(messageID)),
+		QueryValues: queryValues,
+		UserID:      accountID,
+	}
+
+	// Make response
+	var response GetAccountMessagesResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
@@ -0,0 +1,213 @@
+package cio
+
+// Api functions that support: accounts/sources
+
+import (
+	"fmt"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// GetAccountSourcesParams query values data struct.
+// Optional: Status, StatusOK.
+type GetAccountSourcesParams struct {
+	// Optional:
+	Status   string `json:"status,omitempty"`
+	StatusOK string `json:"status_ok,omitempty" valid:"in(0|1)"`
+}
+
+// GetAccountSourcesResponse data struct
+type GetAccountSourcesResponse struct {
+	Status             ciolite.AccountStatus      `json:"status,omitempty"`
+	StatusMessage      string                     `json:"status_message,omitempty"`
+	ResourceURL        string                     `json:"resource_url,omitempty"`
+	Type               ciolite.AccountType        `json:"type,omitempty"`
+	AuthenticationType ciolite.AuthenticationType `json:"authentication_type,omitempty"`
+	Server             string                     `json:"server,omitempty"`
+	Label              string                     `json:"label,omitempty"`
+	Username           string                     `json:"username,omitempty"`
+	SyncPeriod         string                     `json:"sync_period,omitempty"`
+
+	UseSSL bool `json:"use_ssl,omitempty"`
+
+	Port                 int              `json:"port,omitempty"`
+	SyncFlags            int              `json:"sync_flags,omitempty"`
+	ExpungeOnDeletedFlag int              `json:"expunge_on_deleted_flag,omitempty"`
+	StatusUpdated        ciolite.UnixTime `json:"status_updated,omitempty"`
+}
+
+// CreateAccountSourceParams form values data struct.
+// Requires: Email, Server, Username, UseSSL, Port, Type,
+// and (if OAUTH) ProviderRefreshToken and ProviderConsumerKey,
+// and (if not OAUTH) Password.
+// Optional: SyncAllFolders, ExpungeOnDeletedFlag, CallbackURL, StatusCallbackURL.
+type CreateAccountSourceParams struct {
+	// Requires:
+	Email    string `json:"email" valid:"required,email"`
+	Server   string `json:"server" valid:"required"`
+	Username string `json:"username" valid:"required"`
+	Type     string `json:"type" valid:"required"`
+	UseSSL   bool   `json:"use_ssl"`
+	Port     int    `json:"port" valid:"required"`
+
+	// Optional, but Required for OAUTH:
+	ProviderRefreshToken string `json:"provider_refresh_token,omitempty"`
+	ProviderConsumerKey  string `json:"provider_consumer_key,omitempty"`
+
+	// Optional, but Required for non-OAUTH:
+	Password string `json:"password,omitempty"`
+
+	// Optional:
+	SyncAllFolders       bool   `json:"sync_all_folders,omitempty"`
+	ExpungeOnDeletedFlag bool   `json:"expunge_on_deleted_flag,omitempty"`
+	CallbackURL          string `json:"callback_url,omitempty" valid:"url"`
+	StatusCallbackURL    string `json:"status_callback_url,omitempty" valid:"url"`
+}
+
+// CreateAccountSourceResponse data struct
+type CreateAccountSourceResponse struct {
+	Success     bool   `json:"success,omitempty"`
+	Label       string `json:"label,omitempty"`
+	ResourceURL string `json:"resource_url,omitempty"`
+
+	ConnectionLog string `json:"connection_log,omitempty"`
+	FeedbackCode  string `json:"feedback_code,omitempty"`
+}
+
+// ModifyAccountSourceParams form values data struct.
+// Optional: Status, SyncAllFolders, ExpungeOnDeletedFlag, Password, ProviderRefreshToken, ProviderConsumerKey,
+// StatusCallbackURL, ForceStatusCheck.
+type ModifyAccountSourceParams struct {
+	// Optional:
+	Status               string `json:"status,omitempty"`
+	SyncAllFolders       bool   `json:"sync_all_folders,omitempty"`
+	ExpungeOnDeletedFlag bool   `json:"expunge_on_deleted_flag,omitempty"`
+	Password             string `json:"password,omitempty"`
+	ProviderRefreshToken string `json:"provider_refresh_token,omitempty"`
+	ProviderConsumerKey  string `json:"provider_consumer_key,omitempty"`
+	StatusCallbackURL    string `json:"status_callback_url,omitempty" valid:"url"`
+	ForceStatusCheck     bool   `json:"force_status_check,omitempty"`
+}
+
+// ModifyAccountSourceResponse data struct
+type ModifyAccountSourceResponse struct {
+	Success       bool   `json:"success,omitempty"`
+	ResourceURL   string `json:"resource_url,omitempty"`
+	FeedbackCode  string `json:"feedback_code,omitempty"`
+	ConnectionLog string `json:"connection_log,omitempty"`
+}
+
+// DeleteAccountSourceResponse data struct
+type DeleteAccountSourceResponse struct {
+	Success      bool   `json:"success,omitempty"`
+	ResourceURL  string `json:"resource_url,omitempty"`
+	FeedbackCode string `json:"feedback_code,omitempty"`
+}
+
+// GetAccountSources gets a list of the sources (email accounts) of an account.
+// queryValues may optionally contain Status, StatusOK
+func (cio Cio) GetAccountSources(accountID string, queryValues GetAccountSourcesParams) ([]GetAccountSourcesResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        fmt.Sprintf("/2.0/accounts/%s/sources", accountID),
+		QueryValues: queryValues,
+		UserID:      accountID,
+	}
+
+	// Make response
+	var response []GetAccountSourcesResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// GetAccountSource gets details about a given source of an account.
+func (cio Cio) GetAccountSource(accountID string, label string) (GetAccountSourcesResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:       "GET",
+		Path:         fmt.Sprintf("/2.0/accounts/%s/sources/%s", accountID, label),
+		UserID:       accountID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response GetAccountSourcesResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// CreateAccountSource adds a source (email account) to an account.
+// formValues requires Email, Server, Username, UseSSL, Port, Type,
+// and (if OAUTH) ProviderRefreshToken and ProviderConsumerKey,
+// and (if not OAUTH) Password, and may optionally contain SyncAllFolders,
+// ExpungeOnDeletedFlag, CallbackURL, StatusCallbackURL
+func (cio Cio) CreateAccountSource(accountID string, formValues CreateAccountSourceParams) (CreateAccountSourceResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:     "POST",
+		Path:       fmt.Sprintf("/2.0/accounts/%s/sources", accountID),
+		FormValues: formValues,
+		UserID:     accountID,
+	}
+
+	// Make response
+	var response CreateAccountSourceResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// ModifyAccountSource modifies a given source of an account.
+// formValues may optionally contain Status, SyncAllFolders, ExpungeOnDeletedFlag, Password,
+// ProviderRefreshToken, ProviderConsumerKey, StatusCallbackURL, ForceStatusCheck
+func (cio Cio) ModifyAccountSource(accountID string, label string, formValues ModifyAccountSourceParams) (ModifyAccountSourceResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:       "POST",
+		Path:         fmt.Sprintf("/2.0/accounts/%s/sources/%s", accountID, label),
+		FormValues:   formValues,
+		UserID:       accountID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response ModifyAccountSourceResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// DeleteAccountSource removes a given source from an account.
+func (cio Cio) DeleteAccountSource(accountID string, label string) (DeleteAccountSourceResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:       "DELETE",
+		Path:         fmt.Sprintf("/2.0/accounts/%s/sources/%s", accountID, label),
+		UserID:       accountID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response DeleteAccountSourceResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
@@ -0,0 +1,105 @@
+package cio
+
+// Api functions that support: accounts/sync and accounts/sources/sync
+
+import (
+	"fmt"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// GetAccountSyncResponse data struct, of the sync status of each folder by source label, and then by folder name
+type GetAccountSyncResponse map[string]map[string]FolderSyncStatus
+
+// FolderSyncStatus data struct
+type FolderSyncStatus struct {
+	InitialImportFinished bool `json:"initial_import_finished,omitempty"`
+
+	LastSyncStart ciolite.UnixTime `json:"last_sync_start,omitempty"`
+	LastSyncStop  ciolite.UnixTime `json:"last_sync_stop,omitempty"`
+	LastExpunge   ciolite.UnixTime `json:"last_expunge,omitempty"`
+}
+
+// SyncAccountResponse data struct
+type SyncAccountResponse struct {
+	Success     bool   `json:"success,omitempty"`
+	ResourceURL string `json:"resource_url,omitempty"`
+}
+
+// GetAccountSync gets the sync status of every source of an account.
+func (cio Cio) GetAccountSync(accountID string) (GetAccountSyncResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method: "GET",
+		Path:   fmt.Sprintf("/2.0/accounts/%s/sync", accountID),
+		UserID: accountID,
+	}
+
+	// Make response
+	var response GetAccountSyncResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// SyncAccount triggers a sync of every source of an account.
+func (cio Cio) SyncAccount(accountID string) (SyncAccountResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method: "POST",
+		Path:   fmt.Sprintf("/2.0/accounts/%s/sync", accountID),
+		UserID: accountID,
+	}
+
+	// Make response
+	var response SyncAccountResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// GetAccountSourceSync gets the sync status of a given source of an account.
+func (cio Cio) GetAccountSourceSync(accountID string, label string) (GetAccountSyncResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:       "GET",
+		Path:         fmt.Sprintf("/2.0/accounts/%s/sources/%s/sync", accountID, label),
+		UserID:       accountID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response GetAccountSyncResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// SyncAccountSource triggers a sync of a given source of an account.
+func (cio Cio) SyncAccountSource(accountID string, label string) (SyncAccountResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:       "POST",
+		Path:         fmt.Sprintf("/2.0/accounts/%s/sources/%s/sync", accountID, label),
+		UserID:       accountID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response SyncAccountResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
@@ -0,0 +1,275 @@
+package cio
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestSimulatedAccounts tests the accounts endpoints with a simulated server
+func TestSimulatedAccounts(t *testing.T) {
+	t.Parallel()
+
+	cio, testServer, mux := NewTestCioWithTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/2.0/accounts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if r.FormValue("email") != "jane@example.com" || r.FormValue("limit") != "5" {
+				t.Error("Unexpected query: ", r.URL.RawQuery)
+			}
+			_, err := io.WriteString(w, `[{"id": "a1", "email_addresses": ["jane@example.com"], "nb_messages": 12,
+				"sources": [{"label": "jane::imap.example.com", "status": "OK", "use_ssl": true, "port": 993}]}]`)
+			Must(err)
+		case "POST":
+			if r.FormValue("email") != "jane@example.com" || r.FormValue("server") != "imap.example.com" || r.FormValue("use_ssl") != "1" {
+				t.Error("Unexpected form: ", r.Form)
+			}
+			_, err := io.WriteString(w, `{"success": true, "id": "a1", "source": {"success": true, "label": "jane::imap.example.com"}}`)
+			Must(err)
+		}
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			_, err := io.WriteString(w, `{"id": "a1", "first_name": "Jane"}`)
+			Must(err)
+		case "POST":
+			if r.FormValue("last_name") != "Doe" {
+				t.Error("Unexpected form: ", r.Form)
+			}
+			_, err := io.WriteString(w, `{"success": true}`)
+			Must(err)
+		case "DELETE":
+			_, err := io.WriteString(w, `{"success": true}`)
+			Must(err)
+		}
+	})
+
+	accounts, err := cio.GetAccounts(GetAccountsParams{Email: "jane@example.com", Limit: 5})
+	if err != nil || len(accounts) != 1 || accounts[0].NbMessages != 12 || len(accounts[0].Sources) != 1 || !accounts[0].Sources[0].UseSSL {
+		t.Errorf("Unexpected accounts: %+v %v", accounts, err)
+	}
+
+	account, err := cio.GetAccount("a1")
+	if err != nil || account.FirstName != "Jane" {
+		t.Errorf("Unexpected account: %+v %v", account, err)
+	}
+
+	created, err := cio.CreateAccount(CreateAccountParams{Email: "jane@example.com", Server: "imap.example.com", UseSSL: true, Port: 993})
+	if err != nil || created.ID != "a1" || created.Source.Label != "jane::imap.example.com" {
+		t.Errorf("Unexpected created account: %+v %v", created, err)
+	}
+
+	modified, err := cio.ModifyAccount("a1", ModifyAccountParams{LastName: "Doe"})
+	if err != nil || !modified.Success {
+		t.Errorf("Unexpected modified account: %+v %v", modified, err)
+	}
+
+	deleted, err := cio.DeleteAccount("a1")
+	if err != nil || !deleted.Success {
+		t.Errorf("Unexpected deleted account: %+v %v", deleted, err)
+	}
+}
+
+// TestSimulatedAccountMessagesAndThreads tests the messages and threads endpoints with a simulated server
+func TestSimulatedAccountMessagesAndThreads(t *testing.T) {
+	t.Parallel()
+
+	cio, testServer, mux := NewTestCioWithTestServer(t)
+	defer testServer.Close()
+
+	message := `{"message_id": "m1", "email_message_id": "<1@x>", "gmail_thread_id": "gm-99", "subject": "Hello",
+		"addresses": {"from": {"email": "jane@example.com", "name": "Jane"}, "to": [{"email": "joe@example.com"}]},
+		"sources": [{"label": "0", "folder": "INBOX", "uid": 7}], "body": [{"type": "text/plain", "content": "Hi"}], "date": 1467254577}`
+
+	mux.HandleFunc("/2.0/accounts/a1/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("include_body") != "1" || r.FormValue("sort_order") != "desc" {
+			t.Error("Unexpected query: ", r.URL.RawQuery)
+		}
+		_, err := io.WriteString(w, "["+message+"]")
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/messages/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2.0/accounts/a1/messages/<1@x>" {
+			t.Error("Unexpected path: ", r.URL.Path)
+		}
+		_, err := io.WriteString(w, message)
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/threads", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `["https://api.context.io/2.0/accounts/a1/threads/gm-99"]`)
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/threads/gm-99", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `{"email_message_ids": ["<1@x>"], "messages": [`+message+`]}`)
+		Must(err)
+	})
+
+	messages, err := cio.GetAccountMessages("a1", GetAccountMessagesParams{IncludeBody: true, SortOrder: "desc"})
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+	if messages[0].Addresses.From.Email != "jane@example.com" || len(messages[0].Addresses.To) != 1 ||
+		messages[0].Sources[0].UID != 7 || messages[0].Body[0].Content != "Hi" || messages[0].Date != 1467254577 {
+		t.Errorf("Unexpected message: %+v", messages[0])
+	}
+
+	single, err := cio.GetAccountMessage("a1", "<1@x>", GetAccountMessageParams{})
+	if err != nil || !reflect.DeepEqual(single, messages[0]) {
+		t.Errorf("Unexpected message: %+v %v", single, err)
+	}
+
+	threads, err := cio.GetAccountThreads("a1", GetAccountThreadsParams{})
+	if err != nil || len(threads) != 1 {
+		t.Errorf("Unexpected threads: %+v %v", threads, err)
+	}
+
+	thread, err := cio.GetAccountThread("a1", "gm-99", GetAccountThreadParams{})
+	if err != nil || len(thread.Messages) != 1 || thread.Messages[0].GmailThreadID != "gm-99" {
+		t.Errorf("Unexpected thread: %+v %v", thread, err)
+	}
+}
+
+// TestSimulatedAccountContactsFilesAndSources tests the contacts, files, and sources endpoints with a simulated server
+func TestSimulatedAccountContactsFilesAndSources(t *testing.T) {
+	t.Parallel()
+
+	cio, testServer, mux := NewTestCioWithTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/2.0/accounts/a1/contacts", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `{"query": {"search": "jo", "limit": 25}, "matches": [{"email": "joe@example.com", "count": 3}]}`)
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/contacts/joe@example.com", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `{"email": "joe@example.com", "name": "Joe", "sent_count": 2}`)
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("file_name") != "*.pdf" {
+			t.Error("Unexpected query: ", r.URL.RawQuery)
+		}
+		_, err := io.WriteString(w, `[{"file_id": "f1", "file_name": "a.pdf", "size": 1024, "file_name_structure": [["a", "main"], [".pdf", "ext"]]}]`)
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/files/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/2.0/accounts/a1/files/f%2F1" {
+			t.Error("Unexpected path: ", r.URL.EscapedPath())
+		}
+		_, err := io.WriteString(w, `{"file_id": "f/1", "file_name": "a.pdf"}`)
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/sources", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if r.FormValue("password") != "hunter2" || r.FormValue("port") != "993" {
+				t.Error("Unexpected form: ", r.Form)
+			}
+			_, err := io.WriteString(w, `{"success": true, "label": "1"}`)
+			Must(err)
+			return
+		}
+		_, err := io.WriteString(w, `[{"label": "0", "status": "OK"}]`)
+		Must(err)
+	})
+
+	mux.HandleFunc("/2.0/accounts/a1/sources/1", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+
+	contacts, err := cio.GetAccountContacts("a1", GetAccountContactsParams{Search: "jo"})
+	if err != nil || contacts.Query.Search != "jo" || len(contacts.Matches) != 1 || contacts.Matches[0].Count != 3 {
+		t.Errorf("Unexpected contacts: %+v %v", contacts, err)
+	}
+
+	contact, err := cio.GetAccountContact("a1", "joe@example.com")
+	if err != nil || contact.Name != "Joe" || contact.SentCount != 2 {
+		t.Errorf("Unexpected contact: %+v %v", contact, err)
+	}
+
+	files, err := cio.GetAccountFiles("a1", GetAccountFilesParams{FileName: "*.pdf"})
+	if err != nil || len(files) != 1 || files[0].Size != 1024 || len(files[0].FileNameStructure) != 2 {
+		t.Errorf("Unexpected files: %+v %v", files, err)
+	}
+
+	file, err := cio.GetAccountFile("a1", "f/1")
+	if err != nil || file.FileName != "a.pdf" {
+		t.Errorf("Unexpected file: %+v %v", file, err)
+	}
+
+	sources, err := cio.GetAccountSources("a1", GetAccountSourcesParams{})
+	if err != nil || len(sources) != 1 || sources[0].Status != "OK" {
+		t.Errorf("Unexpected sources: %+v %v", sources, err)
+	}
+
+	created, err := cio.CreateAccountSource("a1", CreateAccountSourceParams{
+		Email: "jane@example.com", Server: "imap.example.com", Username: "jane", Type: "IMAP", UseSSL: true, Port: 993, Password: "hunter2"})
+	if err != nil || created.Label != "1" {
+		t.Errorf("Unexpected created source: %+v %v", created, err)
+	}
+
+	modified, err := cio.ModifyAccountSource("a1", "1", ModifyAccountSourceParams{ForceStatusCheck: true})
+	if err != nil || !modified.Success {
+		t.Errorf("Unexpected modified source: %+v %v", modified, err)
+	}
+
+	deleted, err := cio.DeleteAccountSource("a1", "1")
+	if err != nil || !deleted.Success {
+		t.Errorf("Unexpected deleted source: %+v %v", deleted, err)
+	}
+}
+
+// TestSimulatedAccountSync tests the sync endpoints with a simulated server
+func TestSimulatedAccountSync(t *testing.T) {
+	t.Parallel()
+
+	cio, testServer, mux := NewTestCioWithTestServer(t)
+	defer testServer.Close()
+
+	var synced []string
+	status := `{"0": {"INBOX": {"initial_import_finished": true, "last_sync_start": 1467254500, "last_sync_stop": 1467254577}}}`
+	for _, path := range []string{"/2.0/accounts/a1/sync", "/2.0/accounts/a1/sources/0/sync"} {
+		path := path
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "POST" {
+				synced = append(synced, path)
+				_, err := io.WriteString(w, `{"success": true, "resource_url": "https://api.context.io`+path+`"}`)
+				Must(err)
+				return
+			}
+			_, err := io.WriteString(w, status)
+			Must(err)
+		})
+	}
+
+	expected := GetAccountSyncResponse{"0": {"INBOX": {InitialImportFinished: true, LastSyncStart: 1467254500, LastSyncStop: 1467254577}}}
+	accountSync, err := cio.GetAccountSync("a1")
+	if err != nil || !reflect.DeepEqual(accountSync, expected) {
+		t.Errorf("Unexpected account sync: %+v %v", accountSync, err)
+	}
+	sourceSync, err := cio.GetAccountSourceSync("a1", "0")
+	if err != nil || !reflect.DeepEqual(sourceSync, expected) {
+		t.Errorf("Unexpected source sync: %+v %v", sourceSync, err)
+	}
+
+	if response, err := cio.SyncAccount("a1"); err != nil || !response.Success {
+		t.Errorf("Unexpected account sync trigger: %+v %v", response, err)
+	}
+	if response, err := cio.SyncAccountSource("a1", "0"); err != nil || !response.Success {
+		t.Errorf("Unexpected source sync trigger: %+v %v", response, err)
+	}
+	if !reflect.DeepEqual(synced, []string{"/2.0/accounts/a1/sync", "/2.0/accounts/a1/sources/0/sync"}) {
+		t.Error("Expected a POST to each sync endpoint; Got: ", synced)
+	}
+}
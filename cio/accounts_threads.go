@@ -0,0 +1,102 @@
+package cio
+
+// Api functions that support: accounts/threads
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// GetAccountThreadsParams query values data struct.
+// Optional: Subject, Email, To, From, Cc, Bcc, FolderID, IndexedBefore, IndexedAfter, ActiveBefore, ActiveAfter,
+// StartedBefore, StartedAfter, Limit, Offset.
+type GetAccountThreadsParams struct {
+	// Optional:
+	Subject  string `json:"subject,omitempty"`
+	Email    string `json:"email,omitempty"`
+	To       string `json:"to,omitempty"`
+	From     string `json:"from,omitempty"`
+	Cc       string `json:"cc,omitempty"`
+	Bcc      string `json:"bcc,omitempty"`
+	FolderID string `json:"folder,omitempty"`
+
+	IndexedBefore int `json:"indexed_before,omitempty"`
+	IndexedAfter  int `json:"indexed_after,omitempty"`
+	ActiveBefore  int `json:"active_before,omitempty"`
+	ActiveAfter   int `json:"active_after,omitempty"`
+	StartedBefore int `json:"started_before,omitempty"`
+	StartedAfter  int `json:"started_after,omitempty"`
+
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+// GetAccountThreadParams query values data struct.
+// Optional: IncludeBody, IncludeHeaders, IncludeFlags, BodyType, Limit, Offset.
+type GetAccountThreadParams struct {
+	// Optional:
+	IncludeBody  bool   `json:"include_body,omitempty"`
+	IncludeFlags bool   `json:"include_flags,omitempty"`
+	BodyType     string `json:"body_type,omitempty" valid:"in(text/plain|text/html)"`
+
+	// IncludeHeaders can be "0", "1", or "raw"
+	IncludeHeaders string `json:"include_headers,omitempty" valid:"in(0|1|raw)"`
+
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+// GetAccountThreadResponse data struct
+type GetAccountThreadResponse struct {
+	EmailMessageIDs []string `json:"email_message_ids,omitempty"`
+
+	PersonInfo ciolite.PersonInfo `json:"person_info,omitempty"`
+
+	Messages []GetAccountMessagesResponse `json:"messages,omitempty"`
+}
+
+// GetAccountThreads gets a list of the threads of an account, as the resource url of each thread.
+// queryValues may optionally contain Subject, Email, To, From, Cc, Bcc, FolderID, IndexedBefore, IndexedAfter,
+// ActiveBefore, ActiveAfter, StartedBefore, StartedAfter, Limit, Offset
+func (cio Cio) GetAccountThreads(accountID string, queryValues GetAccountThreadsParams) ([]string, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        fmt.Sprintf("/2.0/accounts/%s/threads", accountID),
+		QueryValues: queryValues,
+		UserID:      accountID,
+	}
+
+	// Make response
+	var response []string
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
+
+// GetAccountThread gets the messages of a given thread of an account.
+// threadID can be the gmail_thread_id (ex: gm-1234), or the message_id or email_message_id of any message in the thread.
+// queryValues may optionally contain IncludeBody, IncludeHeaders, IncludeFlags, BodyType, Limit, Offset
+func (cio Cio) GetAccountThread(accountID string, threadID string, queryValues GetAccountThreadParams) (GetAccountThreadResponse, error) {
+
+	// Make request
+	request := ciolite.ClientRequest{
+		Method:      "GET",
+		Path:        fmt.Sprintf("/2.0/accounts/%s/threads/%s", accountID, url.QueryEscape(threadID)),
+		QueryValues: queryValues,
+		UserID:      accountID,
+	}
+
+	// Make response
+	var response GetAccountThreadResponse
+
+	// Request
+	err := cio.Client.DoFormRequest(request, &response)
+
+	return response, err
+}
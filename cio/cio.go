@@ -0,0 +1,80 @@
+// Package cio is the Golang client library for the Context.IO 2.0 API.
+// It is built on the same request signing, params, hooks, and errors (ciolite.RequestError) as package ciolite.
+package cio
+
+//go:generate mockgen -source cio.go -destination cio_mock.go -package cio
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// Cio struct contains the CIO Lite client used to sign and send all requests,
+// and provides convenience functions for accessing all CIO 2.0 endpoints.
+type Cio struct {
+	// Client signs and sends the requests.
+	// Its Host, HTTPClient, and hooks (ex: PreRequestHook) may be set as for CIO Lite,
+	// with the hooks receiving the Account ID as the User ID, and the Source Label as the Account Label.
+	Client ciolite.CioLite
+}
+
+// NewCio returns a CIO struct (without a logger) for accessing the CIO 2.0 API.
+func NewCio(key string, secret string) Cio {
+	return Cio{Client: ciolite.NewCioLite(key, secret)}
+}
+
+// New returns a CIO struct for accessing the CIO 2.0 API, with the CIO Lite options (ex: ciolite.WithTimeout) applied.
+func New(key string, secret string, opts ...ciolite.Option) (Cio, error) {
+	client, err := ciolite.New(key, secret, opts...)
+	return Cio{Client: client}, err
+}
+
+// Interface is just to help generate a mocked client, for testing elsewhere.
+// mockgen -source=cio.go -destination=cio_mock.go -package cio
+type Interface interface {
+	ValidateCallback(token string, signature string, timestamp int) bool
+
+	GetAccounts(queryValues GetAccountsParams) ([]GetAccountsResponse, error)
+	GetAccount(accountID string) (GetAccountsResponse, error)
+	CreateAccount(formValues CreateAccountParams) (CreateAccountResponse, error)
+	ModifyAccount(accountID string, formValues ModifyAccountParams) (ModifyAccountResponse, error)
+	DeleteAccount(accountID string) (DeleteAccountResponse, error)
+
+	GetAccountContacts(accountID string, queryValues GetAccountContactsParams) (GetAccountContactsResponse, error)
+	GetAccountContact(accountID string, email string) (GetAccountContactResponse, error)
+
+	GetAccountFiles(accountID string, queryValues GetAccountFilesParams) ([]GetAccountFilesResponse, error)
+	GetAccountFile(accountID string, fileID string) (GetAccountFilesResponse, error)
+
+	GetAccountMessages(accountID string, queryValues GetAccountMessagesParams) ([]GetAccountMessagesResponse, error)
+	GetAccountMessage(accountID string, messageID string, queryValues GetAccountMessageParams) (GetAccountMessagesResponse, error)
+
+	GetAccountSources(accountID string, queryValues GetAccountSourcesParams) ([]GetAccountSourcesResponse, error)
+	GetAccountSource(accountID string, label string) (GetAccountSourcesResponse, error)
+	CreateAccountSource(accountID string, formValues CreateAccountSourceParams) (CreateAccountSourceResponse, error)
+	ModifyAccountSource(accountID string, label string, formValues ModifyAccountSourceParams) (ModifyAccountSourceResponse, error)
+	DeleteAccountSource(accountID string, label string) (DeleteAccountSourceResponse, error)
+
+	GetAccountThreads(accountID string, queryValues GetAccountThreadsParams) ([]string, error)
+	GetAccountThread(accountID string, threadID string, queryValues GetAccountThreadParams) (GetAccountThreadResponse, error)
+
+	GetAccountSync(accountID string) (GetAccountSyncResponse, error)
+	SyncAccount(accountID string) (SyncAccountResponse, error)
+	GetAccountSourceSync(accountID string, label string) (GetAccountSyncResponse, error)
+	SyncAccountSource(accountID string, label string) (SyncAccountResponse, error)
+}
+
+// NewTestCioServer is a convenience function that returns a Cio object
+// and a *httptest.Server (which must be closed when done being used).
+// The Cio instance will hit the test server for all requests.
+func NewTestCioServer(handler http.Handler) (Cio, *httptest.Server) {
+	client, testServer := ciolite.NewTestCioLiteServer(handler)
+	return Cio{Client: client}, testServer
+}
+
+// ValidateCallback returns true if this Webhook Callback authenticates
+func (cio Cio) ValidateCallback(token string, signature string, timestamp int) bool {
+	return cio.Client.ValidateCallback(token, signature, timestamp)
+}
@@ -0,0 +1,331 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cio.go
+
+// Package cio is a generated GoMock package.
+package cio
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockInterface is a mock of Interface interface
+type MockInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInterfaceMockRecorder
+}
+
+// MockInterfaceMockRecorder is the mock recorder for MockInterface
+type MockInterfaceMockRecorder struct {
+	mock *MockInterface
+}
+
+// NewMockInterface creates a new mock instance
+func NewMockInterface(ctrl *gomock.Controller) *MockInterface {
+	mock := &MockInterface{ctrl: ctrl}
+	mock.recorder = &MockInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockInterface) EXPECT() *MockInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ValidateCallback mocks base method
+func (m *MockInterface) ValidateCallback(token, signature string, timestamp int) bool {
+	ret := m.ctrl.Call(m, "ValidateCallback", token, signature, timestamp)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ValidateCallback indicates an expected call of ValidateCallback
+func (mr *MockInterfaceMockRecorder) ValidateCallback(token, signature, timestamp interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateCallback", reflect.TypeOf((*MockInterface)(nil).ValidateCallback), token, signature, timestamp)
+}
+
+// GetAccounts mocks base method
+func (m *MockInterface) GetAccounts(queryValues GetAccountsParams) ([]GetAccountsResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccounts", queryValues)
+	ret0, _ := ret[0].([]GetAccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts
+func (mr *MockInterfaceMockRecorder) GetAccounts(queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockInterface)(nil).GetAccounts), queryValues)
+}
+
+// GetAccount mocks base method
+func (m *MockInterface) GetAccount(accountID string) (GetAccountsResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccount", accountID)
+	ret0, _ := ret[0].(GetAccountsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount
+func (mr *MockInterfaceMockRecorder) GetAccount(accountID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockInterface)(nil).GetAccount), accountID)
+}
+
+// CreateAccount mocks base method
+func (m *MockInterface) CreateAccount(formValues CreateAccountParams) (CreateAccountResponse, error) {
+	ret := m.ctrl.Call(m, "CreateAccount", formValues)
+	ret0, _ := ret[0].(CreateAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount
+func (mr *MockInterfaceMockRecorder) CreateAccount(formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockInterface)(nil).CreateAccount), formValues)
+}
+
+// ModifyAccount mocks base method
+func (m *MockInterface) ModifyAccount(accountID string, formValues ModifyAccountParams) (ModifyAccountResponse, error) {
+	ret := m.ctrl.Call(m, "ModifyAccount", accountID, formValues)
+	ret0, _ := ret[0].(ModifyAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModifyAccount indicates an expected call of ModifyAccount
+func (mr *MockInterfaceMockRecorder) ModifyAccount(accountID, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyAccount", reflect.TypeOf((*MockInterface)(nil).ModifyAccount), accountID, formValues)
+}
+
+// DeleteAccount mocks base method
+func (m *MockInterface) DeleteAccount(accountID string) (DeleteAccountResponse, error) {
+	ret := m.ctrl.Call(m, "DeleteAccount", accountID)
+	ret0, _ := ret[0].(DeleteAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount
+func (mr *MockInterfaceMockRecorder) DeleteAccount(accountID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockInterface)(nil).DeleteAccount), accountID)
+}
+
+// GetAccountContacts mocks base method
+func (m *MockInterface) GetAccountContacts(accountID string, queryValues GetAccountContactsParams) (GetAccountContactsResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountContacts", accountID, queryValues)
+	ret0, _ := ret[0].(GetAccountContactsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountContacts indicates an expected call of GetAccountContacts
+func (mr *MockInterfaceMockRecorder) GetAccountContacts(accountID, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountContacts", reflect.TypeOf((*MockInterface)(nil).GetAccountContacts), accountID, queryValues)
+}
+
+// GetAccountContact mocks base method
+func (m *MockInterface) GetAccountContact(accountID, email string) (GetAccountContactResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountContact", accountID, email)
+	ret0, _ := ret[0].(GetAccountContactResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountContact indicates an expected call of GetAccountContact
+func (mr *MockInterfaceMockRecorder) GetAccountContact(accountID, email interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountContact", reflect.TypeOf((*MockInterface)(nil).GetAccountContact), accountID, email)
+}
+
+// GetAccountFiles mocks base method
+func (m *MockInterface) GetAccountFiles(accountID string, queryValues GetAccountFilesParams) ([]GetAccountFilesResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountFiles", accountID, queryValues)
+	ret0, _ := ret[0].([]GetAccountFilesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountFiles indicates an expected call of GetAccountFiles
+func (mr *MockInterfaceMockRecorder) GetAccountFiles(accountID, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountFiles", reflect.TypeOf((*MockInterface)(nil).GetAccountFiles), accountID, queryValues)
+}
+
+// GetAccountFile mocks base method
+func (m *MockInterface) GetAccountFile(accountID, fileID string) (GetAccountFilesResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountFile", accountID, fileID)
+	ret0, _ := ret[0].(GetAccountFilesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountFile indicates an expected call of GetAccountFile
+func (mr *MockInterfaceMockRecorder) GetAccountFile(accountID, fileID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountFile", reflect.TypeOf((*MockInterface)(nil).GetAccountFile), accountID, fileID)
+}
+
+// GetAccountMessages mocks base method
+func (m *MockInterface) GetAccountMessages(accountID string, queryValues GetAccountMessagesParams) ([]GetAccountMessagesResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountMessages", accountID, queryValues)
+	ret0, _ := ret[0].([]GetAccountMessagesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountMessages indicates an expected call of GetAccountMessages
+func (mr *MockInterfaceMockRecorder) GetAccountMessages(accountID, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountMessages", reflect.TypeOf((*MockInterface)(nil).GetAccountMessages), accountID, queryValues)
+}
+
+// GetAccountMessage mocks base method
+func (m *MockInterface) GetAccountMessage(accountID, messageID string, queryValues GetAccountMessageParams) (GetAccountMessagesResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountMessage", accountID, messageID, queryValues)
+	ret0, _ := ret[0].(GetAccountMessagesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountMessage indicates an expected call of GetAccountMessage
+func (mr *MockInterfaceMockRecorder) GetAccountMessage(accountID, messageID, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountMessage", reflect.TypeOf((*MockInterface)(nil).GetAccountMessage), accountID, messageID, queryValues)
+}
+
+// GetAccountSources mocks base method
+func (m *MockInterface) GetAccountSources(accountID string, queryValues GetAccountSourcesParams) ([]GetAccountSourcesResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountSources", accountID, queryValues)
+	ret0, _ := ret[0].([]GetAccountSourcesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountSources indicates an expected call of GetAccountSources
+func (mr *MockInterfaceMockRecorder) GetAccountSources(accountID, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountSources", reflect.TypeOf((*MockInterface)(nil).GetAccountSources), accountID, queryValues)
+}
+
+// GetAccountSource mocks base method
+func (m *MockInterface) GetAccountSource(accountID, label string) (GetAccountSourcesResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountSource", accountID, label)
+	ret0, _ := ret[0].(GetAccountSourcesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountSource indicates an expected call of GetAccountSource
+func (mr *MockInterfaceMockRecorder) GetAccountSource(accountID, label interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountSource", reflect.TypeOf((*MockInterface)(nil).GetAccountSource), accountID, label)
+}
+
+// CreateAccountSource mocks base method
+func (m *MockInterface) CreateAccountSource(accountID string, formValues CreateAccountSourceParams) (CreateAccountSourceResponse, error) {
+	ret := m.ctrl.Call(m, "CreateAccountSource", accountID, formValues)
+	ret0, _ := ret[0].(CreateAccountSourceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountSource indicates an expected call of CreateAccountSource
+func (mr *MockInterfaceMockRecorder) CreateAccountSource(accountID, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountSource", reflect.TypeOf((*MockInterface)(nil).CreateAccountSource), accountID, formValues)
+}
+
+// ModifyAccountSource mocks base method
+func (m *MockInterface) ModifyAccountSource(accountID, label string, formValues ModifyAccountSourceParams) (ModifyAccountSourceResponse, error) {
+	ret := m.ctrl.Call(m, "ModifyAccountSource", accountID, label, formValues)
+	ret0, _ := ret[0].(ModifyAccountSourceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModifyAccountSource indicates an expected call of ModifyAccountSource
+func (mr *MockInterfaceMockRecorder) ModifyAccountSource(accountID, label, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyAccountSource", reflect.TypeOf((*MockInterface)(nil).ModifyAccountSource), accountID, label, formValues)
+}
+
+// DeleteAccountSource mocks base method
+func (m *MockInterface) DeleteAccountSource(accountID, label string) (DeleteAccountSourceResponse, error) {
+	ret := m.ctrl.Call(m, "DeleteAccountSource", accountID, label)
+	ret0, _ := ret[0].(DeleteAccountSourceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccountSource indicates an expected call of DeleteAccountSource
+func (mr *MockInterfaceMockRecorder) DeleteAccountSource(accountID, label interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountSource", reflect.TypeOf((*MockInterface)(nil).DeleteAccountSource), accountID, label)
+}
+
+// GetAccountThreads mocks base method
+func (m *MockInterface) GetAccountThreads(accountID string, queryValues GetAccountThreadsParams) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetAccountThreads", accountID, queryValues)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountThreads indicates an expected call of GetAccountThreads
+func (mr *MockInterfaceMockRecorder) GetAccountThreads(accountID, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountThreads", reflect.TypeOf((*MockInterface)(nil).GetAccountThreads), accountID, queryValues)
+}
+
+// GetAccountThread mocks base method
+func (m *MockInterface) GetAccountThread(accountID, threadID string, queryValues GetAccountThreadParams) (GetAccountThreadResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountThread", accountID, threadID, queryValues)
+	ret0, _ := ret[0].(GetAccountThreadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountThread indicates an expected call of GetAccountThread
+func (mr *MockInterfaceMockRecorder) GetAccountThread(accountID, threadID, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountThread", reflect.TypeOf((*MockInterface)(nil).GetAccountThread), accountID, threadID, queryValues)
+}
+
+// GetAccountSync mocks base method
+func (m *MockInterface) GetAccountSync(accountID string) (GetAccountSyncResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountSync", accountID)
+	ret0, _ := ret[0].(GetAccountSyncResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountSync indicates an expected call of GetAccountSync
+func (mr *MockInterfaceMockRecorder) GetAccountSync(accountID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountSync", reflect.TypeOf((*MockInterface)(nil).GetAccountSync), accountID)
+}
+
+// SyncAccount mocks base method
+func (m *MockInterface) SyncAccount(accountID string) (SyncAccountResponse, error) {
+	ret := m.ctrl.Call(m, "SyncAccount", accountID)
+	ret0, _ := ret[0].(SyncAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncAccount indicates an expected call of SyncAccount
+func (mr *MockInterfaceMockRecorder) SyncAccount(accountID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncAccount", reflect.TypeOf((*MockInterface)(nil).SyncAccount), accountID)
+}
+
+// GetAccountSourceSync mocks base method
+func (m *MockInterface) GetAccountSourceSync(accountID, label string) (GetAccountSyncResponse, error) {
+	ret := m.ctrl.Call(m, "GetAccountSourceSync", accountID, label)
+	ret0, _ := ret[0].(GetAccountSyncResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountSourceSync indicates an expected call of GetAccountSourceSync
+func (mr *MockInterfaceMockRecorder) GetAccountSourceSync(accountID, label interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountSourceSync", reflect.TypeOf((*MockInterface)(nil).GetAccountSourceSync), accountID, label)
+}
+
+// SyncAccountSource mocks base method
+func (m *MockInterface) SyncAccountSource(accountID, label string) (SyncAccountResponse, error) {
+	ret := m.ctrl.Call(m, "SyncAccountSource", accountID, label)
+	ret0, _ := ret[0].(SyncAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncAccountSource indicates an expected call of SyncAccountSource
+func (mr *MockInterfaceMockRecorder) SyncAccountSource(accountID, label interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncAccountSource", reflect.TypeOf((*MockInterface)(nil).SyncAccountSource), accountID, label)
+}
@@ -0,0 +1,73 @@
+package cio
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// NewTestCioWithTestServer returns a new Cio, *httptest.Server, and *http.ServeMux objects
+func NewTestCioWithTestServer(t *testing.T) (Cio, *httptest.Server, *http.ServeMux) {
+	mux := http.NewServeMux()
+	cio, server := NewTestCioServer(mux)
+	return cio, server, mux
+}
+
+// Must panics if the error is not nil
+func Must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TestNewCio tests the construction of Cio
+func TestNewCio(t *testing.T) {
+	t.Parallel()
+
+	cio := NewCio("key", "secret")
+	if cio.Client.Host != ciolite.DefaultHost || cio.Client.HTTPClient == nil {
+		t.Errorf("Unexpected client: %+v", cio.Client)
+	}
+
+	var _ Interface = cio
+}
+
+// TestSimulatedHooksAndErrors tests that requests go through the CIO Lite hooks, and return its errors
+func TestSimulatedHooksAndErrors(t *testing.T) {
+	t.Parallel()
+
+	cio, testServer, mux := NewTestCioWithTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/2.0/accounts/a1/sources/0", func(w http.ResponseWriter, r *http.Request) {
+		if len(r.Header.Get("Authorization")) == 0 {
+			t.Error("Expected a signed request")
+		}
+		w.WriteHeader(404)
+		_, err := io.WriteString(w, `{"type": "error", "value": "no such source"}`)
+		Must(err)
+	})
+
+	var hooked []string
+	cio.Client.PreRequestHook = func(userID string, label string, method string, url string, redactedBodyValues url.Values) {
+		hooked = append(hooked, userID, label, method)
+	}
+
+	_, err := cio.GetAccountSource("a1", "0")
+	if ciolite.ErrorStatusCode(err) != 404 || ciolite.ErrorPayload(err) != `{"type": "error", "value": "no such source"}` {
+		t.Error("Expected a 404 RequestError; Got: ", err)
+	}
+	if len(hooked) != 3 || hooked[0] != "a1" || hooked[1] != "0" || hooked[2] != "GET" {
+		t.Error("Unexpected hook arguments: ", hooked)
+	}
+
+	// Params are validated before anything is sent
+	_, err = cio.CreateAccount(CreateAccountParams{Email: "not an email"})
+	if _, ok := err.(ciolite.ValidationError); !ok {
+		t.Error("Expected ValidationError; Got: ", err)
+	}
+}
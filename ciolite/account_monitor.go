@@ -0,0 +1,305 @@
+package ciolite
+
+// Monitor that scans email account statuses, reports transitions, and forces status re-checks of failing accounts
+
+import (
+	"sync"
+	"time"
+)
+
+// AccountHealth is what the AccountMonitor remembers about an email account
+type AccountHealth struct {
+	UserID   string `json:"user_id"`
+	Label    string `json:"label"`
+	Username string `json:"username,omitempty"`
+
+	// Status is the last status seen, and Since is when the account first had this status
+	Status AccountStatus `json:"status"`
+	Since  time.Time     `json:"since"`
+
+	// LastScanned is when the account was last seen by a scan
+	LastScanned time.Time `json:"last_scanned"`
+
+	// Failures is how many scans in a row have seen a status other than OK
+	Failures int `json:"failures,omitempty"`
+
+	// Checks is how many status re-checks have been forced since the account stopped being OK,
+	// LastCheck is when the last one was forced, and NextCheck is when the next one is due
+	Checks    int       `json:"checks,omitempty"`
+	LastCheck time.Time `json:"last_check,omitempty"`
+	NextCheck time.Time `json:"next_check,omitempty"`
+}
+
+// AccountHealthStore persists AccountHealth between scans (and restarts).
+// Implementations must be safe for concurrent use.
+type AccountHealthStore interface {
+	// Load returns the health of the email account, or nil if it has not been seen before
+	Load(userID string, label string) (*AccountHealth, error)
+
+	// Save stores the health of the email account
+	Save(health AccountHealth) error
+}
+
+// MemoryAccountHealthStore is an AccountHealthStore kept in memory
+type MemoryAccountHealthStore struct {
+	mu       sync.Mutex
+	accounts map[string]AccountHealth
+}
+
+// NewMemoryAccountHealthStore returns an empty MemoryAccountHealthStore
+func NewMemoryAccountHealthStore() *MemoryAccountHealthStore {
+	return &MemoryAccountHealthStore{accounts: map[string]AccountHealth{}}
+}
+
+// Load returns the health of the email account, or nil if it has not been seen before
+func (store *MemoryAccountHealthStore) Load(userID string, label string) (*AccountHealth, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	health, ok := store.accounts[userID+"/"+label]
+	if !ok {
+		return nil, nil
+	}
+	return &health, nil
+}
+
+// Save stores the health of the email account
+func (store *MemoryAccountHealthStore) Save(health AccountHealth) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.accounts[health.UserID+"/"+health.Label] = health
+	return nil
+}
+
+// AccountStatusTransition is an email account's status changing between scans
+type AccountStatusTransition struct {
+	UserID   string
+	Label    string
+	Username string
+
+	From AccountStatus
+	To   AccountStatus
+
+	// Duration is how long the account had the From status (as far as the monitor saw)
+	Duration time.Duration
+
+	At time.Time
+}
+
+// AccountStatusCheck is a status re-check forced by the monitor
+type AccountStatusCheck struct {
+	Health   AccountHealth
+	Response ModifyEmailAccountResponse
+	Err      error
+}
+
+// AccountMonitorOptions configure an AccountMonitor.
+// The zero value keeps state in memory, and re-checks failing accounts after 15 minutes, backing off to once a day.
+type AccountMonitorOptions struct {
+	// Store keeps the health of every account, defaults to a new MemoryAccountHealthStore
+	Store AccountHealthStore
+
+	// OnTransition is called for every status transition.
+	// Accounts seen for the first time do not transition.
+	OnTransition func(transition AccountStatusTransition)
+
+	// OnCheck is called after every forced status re-check
+	OnCheck func(check AccountStatusCheck)
+
+	// OnScan is called after every scan made by Run
+	OnScan func(report AccountScanReport, err error)
+
+	// CheckInterval is how long after an account stops being OK its status is re-checked;
+	// the interval then doubles after every re-check (while the account keeps failing), up to MaxCheckInterval
+	CheckInterval    time.Duration
+	MaxCheckInterval time.Duration
+
+	// NoCheckStatuses are never re-checked, defaults to DISABLED (which is set on purpose, rather than by a failure)
+	NoCheckStatuses []AccountStatus
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// AccountScanReport is the outcome of a single scan
+type AccountScanReport struct {
+	Accounts int
+
+	// Counts holds the number of accounts with each status
+	Counts map[AccountStatus]int
+
+	Transitions []AccountStatusTransition
+
+	Checks      int
+	CheckErrors int
+
+	// StoreErrors holds the errors received while loading or saving account health.
+	// An account whose health cannot be loaded is skipped.
+	StoreErrors []error
+}
+
+// AccountMonitor periodically scans the status of every email account
+type AccountMonitor struct {
+	client  Interface
+	options AccountMonitorOptions
+}
+
+// NewAccountMonitor returns an AccountMonitor
+func NewAccountMonitor(client Interface, options AccountMonitorOptions) *AccountMonitor {
+	if options.Store == nil {
+		options.Store = NewMemoryAccountHealthStore()
+	}
+	if options.CheckInterval <= 0 {
+		options.CheckInterval = 15 * time.Minute
+	}
+	if options.MaxCheckInterval < options.CheckInterval {
+		options.MaxCheckInterval = 24 * time.Hour
+		if options.MaxCheckInterval < options.CheckInterval {
+			options.MaxCheckInterval = options.CheckInterval
+		}
+	}
+	if options.NoCheckStatuses == nil {
+		options.NoCheckStatuses = []AccountStatus{AccountStatusDisabled}
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	return &AccountMonitor{client: client, options: options}
+}
+
+// Run scans immediately, and then every interval, until stop is closed
+func (monitor *AccountMonitor) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := monitor.Scan()
+		if monitor.options.OnScan != nil {
+			monitor.options.OnScan(report, err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan lists every user and email account, records their status, reports any transitions,
+// and forces a status re-check of failing accounts that are due one.
+// The returned error is only set if the users could not be listed.
+func (monitor *AccountMonitor) Scan() (AccountScanReport, error) {
+
+	report := AccountScanReport{Counts: map[AccountStatus]int{}}
+
+	users, err := listAllUsers(monitor.client)
+	if err != nil {
+		return report, err
+	}
+
+	for _, user := range users {
+		for _, account := range user.EmailAccounts {
+			monitor.scanAccount(user.ID, account, &report)
+		}
+	}
+
+	return report, nil
+}
+
+// scanAccount records the status of a single email account
+func (monitor *AccountMonitor) scanAccount(userID string, account GetUsersEmailAccountsResponse, report *AccountScanReport) {
+
+	now := monitor.options.Now()
+	report.Accounts++
+	report.Counts[account.Status]++
+
+	stored, err := monitor.options.Store.Load(userID, account.Label)
+	if err != nil {
+		report.StoreErrors = append(report.StoreErrors, err)
+		return
+	}
+
+	health := AccountHealth{UserID: userID, Label: account.Label, Status: account.Status, Since: now}
+	if stored != nil {
+		health = *stored
+	}
+	health.Username = account.Username
+	health.LastScanned = now
+
+	if stored != nil && stored.Status != account.Status {
+		transition := AccountStatusTransition{
+			UserID:   userID,
+			Label:    account.Label,
+			Username: account.Username,
+			From:     stored.Status,
+			To:       account.Status,
+			Duration: now.Sub(stored.Since),
+			At:       now,
+		}
+		report.Transitions = append(report.Transitions, transition)
+		if monitor.options.OnTransition != nil {
+			monitor.options.OnTransition(transition)
+		}
+
+		health.Status = account.Status
+		health.Since = now
+	}
+
+	if account.Status.IsOK() {
+		health.Failures = 0
+		health.Checks = 0
+		health.NextCheck = time.Time{}
+	} else {
+		health.Failures++
+		if health.NextCheck.IsZero() {
+			health.NextCheck = now.Add(monitor.options.CheckInterval)
+		} else if !now.Before(health.NextCheck) && monitor.checkable(account.Status) {
+			monitor.check(&health, now, report)
+		}
+	}
+
+	if err = monitor.options.Store.Save(health); err != nil {
+		report.StoreErrors = append(report.StoreErrors, err)
+	}
+}
+
+// check forces a status re-check, and schedules the next one
+func (monitor *AccountMonitor) check(health *AccountHealth, now time.Time, report *AccountScanReport) {
+
+	response, err := monitor.client.ModifyUserEmailAccount(health.UserID, health.Label, ModifyUserEmailAccountParams{ForceStatusCheck: true})
+
+	report.Checks++
+	if err != nil {
+		report.CheckErrors++
+	}
+
+	health.Checks++
+	health.LastCheck = now
+	health.NextCheck = now.Add(monitor.checkInterval(health.Checks))
+
+	if monitor.options.OnCheck != nil {
+		monitor.options.OnCheck(AccountStatusCheck{Health: *health, Response: response, Err: err})
+	}
+}
+
+// checkInterval returns the interval after the given number of checks, doubling each time up to MaxCheckInterval
+func (monitor *AccountMonitor) checkInterval(checks int) time.Duration {
+	interval := monitor.options.CheckInterval
+	for i := 0; i < checks && interval < monitor.options.MaxCheckInterval; i++ {
+		interval *= 2
+	}
+	if interval > monitor.options.MaxCheckInterval {
+		interval = monitor.options.MaxCheckInterval
+	}
+	return interval
+}
+
+// checkable returns true if accounts with the status should be re-checked
+func (monitor *AccountMonitor) checkable(status AccountStatus) bool {
+	for _, s := range monitor.options.NoCheckStatuses {
+		if s == status {
+			return false
+		}
+	}
+	return true
+}
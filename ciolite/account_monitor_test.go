@@ -0,0 +1,153 @@
+package ciolite
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSimulatedAccountMonitor tests AccountMonitor.Scan with a simulated server
+func TestSimulatedAccountMonitor(t *testing.T) {
+	t.Parallel()
+
+	cioLite, _, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var (
+		mu       sync.Mutex
+		statuses = map[string]string{"a": "OK", "b": "OK", "c": "DISABLED"}
+		checked  []string
+	)
+
+	mux.HandleFunc("/lite/users", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if offset := r.FormValue("offset"); offset != "" && offset != "0" {
+			_, err := io.WriteString(w, `[]`)
+			Must(err)
+			return
+		}
+		_, err := fmt.Fprintf(w, `[{"id": "u1", "email_accounts": [
+			{"label": "a", "status": %q, "username": "a@example.com"},
+			{"label": "b", "status": %q, "username": "b@example.com"}
+		]}, {"id": "u2", "email_accounts": [{"label": "c", "status": %q}]}]`, statuses["a"], statuses["b"], statuses["c"])
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.FormValue("force_status_check") != "1" {
+			t.Errorf("Unexpected request: %s %s %s", r.Method, r.URL.Path, r.Form.Encode())
+		}
+		mu.Lock()
+		checked = append(checked, r.URL.Path)
+		mu.Unlock()
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+
+	now := time.Unix(1500000000, 0)
+	var transitions []AccountStatusTransition
+	monitor := NewAccountMonitor(cioLite, AccountMonitorOptions{
+		CheckInterval:    time.Minute,
+		MaxCheckInterval: 3 * time.Minute,
+		OnTransition:     func(transition AccountStatusTransition) { transitions = append(transitions, transition) },
+		Now:              func() time.Time { return now },
+	})
+
+	scan := func(minutes int) AccountScanReport {
+		now = now.Add(time.Duration(minutes) * time.Minute)
+		report, err := monitor.Scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	checks := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		c := checked
+		checked = nil
+		return c
+	}
+
+	// First scan records every account, without transitions or checks
+	report := scan(0)
+	if report.Accounts != 3 || !reflect.DeepEqual(report.Counts, map[AccountStatus]int{AccountStatusOK: 2, AccountStatusDisabled: 1}) || len(report.Transitions) != 0 {
+		t.Errorf("Unexpected first report: %+v", report)
+	}
+
+	// Account a fails: one transition, and the check is only scheduled
+	mu.Lock()
+	statuses["a"] = "INVALID_CREDENTIALS"
+	mu.Unlock()
+	report = scan(5)
+	expected := []AccountStatusTransition{{
+		UserID: "u1", Label: "a", Username: "a@example.com",
+		From: "OK", To: "INVALID_CREDENTIALS", Duration: 5 * time.Minute, At: now,
+	}}
+	if !reflect.DeepEqual(report.Transitions, expected) || !reflect.DeepEqual(transitions, expected) {
+		t.Errorf("Unexpected transitions: %+v", report.Transitions)
+	}
+	if c := checks(); len(c) != 0 {
+		t.Errorf("Unexpected checks: %v", c)
+	}
+
+	// The check backs off: due after 1 minute, then 2, then 3 (the max), then 3
+	var checkedAt []int
+	for minute := 1; minute <= 10; minute++ {
+		report = scan(1)
+		if c := checks(); len(c) > 0 {
+			if !reflect.DeepEqual(c, []string{"/lite/users/u1/email_accounts/a"}) || report.Checks != 1 {
+				t.Errorf("Unexpected checks at minute %d: %v %+v", minute, c, report)
+			}
+			checkedAt = append(checkedAt, minute)
+		}
+	}
+	if !reflect.DeepEqual(checkedAt, []int{1, 3, 6, 9}) {
+		t.Errorf("Unexpected check schedule: %v", checkedAt)
+	}
+
+	health, err := monitor.options.Store.Load("u1", "a")
+	if err != nil || health == nil || health.Checks != 4 || health.Failures != 11 || health.Status != "INVALID_CREDENTIALS" {
+		t.Errorf("Unexpected health: %+v %v", health, err)
+	}
+
+	// Recovery resets the backoff
+	mu.Lock()
+	statuses["a"] = "OK"
+	mu.Unlock()
+	report = scan(1)
+	if len(report.Transitions) != 1 || report.Transitions[0].To != "OK" || report.Transitions[0].Duration != 11*time.Minute {
+		t.Errorf("Unexpected recovery: %+v", report.Transitions)
+	}
+	health, _ = monitor.options.Store.Load("u1", "a")
+	if health.Checks != 0 || health.Failures != 0 || !health.NextCheck.IsZero() {
+		t.Errorf("Unexpected health after recovery: %+v", health)
+	}
+
+	// The disabled account is never checked
+	scan(60)
+	scan(60)
+	if c := checks(); len(c) != 0 {
+		t.Errorf("Unexpected checks of disabled account: %v", c)
+	}
+}
+
+// TestAccountMonitorCheckInterval tests the check backoff
+func TestAccountMonitorCheckInterval(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewAccountMonitor(nil, AccountMonitorOptions{})
+	expected := []time.Duration{15 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour}
+	for i, interval := range expected {
+		if actual := monitor.checkInterval(i); actual != interval {
+			t.Errorf("Expected interval %s after %d checks, got %s", interval, i, actual)
+		}
+	}
+	if actual := monitor.checkInterval(20); actual != 24*time.Hour {
+		t.Errorf("Expected interval capped at 24h, got %s", actual)
+	}
+}
@@ -11,7 +11,7 @@ type GetStatusCallbackURLResponse struct {
 // CreateStatusCallbackURLParams form values data struct.
 // Requires: StatusCallbackURL
 type CreateStatusCallbackURLParams struct {
-	StatusCallbackURL string `json:"status_callback_url,omitempty"`
+	StatusCallbackURL string `json:"status_callback_url,omitempty" valid:"required,url"`
 }
 
 // CreateDeleteStatusCallbackURLResponse data struct
@@ -23,7 +23,7 @@ type CreateDeleteStatusCallbackURLResponse struct {
 func (cioLite CioLite) GetStatusCallbackURL() (GetStatusCallbackURLResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   "/app/status_callback_url",
 	}
@@ -42,7 +42,7 @@ func (cioLite CioLite) GetStatusCallbackURL() (GetStatusCallbackURLResponse, err
 func (cioLite CioLite) CreateStatusCallbackURL(formValues CreateStatusCallbackURLParams) (CreateDeleteStatusCallbackURLResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       "/app/status_callback_url",
 		FormValues: formValues,
@@ -61,7 +61,7 @@ func (cioLite CioLite) CreateStatusCallbackURL(formValues CreateStatusCallbackUR
 func (cioLite CioLite) DeleteStatusCallbackURL() (CreateDeleteStatusCallbackURLResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   "/app/status_callback_url",
 	}
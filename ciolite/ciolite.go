@@ -21,6 +21,12 @@ const (
 
 	// DefaultRequestTimeout is the default timeout duration used on HTTP requests
 	DefaultRequestTimeout = 120 * time.Second
+
+	// DefaultUserAgent is the default User-Agent header sent with requests
+	DefaultUserAgent = "Golang CIO Library"
+
+	// MaxRetryBackoff is the longest WithRetry waits before a retry
+	MaxRetryBackoff = time.Minute
 )
 
 // CioLite struct contains the api key and secret, along with an optional logger,
@@ -44,7 +50,8 @@ type CioLite struct {
 	PreRequestHook func(string, string, string, string, url.Values)
 
 	// PostRequestShouldRetryHook is a function (mostly for logging) that will be
-	// executed after each request is made, and will be called at least once.
+	// executed after each request is made, and will be called at least once
+	// (except for streams, which are not retried nor passed to it once elements have been passed on).
 	// 	Its arguments are:
 	// 	request Attempt # (starts at 1),
 	// 	User ID (if present),
@@ -64,6 +71,24 @@ type CioLite struct {
 	// ResponseBodyCloseErrorHook is a function (purely for logging) that will
 	// execute if there is an error closing the response body.
 	ResponseBodyCloseErrorHook func(error)
+
+	// Limiter, if set, is waited on before every attempt of every request
+	// (it can be shared by several CioLite's, ex: see Registry)
+	Limiter Limiter
+
+	// UserAgent is sent as the User-Agent header, defaults to DefaultUserAgent (see WithUserAgent)
+	UserAgent string
+
+	// Compression, if true, asks for gzip or deflate compressed responses (which are always decompressed)
+	Compression bool
+
+	// MaxResponseBytes, if set, limits the size of (decompressed) responses:
+	// larger responses fail with a RequestError caused by ResponseTooLargeError (see IsResponseTooLarge)
+	MaxResponseBytes int64
+
+	// CircuitBreaker, if set, fails requests fast (with a RequestError caused by CircuitOpenError, see IsCircuitOpen)
+	// while their endpoint and account keep failing (it can be shared by several CioLite's)
+	CircuitBreaker *CircuitBreaker
 }
 
 // NewCioLite returns a CIO Lite struct (without a logger) for accessing the CIO Lite API.
@@ -113,6 +138,9 @@ type Interface interface {
 	GetUserEmailAccountsFolderMessageAttachment(userID string, label string, folder string, messageID string, attachmentID string, queryValues GetUserEmailAccountsFolderMessageAttachmentParam) (GetUserEmailAccountsFolderMessageAttachmentsResponse, error)
 	GetUserEmailAccountsFolderMessageBody(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageBodyParams) ([]GetUserEmailAccountsFolderMessageBodyResponse, error)
 	GetUserEmailAccountsFolderMessageFlags(userID string, label string, folder string, messageID string, queryValues EmailAccountFolderDelimiterParam) (GetUserEmailAccountsFolderMessageFlagsResponse, error)
+	ModifyUserEmailAccountsFolderMessageFlags(userID string, label string, folder string, messageID string, formValues ModifyUserEmailAccountsFolderMessageFlagsParams) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error)
+	SetUserEmailAccountsFolderMessageFlag(userID string, label string, folder string, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error)
+	ClearUserEmailAccountsFolderMessageFlag(userID string, label string, folder string, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error)
 
 	GetUserEmailAccountsFolderMessageHeaders(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageHeadersParams) (GetUserEmailAccountsFolderMessageHeadersResponse, error)
 	GetUserEmailAccountsFolderMessageRaw(userID string, label string, folder string, messageID string, queryValues EmailAccountFolderDelimiterParam) (GetUserEmailAccountsFolderMessageRawResponse, error)
@@ -120,6 +148,7 @@ type Interface interface {
 	MarkUserEmailAccountsFolderMessageUnRead(userID string, label string, folder string, messageID string, formValues EmailAccountFolderDelimiterParam) (UserEmailAccountsFolderMessageReadResponse, error)
 
 	GetUserEmailAccountsFolderMessages(userID string, label string, folder string, queryValues GetUserEmailAccountsFolderMessageParams) ([]GetUsersEmailAccountFolderMessagesResponse, error)
+	StreamUserEmailAccountsFolderMessages(userID string, label string, folder string, queryValues GetUserEmailAccountsFolderMessageParams, fn func(GetUsersEmailAccountFolderMessagesResponse) error) error
 	GetUserEmailAccountFolderMessage(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageParams) (GetUsersEmailAccountFolderMessagesResponse, error)
 	MoveUserEmailAccountFolderMessage(userID string, label string, folder string, messageID string, queryValues MoveUserEmailAccountFolderMessageParams) (MoveUserEmailAccountFolderMessageResponse, error)
 
@@ -127,8 +156,14 @@ type Interface interface {
 	GetUserEmailAccountFolder(userID string, label string, folder string, queryValues EmailAccountFolderDelimiterParam) (GetUsersEmailAccountFoldersResponse, error)
 	CreateUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (CreateEmailAccountFolderResponse, error)
 	SafeCreateUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (bool, error)
+	RenameUserEmailAccountFolder(userID string, label string, folder string, queryValues RenameUserEmailAccountFolderParams) (RenameEmailAccountFolderResponse, error)
+	DeleteUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (DeleteEmailAccountFolderResponse, error)
+	SafeCreateAllUserEmailAccountFolders(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error)
+	SafeRenameUserEmailAccountFolder(userID string, label string, folder string, newFolder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error)
+	SafeDeleteAllUserEmailAccountFolders(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error)
 
 	GetUserEmailAccountsMessages(userID string, label string, queryValues GetUserEmailAccountsMessageParams) ([]GetUsersEmailAccountMessagesResponse, error)
+	StreamUserEmailAccountsMessages(userID string, label string, queryValues GetUserEmailAccountsMessageParams, fn func(GetUsersEmailAccountMessagesResponse) error) error
 	GetUserEmailAccountMessage(userID string, label string, messageID string, queryValues GetUserEmailAccountsMessageParams) (GetUsersEmailAccountMessagesResponse, error)
 
 	GetUserEmailAccounts(userID string, queryValues GetUserEmailAccountsParams) ([]GetUsersEmailAccountsResponse, error)
@@ -159,12 +194,17 @@ type Interface interface {
 // NewTestCioLiteServer is a convenience function that returns a CioLite object
 // and a *httptest.Server (which must be closed when done being used).
 // The CioLite instance will hit the test server for all requests.
-func NewTestCioLiteServer(handler http.Handler) (CioLite, *httptest.Server) {
+// Options are applied after the Host and HTTPClient are set, and it panics if one of them is invalid.
+func NewTestCioLiteServer(handler http.Handler, opts ...Option) (CioLite, *httptest.Server) {
 	testServer := httptest.NewServer(handler)
 	testCioLite := CioLite{
 		Host:       testServer.URL,
 		HTTPClient: &http.Client{Timeout: 5 * time.Second},
 	}
+	if err := testCioLite.apply(opts); err != nil {
+		testServer.Close()
+		panic(err)
+	}
 	return testCioLite, testServer
 }
 
@@ -0,0 +1,1667 @@
+package ciolite
+
+// In-memory fake of the CIO Lite API, implementing Interface without any HTTP
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FakeClient implements Interface with an in-memory model of users, email accounts, folders, messages,
+// webhooks, connect tokens, and oauth providers, for tests that would rather not script every call of a MockInterface.
+// Calls have the same semantics as CIO: ex: moving a message removes it from its folder, deleting a user deletes its
+// email accounts, webhooks, and connect tokens, and params are validated the same way.
+// Missing resources are returned as a RequestError with a 404 status code (see FakeRequestError).
+// A FakeClient is safe for concurrent use.
+type FakeClient struct {
+	// Secret is used by ValidateCallback, to validate callbacks signed by tests
+	Secret string
+
+	// Delimiter is the folder delimiter of new email accounts, defaults to "/"
+	Delimiter string
+
+	// ErrorHook, if set, is called before every call with the method's name (ex: "GetUsers") and its arguments.
+	// A non-nil error is returned by the call, which then changes nothing.
+	ErrorHook func(method string, args ...interface{}) error
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+
+	mu       sync.Mutex
+	calls    []string
+	failNext map[string][]error
+	fail     map[string]error
+	nextID   int
+
+	users             []*fakeUser
+	connectTokens     []*fakeConnectToken
+	webhooks          []*GetUsersWebhooksResponse
+	oauthProviders    []GetOAuthProvidersResponse
+	statusCallbackURL string
+	discovery         map[string]GetDiscoveryResponse
+}
+
+// FakeMessage is a message held in a folder of a FakeClient
+type FakeMessage struct {
+	GetUsersEmailAccountFolderMessagesResponse
+
+	// Headers are returned by GetUserEmailAccountsFolderMessageHeaders
+	Headers map[string][]string
+
+	// Raw is the RFC-822 message returned by GetUserEmailAccountsFolderMessageRaw
+	Raw string
+}
+
+// fakeUser is a user, along with everything that belongs to it
+type fakeUser struct {
+	user      GetUsersResponse
+	accounts  []*fakeEmailAccount
+	webhooks  []*GetUsersWebhooksResponse
+	nextLabel int
+}
+
+// fakeEmailAccount is an email account, along with its folders
+type fakeEmailAccount struct {
+	account   GetUsersEmailAccountsResponse
+	delimiter string
+	folders   []*fakeFolder
+}
+
+// fakeFolder is a folder, along with its messages
+type fakeFolder struct {
+	name     string
+	messages []*FakeMessage
+}
+
+// fakeConnectToken is a connect token, along with the user (and email account) it was created for, if any
+type fakeConnectToken struct {
+	token  GetConnectTokenResponse
+	userID string
+	label  string
+}
+
+// NewFakeClient returns an empty FakeClient
+func NewFakeClient() *FakeClient {
+	return &FakeClient{}
+}
+
+// FakeRequestError returns a RequestError like the ones CIO responds with, such as for injecting errors into a FakeClient
+func FakeRequestError(statusCode int, message string) error {
+	payload, _ := json.Marshal(map[string]string{"type": "error", "value": message})
+	return RequestError{errors.New("CIO: Status Code >= 400"), ErrorMetaData{StatusCode: statusCode, Payload: string(payload)}}
+}
+
+// FailNext makes the next call of the method (ex: "GetUsers") return err, after any failures already queued
+func (fake *FakeClient) FailNext(method string, err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.failNext == nil {
+		fake.failNext = map[string][]error{}
+	}
+	fake.failNext[method] = append(fake.failNext[method], err)
+}
+
+// Fail makes every call of the method (ex: "GetUsers") return err, until it is called again with a nil error
+func (fake *FakeClient) Fail(method string, err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.fail == nil {
+		fake.fail = map[string]error{}
+	}
+	if err == nil {
+		delete(fake.fail, method)
+		return
+	}
+	fake.fail[method] = err
+}
+
+// Calls returns the name of every method called so far, in order
+func (fake *FakeClient) Calls() []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]string(nil), fake.calls...)
+}
+
+// AddMessage adds a message to a folder of an email account, creating the folder if it does not exist.
+// A MessageID is generated if it is empty. Returns the MessageID.
+func (fake *FakeClient) AddMessage(userID string, label string, folder string, message FakeMessage) (string, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return "", err
+	}
+	f := account.folder(folder)
+	if f == nil {
+		f = &fakeFolder{name: folder}
+		account.folders = append(account.folders, f)
+	}
+	if len(message.MessageID) == 0 {
+		message.MessageID = fmt.Sprintf("<%s@fake.context.io>", fake.newID())
+	}
+	f.messages = append(f.messages, &message)
+	return message.MessageID, nil
+}
+
+// SetEmailAccountStatus changes the status of an email account, as if CIO had (ex: lost access to the account)
+func (fake *FakeClient) SetEmailAccountStatus(userID string, label string, status AccountStatus) error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return err
+	}
+	account.account.Status = status
+	return nil
+}
+
+// SetDiscovery sets the response of GetDiscovery for the response's Email.
+// Other emails are not found.
+func (fake *FakeClient) SetDiscovery(response GetDiscoveryResponse) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.discovery == nil {
+		fake.discovery = map[string]GetDiscoveryResponse{}
+	}
+	fake.discovery[strings.ToLower(response.Email)] = response
+}
+
+// UseConnectToken simulates the user authorizing access with a connect token, using the account params
+// (Email defaults to the token's Email): a user is created for tokens that are not for a user,
+// an email account is created for tokens that are not for an email account,
+// and tokens for an email account have its status reset to OK.
+func (fake *FakeClient) UseConnectToken(token string, account CreateUserParams) error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	connectToken := fake.connectToken(token)
+	if connectToken == nil || connectToken.token.Used != 0 {
+		return FakeRequestError(404, "Connect token not found: "+token)
+	}
+	if len(account.Email) == 0 {
+		account.Email = connectToken.token.Email
+	}
+
+	var user *fakeUser
+	switch {
+	case len(connectToken.userID) == 0:
+		account.FirstName, account.LastName = connectToken.token.FirstName, connectToken.token.LastName
+		user = fake.createUser(account)
+		connectToken.token.ServerLabel = user.accounts[0].account.Label
+	case len(connectToken.label) == 0:
+		var err error
+		if user, err = fake.user(connectToken.userID); err != nil {
+			return err
+		}
+		connectToken.token.ServerLabel = fake.createEmailAccount(user, account).account.Label
+	default:
+		var (
+			emailAccount *fakeEmailAccount
+			err          error
+		)
+		if user, emailAccount, err = fake.emailAccount(connectToken.userID, connectToken.label); err != nil {
+			return err
+		}
+		emailAccount.account.Status = AccountStatusOK
+		connectToken.token.ServerLabel = emailAccount.account.Label
+	}
+
+	connectToken.token.Email = account.Email
+	connectToken.token.Used = NewUnixTime(fake.now())
+	connectToken.token.Expires = ExpiresMixed{}
+	connectToken.token.User = GetConnectTokenUserResponse{
+		ID:             user.user.ID,
+		EmailAddresses: append([]string(nil), user.user.EmailAddresses...),
+		FirstName:      user.user.FirstName,
+		LastName:       user.user.LastName,
+		Created:        user.user.Created,
+		EmailAccounts:  user.emailAccounts(),
+	}
+	return nil
+}
+
+// begin records the call, and returns any validation error or injected error
+func (fake *FakeClient) begin(method string, params interface{}, args ...interface{}) error {
+	fake.mu.Lock()
+	fake.calls = append(fake.calls, method)
+	var err error
+	if queued := fake.failNext[method]; len(queued) > 0 {
+		err, fake.failNext[method] = queued[0], queued[1:]
+	} else if failure, ok := fake.fail[method]; ok {
+		err = failure
+	}
+	hook := fake.ErrorHook
+	fake.mu.Unlock()
+
+	if validationErr := validateParams(params); validationErr != nil {
+		return validationErr
+	}
+	if err != nil {
+		return err
+	}
+	if hook != nil {
+		return hook(method, args...)
+	}
+	return nil
+}
+
+// now returns the current time
+func (fake *FakeClient) now() time.Time {
+	if fake.Now != nil {
+		return fake.Now()
+	}
+	return time.Now()
+}
+
+// newID returns a new unique id, shaped like CIO's
+func (fake *FakeClient) newID() string {
+	fake.nextID++
+	return fmt.Sprintf("%024x", fake.nextID)
+}
+
+// user returns the user, or a 404 error
+func (fake *FakeClient) user(userID string) (*fakeUser, error) {
+	for _, user := range fake.users {
+		if user.user.ID == userID {
+			return user, nil
+		}
+	}
+	return nil, FakeRequestError(404, "User not found: "+userID)
+}
+
+// emailAccount returns the user and email account, or a 404 error
+func (fake *FakeClient) emailAccount(userID string, label string) (*fakeUser, *fakeEmailAccount, error) {
+	user, err := fake.user(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, account := range user.accounts {
+		if account.account.Label == label {
+			return user, account, nil
+		}
+	}
+	return nil, nil, FakeRequestError(404, "Email account not found: "+label)
+}
+
+// folder returns the email account and folder (named with delimiter, if set), or a 404 error
+func (fake *FakeClient) folder(userID string, label string, folder string, delimiter string) (*fakeEmailAccount, *fakeFolder, error) {
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return nil, nil, err
+	}
+	f := account.folder(account.folderName(folder, delimiter))
+	if f == nil {
+		return nil, nil, FakeRequestError(404, "Folder not found: "+folder)
+	}
+	return account, f, nil
+}
+
+// message returns the folder and message, or a 404 error
+func (fake *FakeClient) message(userID string, label string, folder string, messageID string, delimiter string) (*fakeFolder, *FakeMessage, error) {
+	_, f, err := fake.folder(userID, label, folder, delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, message := range f.messages {
+		if message.MessageID == messageID {
+			return f, message, nil
+		}
+	}
+	return nil, nil, FakeRequestError(404, "Message not found: "+messageID)
+}
+
+// connectToken returns the connect token, or nil
+func (fake *FakeClient) connectToken(token string) *fakeConnectToken {
+	for _, connectToken := range fake.connectTokens {
+		if connectToken.token.Token == token {
+			return connectToken
+		}
+	}
+	return nil
+}
+
+// createUser creates a user, along with an email account if the params have one
+func (fake *FakeClient) createUser(formValues CreateUserParams) *fakeUser {
+	user := &fakeUser{user: GetUsersResponse{
+		ID:        fake.newID(),
+		FirstName: formValues.FirstName,
+		LastName:  formValues.LastName,
+		Created:   NewUnixTime(fake.now()),
+	}}
+	fake.users = append(fake.users, user)
+	if len(formValues.Email) > 0 {
+		fake.createEmailAccount(user, formValues)
+	}
+	return user
+}
+
+// createEmailAccount creates an email account for the user, with an INBOX
+func (fake *FakeClient) createEmailAccount(user *fakeUser, formValues CreateUserParams) *fakeEmailAccount {
+	account := &fakeEmailAccount{
+		account: GetUsersEmailAccountsResponse{
+			Status:             AccountStatusOK,
+			Type:               AccountType(formValues.Type),
+			AuthenticationType: AuthenticationTypePassword,
+			Server:             formValues.Server,
+			Label:              strconv.Itoa(user.nextLabel),
+			Username:           formValues.Username,
+			UseSSL:             formValues.UseSSL,
+			Port:               formValues.Port,
+		},
+		delimiter: fake.Delimiter,
+		folders:   []*fakeFolder{{name: "INBOX"}},
+	}
+	if len(account.account.Type) == 0 {
+		account.account.Type = AccountTypeIMAP
+	}
+	if len(formValues.ProviderRefreshToken) > 0 {
+		account.account.AuthenticationType = AuthenticationTypeOAuth2
+	}
+	if len(account.account.Username) == 0 {
+		account.account.Username = formValues.Email
+	}
+	if len(account.delimiter) == 0 {
+		account.delimiter = "/"
+	}
+	user.nextLabel++
+	user.accounts = append(user.accounts, account)
+
+	for _, email := range user.user.EmailAddresses {
+		if strings.EqualFold(email, formValues.Email) {
+			return account
+		}
+	}
+	user.user.EmailAddresses = append(user.user.EmailAddresses, formValues.Email)
+	return account
+}
+
+// response returns the user, with its email accounts
+func (user *fakeUser) response() GetUsersResponse {
+	response := user.user
+	response.EmailAddresses = append([]string(nil), user.user.EmailAddresses...)
+	response.EmailAccounts = user.emailAccounts()
+	return response
+}
+
+// emailAccounts returns the user's email accounts
+func (user *fakeUser) emailAccounts() []GetUsersEmailAccountsResponse {
+	var accounts []GetUsersEmailAccountsResponse
+	for _, account := range user.accounts {
+		accounts = append(accounts, account.account)
+	}
+	return accounts
+}
+
+// hasStatus returns true if the user has an email account matching the status params
+func (user *fakeUser) hasStatus(status string, statusOK string) bool {
+	if len(status) == 0 && len(statusOK) == 0 {
+		return true
+	}
+	for _, account := range user.accounts {
+		if account.hasStatus(status, statusOK) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStatus returns true if the email account matches the status params
+func (account *fakeEmailAccount) hasStatus(status string, statusOK string) bool {
+	if len(status) > 0 && string(account.account.Status) != status {
+		return false
+	}
+	if len(statusOK) > 0 && account.account.Status.IsOK() != (statusOK == "1") {
+		return false
+	}
+	return true
+}
+
+// folderName returns the folder's name with the account's delimiter, if it was named with another delimiter
+func (account *fakeEmailAccount) folderName(folder string, delimiter string) string {
+	if len(delimiter) == 0 || delimiter == account.delimiter {
+		return folder
+	}
+	return strings.Replace(folder, delimiter, account.delimiter, -1)
+}
+
+// folder returns the folder, or nil
+func (account *fakeEmailAccount) folder(name string) *fakeFolder {
+	for _, f := range account.folders {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// response returns the folder
+func (f *fakeFolder) response(account *fakeEmailAccount, namesOnly bool) GetUsersEmailAccountFoldersResponse {
+	if namesOnly {
+		return GetUsersEmailAccountFoldersResponse{Name: f.name}
+	}
+	response := GetUsersEmailAccountFoldersResponse{Name: f.name, NbMessages: len(f.messages), Delimiter: account.delimiter}
+	for _, message := range f.messages {
+		if !message.Flags.Read {
+			response.NbUnseenMessages++
+		}
+	}
+	return response
+}
+
+// response returns the message, with its flags and bodies only if they were asked for
+func (message *FakeMessage) response(folder string, includeFlags bool, includeBody bool, bodyType string) GetUsersEmailAccountFolderMessagesResponse {
+	response := message.GetUsersEmailAccountFolderMessagesResponse
+	response.Folders = []string{folder}
+	response.Flags = UserEmailAccountsFolderMessageFlags{}
+	if includeFlags {
+		response.Flags = message.Flags
+		response.Flags.Keywords = append([]string(nil), message.Flags.Keywords...)
+	}
+	response.Bodies = nil
+	if includeBody {
+		for _, body := range message.Bodies {
+			if len(bodyType) == 0 || body.Type == bodyType {
+				response.Bodies = append(response.Bodies, body)
+			}
+		}
+	}
+	return response
+}
+
+// page returns the part of a list of n items within limit and offset
+func page(n int, limit int, offset int) (int, int) {
+	if offset > n {
+		offset = n
+	}
+	end := n
+	if limit > 0 && offset+limit < n {
+		end = offset + limit
+	}
+	return offset, end
+}
+
+// ValidateCallback returns true if this Webhook Callback or User Account Status Callback was signed with the Secret
+func (fake *FakeClient) ValidateCallback(token string, signature string, timestamp int) bool {
+	hash := hashHmac(sha256.New, strconv.Itoa(timestamp)+token, fake.Secret)
+	return len(hash) > 0 && signature == hash
+}
+
+// GetStatusCallbackURL gets the app's status callback url
+func (fake *FakeClient) GetStatusCallbackURL() (GetStatusCallbackURLResponse, error) {
+	if err := fake.begin("GetStatusCallbackURL", nil); err != nil {
+		return GetStatusCallbackURLResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return GetStatusCallbackURLResponse{StatusCallbackURL: fake.statusCallbackURL}, nil
+}
+
+// CreateStatusCallbackURL sets the app's status callback url
+func (fake *FakeClient) CreateStatusCallbackURL(formValues CreateStatusCallbackURLParams) (CreateDeleteStatusCallbackURLResponse, error) {
+	if err := fake.begin("CreateStatusCallbackURL", formValues, formValues); err != nil {
+		return CreateDeleteStatusCallbackURLResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.statusCallbackURL = formValues.StatusCallbackURL
+	return CreateDeleteStatusCallbackURLResponse{Success: true}, nil
+}
+
+// DeleteStatusCallbackURL removes the app's status callback url
+func (fake *FakeClient) DeleteStatusCallbackURL() (CreateDeleteStatusCallbackURLResponse, error) {
+	if err := fake.begin("DeleteStatusCallbackURL", nil); err != nil {
+		return CreateDeleteStatusCallbackURLResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.statusCallbackURL) == 0 {
+		return CreateDeleteStatusCallbackURLResponse{}, FakeRequestError(404, "No status callback url")
+	}
+	fake.statusCallbackURL = ""
+	return CreateDeleteStatusCallbackURLResponse{Success: true}, nil
+}
+
+// getConnectTokens returns the connect tokens created for exactly this user and email account
+func (fake *FakeClient) getConnectTokens(method string, userID string, label string) ([]GetConnectTokenResponse, error) {
+	if err := fake.begin(method, nil, userID, label); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(userID) > 0 {
+		if _, err := fake.user(userID); err != nil {
+			return nil, err
+		}
+	}
+	if len(label) > 0 {
+		if _, _, err := fake.emailAccount(userID, label); err != nil {
+			return nil, err
+		}
+	}
+	var tokens []GetConnectTokenResponse
+	for _, connectToken := range fake.connectTokens {
+		if connectToken.userID == userID && connectToken.label == label {
+			tokens = append(tokens, connectToken.token)
+		}
+	}
+	return tokens, nil
+}
+
+// getConnectToken returns a connect token created for exactly this user and email account
+func (fake *FakeClient) getConnectToken(method string, userID string, label string, token string) (GetConnectTokenResponse, error) {
+	if err := fake.begin(method, nil, userID, label, token); err != nil {
+		return GetConnectTokenResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	connectToken := fake.connectToken(token)
+	if connectToken == nil || connectToken.userID != userID || connectToken.label != label {
+		return GetConnectTokenResponse{}, FakeRequestError(404, "Connect token not found: "+token)
+	}
+	return connectToken.token, nil
+}
+
+// createConnectToken creates a connect token for the user and email account (if set)
+func (fake *FakeClient) createConnectToken(method string, userID string, label string, formValues CreateConnectTokenParams) (CreateConnectTokenResponse, error) {
+	if err := fake.begin(method, formValues, userID, label, formValues); err != nil {
+		return CreateConnectTokenResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(userID) > 0 {
+		if _, err := fake.user(userID); err != nil {
+			return CreateConnectTokenResponse{}, err
+		}
+	}
+	if len(label) > 0 {
+		if _, _, err := fake.emailAccount(userID, label); err != nil {
+			return CreateConnectTokenResponse{}, err
+		}
+	}
+
+	now := fake.now()
+	expires := int(now.Add(24 * time.Hour).Unix())
+	connectToken := &fakeConnectToken{userID: userID, label: label, token: GetConnectTokenResponse{
+		Token:              fake.newID()[8:],
+		Email:              formValues.Email,
+		CallbackURL:        formValues.CallbackURL,
+		StatusCallbackURL:  formValues.StatusCallbackURL,
+		FirstName:          formValues.FirstName,
+		LastName:           formValues.LastName,
+		AccountLite:        true,
+		Created:            NewUnixTime(now),
+		Expires:            ExpiresMixed{Expires: &expires},
+		EmailAccountID:     label,
+		BrowserRedirectURL: "https://connect.context.io/",
+	}}
+	connectToken.token.BrowserRedirectURL += connectToken.token.Token
+	fake.connectTokens = append(fake.connectTokens, connectToken)
+
+	return CreateConnectTokenResponse{
+		Success:            true,
+		Token:              connectToken.token.Token,
+		BrowserRedirectURL: connectToken.token.BrowserRedirectURL,
+	}, nil
+}
+
+// deleteConnectToken deletes a connect token created for exactly this user and email account
+func (fake *FakeClient) deleteConnectToken(method string, userID string, label string, token string) (DeleteConnectTokenResponse, error) {
+	if err := fake.begin(method, nil, userID, label, token); err != nil {
+		return DeleteConnectTokenResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for i, connectToken := range fake.connectTokens {
+		if connectToken.token.Token == token && connectToken.userID == userID && connectToken.label == label {
+			fake.connectTokens = append(fake.connectTokens[:i], fake.connectTokens[i+1:]...)
+			return DeleteConnectTokenResponse{Success: true}, nil
+		}
+	}
+	return DeleteConnectTokenResponse{}, FakeRequestError(404, "Connect token not found: "+token)
+}
+
+// GetConnectTokens gets the app's connect tokens
+func (fake *FakeClient) GetConnectTokens() ([]GetConnectTokenResponse, error) {
+	return fake.getConnectTokens("GetConnectTokens", "", "")
+}
+
+// GetConnectToken gets an app connect token
+func (fake *FakeClient) GetConnectToken(token string) (GetConnectTokenResponse, error) {
+	return fake.getConnectToken("GetConnectToken", "", "", token)
+}
+
+// CreateConnectToken creates an app connect token, which creates a user when used
+func (fake *FakeClient) CreateConnectToken(formValues CreateConnectTokenParams) (CreateConnectTokenResponse, error) {
+	return fake.createConnectToken("CreateConnectToken", "", "", formValues)
+}
+
+// DeleteConnectToken deletes an app connect token
+func (fake *FakeClient) DeleteConnectToken(token string) (DeleteConnectTokenResponse, error) {
+	return fake.deleteConnectToken("DeleteConnectToken", "", "", token)
+}
+
+// CheckConnectToken checks the connect token, as CioLite does
+func (fake *FakeClient) CheckConnectToken(connectToken GetConnectTokenResponse, email string) error {
+	if err := fake.begin("CheckConnectToken", nil, connectToken, email); err != nil {
+		return err
+	}
+	return checkConnectToken(connectToken, email)
+}
+
+// GetDiscovery returns the response set with SetDiscovery for the email, or not found
+func (fake *FakeClient) GetDiscovery(queryValues GetDiscoveryParams) (GetDiscoveryResponse, error) {
+	if err := fake.begin("GetDiscovery", queryValues, queryValues); err != nil {
+		return GetDiscoveryResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if response, ok := fake.discovery[strings.ToLower(queryValues.Email)]; ok {
+		return response, nil
+	}
+	return GetDiscoveryResponse{Email: queryValues.Email}, nil
+}
+
+// GetOAuthProviders gets the app's oauth providers
+func (fake *FakeClient) GetOAuthProviders() ([]GetOAuthProvidersResponse, error) {
+	if err := fake.begin("GetOAuthProviders", nil); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]GetOAuthProvidersResponse(nil), fake.oauthProviders...), nil
+}
+
+// GetOAuthProvider gets an oauth provider by its consumer key
+func (fake *FakeClient) GetOAuthProvider(key string) (GetOAuthProvidersResponse, error) {
+	if err := fake.begin("GetOAuthProvider", nil, key); err != nil {
+		return GetOAuthProvidersResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for _, provider := range fake.oauthProviders {
+		if provider.ProviderConsumerKey == key {
+			return provider, nil
+		}
+	}
+	return GetOAuthProvidersResponse{}, FakeRequestError(404, "OAuth provider not found: "+key)
+}
+
+// CreateOAuthProvider creates an oauth provider, which must not already exist
+func (fake *FakeClient) CreateOAuthProvider(formValues CreateOAuthProviderParams) (CreateOAuthProviderResponse, error) {
+	if err := fake.begin("CreateOAuthProvider", formValues, formValues); err != nil {
+		return CreateOAuthProviderResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for _, provider := range fake.oauthProviders {
+		if provider.ProviderConsumerKey == formValues.ProviderConsumerKey {
+			return CreateOAuthProviderResponse{}, FakeRequestError(400, "OAuth provider already exists: "+formValues.ProviderConsumerKey)
+		}
+	}
+	fake.oauthProviders = append(fake.oauthProviders, GetOAuthProvidersResponse{
+		Type:                   formValues.Type,
+		ProviderConsumerKey:    formValues.ProviderConsumerKey,
+		ProviderConsumerSecret: formValues.ProviderConsumerSecret,
+	})
+	return CreateOAuthProviderResponse{Success: true, ProviderConsumerKey: formValues.ProviderConsumerKey}, nil
+}
+
+// DeleteOAuthProvider deletes an oauth provider
+func (fake *FakeClient) DeleteOAuthProvider(key string) (DeleteOAuthProviderResponse, error) {
+	if err := fake.begin("DeleteOAuthProvider", nil, key); err != nil {
+		return DeleteOAuthProviderResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for i, provider := range fake.oauthProviders {
+		if provider.ProviderConsumerKey == key {
+			fake.oauthProviders = append(fake.oauthProviders[:i], fake.oauthProviders[i+1:]...)
+			return DeleteOAuthProviderResponse{Success: true}, nil
+		}
+	}
+	return DeleteOAuthProviderResponse{}, FakeRequestError(404, "OAuth provider not found: "+key)
+}
+
+// GetUserConnectTokens gets the connect tokens of a user
+func (fake *FakeClient) GetUserConnectTokens(userID string) ([]GetConnectTokenResponse, error) {
+	return fake.getConnectTokens("GetUserConnectTokens", userID, "")
+}
+
+// GetUserConnectToken gets a connect token of a user
+func (fake *FakeClient) GetUserConnectToken(userID string, token string) (GetConnectTokenResponse, error) {
+	return fake.getConnectToken("GetUserConnectToken", userID, "", token)
+}
+
+// CreateUserConnectToken creates a connect token for a user, which adds an email account to it when used
+func (fake *FakeClient) CreateUserConnectToken(userID string, formValues CreateConnectTokenParams) (CreateConnectTokenResponse, error) {
+	return fake.createConnectToken("CreateUserConnectToken", userID, "", formValues)
+}
+
+// DeleteUserConnectToken deletes a connect token of a user
+func (fake *FakeClient) DeleteUserConnectToken(userID string, token string) (DeleteConnectTokenResponse, error) {
+	return fake.deleteConnectToken("DeleteUserConnectToken", userID, "", token)
+}
+
+// GetUserEmailAccountConnectTokens gets the connect tokens of an email account
+func (fake *FakeClient) GetUserEmailAccountConnectTokens(userID string, label string) ([]GetConnectTokenResponse, error) {
+	return fake.getConnectTokens("GetUserEmailAccountConnectTokens", userID, label)
+}
+
+// GetUserEmailAccountConnectToken gets a connect token of an email account
+func (fake *FakeClient) GetUserEmailAccountConnectToken(userID string, label string, token string) (GetConnectTokenResponse, error) {
+	return fake.getConnectToken("GetUserEmailAccountConnectToken", userID, label, token)
+}
+
+// CreateUserEmailAccountConnectToken creates a connect token for an email account, which re-authorizes it when used
+func (fake *FakeClient) CreateUserEmailAccountConnectToken(userID string, label string, formValues CreateConnectTokenParams) (CreateConnectTokenResponse, error) {
+	return fake.createConnectToken("CreateUserEmailAccountConnectToken", userID, label, formValues)
+}
+
+// DeleteUserEmailAccountConnectToken deletes a connect token of an email account
+func (fake *FakeClient) DeleteUserEmailAccountConnectToken(userID string, label string, token string) (DeleteConnectTokenResponse, error) {
+	return fake.deleteConnectToken("DeleteUserEmailAccountConnectToken", userID, label, token)
+}
+
+// GetUserEmailAccountsFolderMessageAttachments gets the attachments of a message
+func (fake *FakeClient) GetUserEmailAccountsFolderMessageAttachments(userID string, label string, folder string, messageID string, queryValues EmailAccountFolderDelimiterParam) ([]GetUserEmailAccountsFolderMessageAttachmentsResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolderMessageAttachments", queryValues, userID, label, folder, messageID, queryValues); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	var attachments []GetUserEmailAccountsFolderMessageAttachmentsResponse
+	for _, attachment := range message.Attachments {
+		attachments = append(attachments, fakeAttachmentResponse(attachment))
+	}
+	return attachments, nil
+}
+
+// GetUserEmailAccountsFolderMessageAttachment gets a single attachment of a message
+func (fake *FakeClient) GetUserEmailAccountsFolderMessageAttachment(userID string, label string, folder string, messageID string, attachmentID string, queryValues GetUserEmailAccountsFolderMessageAttachmentParam) (GetUserEmailAccountsFolderMessageAttachmentsResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolderMessageAttachment", queryValues, userID, label, folder, messageID, attachmentID, queryValues); err != nil {
+		return GetUserEmailAccountsFolderMessageAttachmentsResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return GetUserEmailAccountsFolderMessageAttachmentsResponse{}, err
+	}
+	for _, attachment := range message.Attachments {
+		if strconv.Itoa(attachment.AttachmentID) == attachmentID {
+			response := fakeAttachmentResponse(attachment)
+			if queryValues.AsLink {
+				response.AttachmentLink = fmt.Sprintf("https://fake.context.io/attachments/%s/%s", response.MessageID, attachmentID)
+			}
+			return response, nil
+		}
+	}
+	return GetUserEmailAccountsFolderMessageAttachmentsResponse{}, FakeRequestError(404, "Attachment not found: "+attachmentID)
+}
+
+// fakeAttachmentResponse returns the attachment as the attachments endpoint does
+func fakeAttachmentResponse(attachment UsersEmailAccountFolderMessageAttachment) GetUserEmailAccountsFolderMessageAttachmentsResponse {
+	return GetUserEmailAccountsFolderMessageAttachmentsResponse{
+		Type:               attachment.Type,
+		FileName:           attachment.FileName,
+		BodySection:        attachment.BodySection,
+		ContentDisposition: attachment.ContentDisposition,
+		MessageID:          attachment.MessageID,
+		XAttachmentID:      attachment.XAttachmentID,
+		Size:               attachment.Size,
+		AttachmentID:       attachment.AttachmentID,
+	}
+}
+
+// GetUserEmailAccountsFolderMessageBody gets the bodies of a message, optionally only of one Type
+func (fake *FakeClient) GetUserEmailAccountsFolderMessageBody(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageBodyParams) ([]GetUserEmailAccountsFolderMessageBodyResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolderMessageBody", queryValues, userID, label, folder, messageID, queryValues); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	var bodies []GetUserEmailAccountsFolderMessageBodyResponse
+	for _, body := range message.Bodies {
+		if len(queryValues.Type) == 0 || body.Type == queryValues.Type {
+			bodies = append(bodies, GetUserEmailAccountsFolderMessageBodyResponse{Type: body.Type, Content: body.Content, BodySection: body.BodySection})
+		}
+	}
+	return bodies, nil
+}
+
+// GetUserEmailAccountsFolderMessageFlags gets the flags of a message
+func (fake *FakeClient) GetUserEmailAccountsFolderMessageFlags(userID string, label string, folder string, messageID string, queryValues EmailAccountFolderDelimiterParam) (GetUserEmailAccountsFolderMessageFlagsResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolderMessageFlags", queryValues, userID, label, folder, messageID, queryValues); err != nil {
+		return GetUserEmailAccountsFolderMessageFlagsResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	f, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return GetUserEmailAccountsFolderMessageFlagsResponse{}, err
+	}
+	return GetUserEmailAccountsFolderMessageFlagsResponse{Flags: message.response(f.name, true, false, "").Flags}, nil
+}
+
+// ModifyUserEmailAccountsFolderMessageFlags sets and clears the flags and keywords of a message
+func (fake *FakeClient) ModifyUserEmailAccountsFolderMessageFlags(userID string, label string, folder string, messageID string, formValues ModifyUserEmailAccountsFolderMessageFlagsParams) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	if err := fake.begin("ModifyUserEmailAccountsFolderMessageFlags", formValues, userID, label, folder, messageID, formValues); err != nil {
+		return ModifyUserEmailAccountsFolderMessageFlagsResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	f, message, err := fake.message(userID, label, folder, messageID, formValues.Delimiter)
+	if err != nil {
+		return ModifyUserEmailAccountsFolderMessageFlagsResponse{}, err
+	}
+
+	flags := &message.Flags
+	for _, flag := range []struct {
+		value string
+		flag  *bool
+	}{
+		{formValues.Seen, &flags.Read},
+		{formValues.Answered, &flags.Answered},
+		{formValues.Flagged, &flags.Flagged},
+		{formValues.Deleted, &flags.Deleted},
+		{formValues.Draft, &flags.Draft},
+	} {
+		if len(flag.value) > 0 {
+			*flag.flag = flag.value == "1"
+		}
+	}
+
+	keywords := append([]string(nil), flags.Keywords...)
+	for _, remove := range splitKeywords(formValues.RemoveKeywords) {
+		for i := 0; i < len(keywords); i++ {
+			if strings.EqualFold(keywords[i], remove) {
+				keywords = append(keywords[:i], keywords[i+1:]...)
+				i--
+			}
+		}
+	}
+	for _, add := range splitKeywords(formValues.AddKeywords) {
+		if !(MessageFlags{Keywords: keywords}).Has(MessageFlag(add)) {
+			keywords = append(keywords, add)
+		}
+	}
+	flags.Keywords = keywords
+
+	return ModifyUserEmailAccountsFolderMessageFlagsResponse{Success: true, Flags: message.response(f.name, true, false, "").Flags}, nil
+}
+
+// splitKeywords splits a comma separated list of keywords
+func splitKeywords(keywords string) []string {
+	var split []string
+	for _, keyword := range strings.Split(keywords, ",") {
+		if keyword = strings.TrimSpace(keyword); len(keyword) > 0 {
+			split = append(split, keyword)
+		}
+	}
+	return split
+}
+
+// SetUserEmailAccountsFolderMessageFlag sets a single system flag or keyword on the message
+func (fake *FakeClient) SetUserEmailAccountsFolderMessageFlag(userID string, label string, folder string, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	return fake.ModifyUserEmailAccountsFolderMessageFlags(userID, label, folder, messageID, flag.modifyParams(true, formValues.Delimiter))
+}
+
+// ClearUserEmailAccountsFolderMessageFlag clears a single system flag or keyword from the message
+func (fake *FakeClient) ClearUserEmailAccountsFolderMessageFlag(userID string, label string, folder string, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	return fake.ModifyUserEmailAccountsFolderMessageFlags(userID, label, folder, messageID, flag.modifyParams(false, formValues.Delimiter))
+}
+
+// GetUserEmailAccountsFolderMessageHeaders gets the headers of a message
+func (fake *FakeClient) GetUserEmailAccountsFolderMessageHeaders(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageHeadersParams) (GetUserEmailAccountsFolderMessageHeadersResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolderMessageHeaders", queryValues, userID, label, folder, messageID, queryValues); err != nil {
+		return GetUserEmailAccountsFolderMessageHeadersResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return GetUserEmailAccountsFolderMessageHeadersResponse{}, err
+	}
+	headers := make(map[string][]string, len(message.Headers))
+	for key, values := range message.Headers {
+		headers[key] = append([]string(nil), values...)
+	}
+	return GetUserEmailAccountsFolderMessageHeadersResponse{Headers: headers}, nil
+}
+
+// GetUserEmailAccountsFolderMessageRaw gets the raw message
+func (fake *FakeClient) GetUserEmailAccountsFolderMessageRaw(userID string, label string, folder string, messageID string, queryValues EmailAccountFolderDelimiterParam) (GetUserEmailAccountsFolderMessageRawResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolderMessageRaw", queryValues, userID, label, folder, messageID, queryValues); err != nil {
+		return "", err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return "", err
+	}
+	return GetUserEmailAccountsFolderMessageRawResponse(message.Raw), nil
+}
+
+// markRead sets or clears the read flag of a message
+func (fake *FakeClient) markRead(method string, userID string, label string, folder string, messageID string, formValues EmailAccountFolderDelimiterParam, read bool) (UserEmailAccountsFolderMessageReadResponse, error) {
+	if err := fake.begin(method, formValues, userID, label, folder, messageID, formValues); err != nil {
+		return UserEmailAccountsFolderMessageReadResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, message, err := fake.message(userID, label, folder, messageID, formValues.Delimiter)
+	if err != nil {
+		return UserEmailAccountsFolderMessageReadResponse{}, err
+	}
+	message.Flags.Read = read
+	return UserEmailAccountsFolderMessageReadResponse{Success: true}, nil
+}
+
+// MarkUserEmailAccountsFolderMessageRead sets the read flag of a message
+func (fake *FakeClient) MarkUserEmailAccountsFolderMessageRead(userID string, label string, folder string, messageID string, formValues EmailAccountFolderDelimiterParam) (UserEmailAccountsFolderMessageReadResponse, error) {
+	return fake.markRead("MarkUserEmailAccountsFolderMessageRead", userID, label, folder, messageID, formValues, true)
+}
+
+// MarkUserEmailAccountsFolderMessageUnRead clears the read flag of a message
+func (fake *FakeClient) MarkUserEmailAccountsFolderMessageUnRead(userID string, label string, folder string, messageID string, formValues EmailAccountFolderDelimiterParam) (UserEmailAccountsFolderMessageReadResponse, error) {
+	return fake.markRead("MarkUserEmailAccountsFolderMessageUnRead", userID, label, folder, messageID, formValues, false)
+}
+
+// GetUserEmailAccountsFolderMessages gets the messages of a folder, in the order they were added
+func (fake *FakeClient) GetUserEmailAccountsFolderMessages(userID string, label string, folder string, queryValues GetUserEmailAccountsFolderMessageParams) ([]GetUsersEmailAccountFolderMessagesResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolderMessages", queryValues, userID, label, folder, queryValues); err != nil {
+		return nil, err
+	}
+	return fake.folderMessages(userID, label, folder, queryValues)
+}
+
+// StreamUserEmailAccountsFolderMessages calls fn with each message of a folder, in the order they were added
+func (fake *FakeClient) StreamUserEmailAccountsFolderMessages(userID string, label string, folder string, queryValues GetUserEmailAccountsFolderMessageParams, fn func(GetUsersEmailAccountFolderMessagesResponse) error) error {
+	if err := fake.begin("StreamUserEmailAccountsFolderMessages", queryValues, userID, label, folder, queryValues); err != nil {
+		return err
+	}
+	messages, err := fake.folderMessages(userID, label, folder, queryValues)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		if err = fn(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folderMessages returns the messages of a folder
+func (fake *FakeClient) folderMessages(userID string, label string, folder string, queryValues GetUserEmailAccountsFolderMessageParams) ([]GetUsersEmailAccountFolderMessagesResponse, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, f, err := fake.folder(userID, label, folder, queryValues.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	var messages []GetUsersEmailAccountFolderMessagesResponse
+	start, end := page(len(f.messages), queryValues.Limit, queryValues.Offset)
+	for _, message := range f.messages[start:end] {
+		messages = append(messages, message.response(f.name, queryValues.IncludeFlags, queryValues.IncludeBody, queryValues.BodyType))
+	}
+	return messages, nil
+}
+
+// GetUserEmailAccountFolderMessage gets a message of a folder
+func (fake *FakeClient) GetUserEmailAccountFolderMessage(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageParams) (GetUsersEmailAccountFolderMessagesResponse, error) {
+	if err := fake.begin("GetUserEmailAccountFolderMessage", queryValues, userID, label, folder, messageID, queryValues); err != nil {
+		return GetUsersEmailAccountFolderMessagesResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	f, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return GetUsersEmailAccountFolderMessagesResponse{}, err
+	}
+	return message.response(f.name, queryValues.IncludeFlags, queryValues.IncludeBody, queryValues.BodyType), nil
+}
+
+// MoveUserEmailAccountFolderMessage moves a message to another folder, which must exist
+func (fake *FakeClient) MoveUserEmailAccountFolderMessage(userID string, label string, folder string, messageID string, queryValues MoveUserEmailAccountFolderMessageParams) (MoveUserEmailAccountFolderMessageResponse, error) {
+	if err := fake.begin("MoveUserEmailAccountFolderMessage", queryValues, userID, label, folder, messageID, queryValues); err != nil {
+		return MoveUserEmailAccountFolderMessageResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	f, message, err := fake.message(userID, label, folder, messageID, queryValues.Delimiter)
+	if err != nil {
+		return MoveUserEmailAccountFolderMessageResponse{}, err
+	}
+	_, newFolder, err := fake.folder(userID, label, queryValues.NewFolderID, queryValues.Delimiter)
+	if err != nil {
+		return MoveUserEmailAccountFolderMessageResponse{}, err
+	}
+	if newFolder == f {
+		return MoveUserEmailAccountFolderMessageResponse{Success: true}, nil
+	}
+	for i, m := range f.messages {
+		if m == message {
+			f.messages = append(f.messages[:i], f.messages[i+1:]...)
+			break
+		}
+	}
+	newFolder.messages = append(newFolder.messages, message)
+	return MoveUserEmailAccountFolderMessageResponse{Success: true}, nil
+}
+
+// GetUserEmailAccountsFolders gets the folders of an email account
+func (fake *FakeClient) GetUserEmailAccountsFolders(userID string, label string, queryValues GetUserEmailAccountsFoldersParams) ([]GetUsersEmailAccountFoldersResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsFolders", queryValues, userID, label, queryValues); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return nil, err
+	}
+	var folders []GetUsersEmailAccountFoldersResponse
+	for _, f := range account.folders {
+		folders = append(folders, f.response(account, queryValues.IncludeNamesOnly))
+	}
+	return folders, nil
+}
+
+// GetUserEmailAccountFolder gets a folder of an email account
+func (fake *FakeClient) GetUserEmailAccountFolder(userID string, label string, folder string, queryValues EmailAccountFolderDelimiterParam) (GetUsersEmailAccountFoldersResponse, error) {
+	if err := fake.begin("GetUserEmailAccountFolder", queryValues, userID, label, folder, queryValues); err != nil {
+		return GetUsersEmailAccountFoldersResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	account, f, err := fake.folder(userID, label, folder, queryValues.Delimiter)
+	if err != nil {
+		return GetUsersEmailAccountFoldersResponse{}, err
+	}
+	return f.response(account, false), nil
+}
+
+// CreateUserEmailAccountFolder creates a folder, which must not already exist
+func (fake *FakeClient) CreateUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (CreateEmailAccountFolderResponse, error) {
+	if err := fake.begin("CreateUserEmailAccountFolder", formValues, userID, label, folder, formValues); err != nil {
+		return CreateEmailAccountFolderResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return CreateEmailAccountFolderResponse{}, err
+	}
+	name := account.folderName(folder, formValues.Delimiter)
+	if account.folder(name) != nil {
+		return CreateEmailAccountFolderResponse{}, FakeRequestError(400, "Folder already exists: "+folder)
+	}
+	account.folders = append(account.folders, &fakeFolder{name: name})
+	return CreateEmailAccountFolderResponse{Success: true}, nil
+}
+
+// SafeCreateUserEmailAccountFolder creates a folder if it does not exist, as CioLite does
+func (fake *FakeClient) SafeCreateUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (bool, error) {
+	if err := fake.begin("SafeCreateUserEmailAccountFolder", formValues, userID, label, folder, formValues); err != nil {
+		return false, err
+	}
+	return safeCreateUserEmailAccountFolder(fake, userID, label, folder, formValues)
+}
+
+// RenameUserEmailAccountFolder renames a folder, along with its sub-folders
+func (fake *FakeClient) RenameUserEmailAccountFolder(userID string, label string, folder string, queryValues RenameUserEmailAccountFolderParams) (RenameEmailAccountFolderResponse, error) {
+	if err := fake.begin("RenameUserEmailAccountFolder", queryValues, userID, label, folder, queryValues); err != nil {
+		return RenameEmailAccountFolderResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	account, f, err := fake.folder(userID, label, folder, queryValues.Delimiter)
+	if err != nil {
+		return RenameEmailAccountFolderResponse{}, err
+	}
+	newName := account.folderName(queryValues.NewFolderID, queryValues.Delimiter)
+	if account.folder(newName) != nil {
+		return RenameEmailAccountFolderResponse{}, FakeRequestError(400, "Folder already exists: "+queryValues.NewFolderID)
+	}
+	oldPrefix := f.name + account.delimiter
+	for _, sub := range account.folders {
+		if strings.HasPrefix(sub.name, oldPrefix) {
+			sub.name = newName + account.delimiter + strings.TrimPrefix(sub.name, oldPrefix)
+		}
+	}
+	f.name = newName
+	return RenameEmailAccountFolderResponse{Success: true}, nil
+}
+
+// DeleteUserEmailAccountFolder deletes a folder, along with its messages (but not its sub-folders)
+func (fake *FakeClient) DeleteUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (DeleteEmailAccountFolderResponse, error) {
+	if err := fake.begin("DeleteUserEmailAccountFolder", formValues, userID, label, folder, formValues); err != nil {
+		return DeleteEmailAccountFolderResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	account, f, err := fake.folder(userID, label, folder, formValues.Delimiter)
+	if err != nil {
+		return DeleteEmailAccountFolderResponse{}, err
+	}
+	for i, existing := range account.folders {
+		if existing == f {
+			account.folders = append(account.folders[:i], account.folders[i+1:]...)
+			break
+		}
+	}
+	return DeleteEmailAccountFolderResponse{Success: true}, nil
+}
+
+// SafeCreateAllUserEmailAccountFolders creates a folder along with any missing parents, as CioLite does
+func (fake *FakeClient) SafeCreateAllUserEmailAccountFolders(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	if err := fake.begin("SafeCreateAllUserEmailAccountFolders", formValues, userID, label, folder, formValues); err != nil {
+		return nil, err
+	}
+	return safeCreateAllUserEmailAccountFolders(fake, userID, label, folder, formValues)
+}
+
+// SafeRenameUserEmailAccountFolder renames a folder, first creating any missing parents of the new name, as CioLite does
+func (fake *FakeClient) SafeRenameUserEmailAccountFolder(userID string, label string, folder string, newFolder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	if err := fake.begin("SafeRenameUserEmailAccountFolder", formValues, userID, label, folder, newFolder, formValues); err != nil {
+		return nil, err
+	}
+	return safeRenameUserEmailAccountFolder(fake, userID, label, folder, newFolder, formValues)
+}
+
+// SafeDeleteAllUserEmailAccountFolders deletes a folder along with all of its sub-folders, as CioLite does
+func (fake *FakeClient) SafeDeleteAllUserEmailAccountFolders(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	if err := fake.begin("SafeDeleteAllUserEmailAccountFolders", formValues, userID, label, folder, formValues); err != nil {
+		return nil, err
+	}
+	return safeDeleteAllUserEmailAccountFolders(fake, userID, label, folder, formValues)
+}
+
+// accountMessageResponse returns the message as the email account messages endpoint does
+func accountMessageResponse(m GetUsersEmailAccountFolderMessagesResponse) GetUsersEmailAccountMessagesResponse {
+	response := GetUsersEmailAccountMessagesResponse{
+		MessageID:       m.MessageID,
+		Subject:         m.Subject,
+		InReplyTo:       m.InReplyTo,
+		ResourceURL:     m.ResourceURL,
+		Folders:         m.Folders,
+		References:      m.References,
+		ReceivedHeaders: m.ReceivedHeaders,
+		ListHeaders:     m.ListHeaders,
+		Addresses:       GetUsersEmailAccountMessageAddresses(m.Addresses),
+		PersonInfo:      m.PersonInfo,
+		Flags:           m.Flags,
+		SentAt:          m.SentAt,
+		ReceivedAt:      m.ReceivedAt,
+	}
+	for _, attachment := range m.Attachments {
+		response.Attachments = append(response.Attachments, UsersEmailAccountMessageAttachment(attachment))
+	}
+	for _, body := range m.Bodies {
+		response.Bodies = append(response.Bodies, UsersEmailAccountMessageBody(body))
+	}
+	return response
+}
+
+// GetUserEmailAccountsMessages gets the messages of every folder of an email account
+func (fake *FakeClient) GetUserEmailAccountsMessages(userID string, label string, queryValues GetUserEmailAccountsMessageParams) ([]GetUsersEmailAccountMessagesResponse, error) {
+	if err := fake.begin("GetUserEmailAccountsMessages", queryValues, userID, label, queryValues); err != nil {
+		return nil, err
+	}
+	return fake.accountMessages(userID, label, queryValues)
+}
+
+// StreamUserEmailAccountsMessages calls fn with each message of every folder of an email account
+func (fake *FakeClient) StreamUserEmailAccountsMessages(userID string, label string, queryValues GetUserEmailAccountsMessageParams, fn func(GetUsersEmailAccountMessagesResponse) error) error {
+	if err := fake.begin("StreamUserEmailAccountsMessages", queryValues, userID, label, queryValues); err != nil {
+		return err
+	}
+	messages, err := fake.accountMessages(userID, label, queryValues)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		if err = fn(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountMessages returns the messages of every folder of an email account
+func (fake *FakeClient) accountMessages(userID string, label string, queryValues GetUserEmailAccountsMessageParams) ([]GetUsersEmailAccountMessagesResponse, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return nil, err
+	}
+	var messages []GetUsersEmailAccountMessagesResponse
+	for _, f := range account.folders {
+		for _, message := range f.messages {
+			messages = append(messages, accountMessageResponse(message.response(f.name, queryValues.IncludeFlags, queryValues.IncludeBody, queryValues.BodyType)))
+		}
+	}
+	start, end := page(len(messages), queryValues.Limit, queryValues.Offset)
+	return messages[start:end], nil
+}
+
+// GetUserEmailAccountMessage gets a message of an email account, from whichever folder it is in
+func (fake *FakeClient) GetUserEmailAccountMessage(userID string, label string, messageID string, queryValues GetUserEmailAccountsMessageParams) (GetUsersEmailAccountMessagesResponse, error) {
+	if err := fake.begin("GetUserEmailAccountMessage", queryValues, userID, label, messageID, queryValues); err != nil {
+		return GetUsersEmailAccountMessagesResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return GetUsersEmailAccountMessagesResponse{}, err
+	}
+	for _, f := range account.folders {
+		for _, message := range f.messages {
+			if message.MessageID == messageID {
+				return accountMessageResponse(message.response(f.name, queryValues.IncludeFlags, queryValues.IncludeBody, queryValues.BodyType)), nil
+			}
+		}
+	}
+	return GetUsersEmailAccountMessagesResponse{}, FakeRequestError(404, "Message not found: "+messageID)
+}
+
+// GetUserEmailAccounts gets the email accounts of a user
+func (fake *FakeClient) GetUserEmailAccounts(userID string, queryValues GetUserEmailAccountsParams) ([]GetUsersEmailAccountsResponse, error) {
+	if err := fake.begin("GetUserEmailAccounts", queryValues, userID, queryValues); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	user, err := fake.user(userID)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []GetUsersEmailAccountsResponse
+	for _, account := range user.accounts {
+		if account.hasStatus(queryValues.Status, queryValues.StatusOK) {
+			accounts = append(accounts, account.account)
+		}
+	}
+	return accounts, nil
+}
+
+// GetUserEmailAccount gets an email account of a user
+func (fake *FakeClient) GetUserEmailAccount(userID string, label string) (GetUsersEmailAccountsResponse, error) {
+	if err := fake.begin("GetUserEmailAccount", nil, userID, label); err != nil {
+		return GetUsersEmailAccountsResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return GetUsersEmailAccountsResponse{}, err
+	}
+	return account.account, nil
+}
+
+// CreateUserEmailAccount adds an email account (with an INBOX) to a user, with an OK status
+func (fake *FakeClient) CreateUserEmailAccount(userID string, formValues CreateUserParams) (CreateEmailAccountResponse, error) {
+	if err := fake.begin("CreateUserEmailAccount", formValues, userID, formValues); err != nil {
+		return CreateEmailAccountResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	user, err := fake.user(userID)
+	if err != nil {
+		return CreateEmailAccountResponse{}, err
+	}
+	if len(formValues.Email) == 0 {
+		return CreateEmailAccountResponse{}, FakeRequestError(400, "Missing email")
+	}
+	account := fake.createEmailAccount(user, formValues)
+	return CreateEmailAccountResponse{Status: account.account.Status, Label: account.account.Label}, nil
+}
+
+// ModifyUserEmailAccount modifies an email account (only its Status is kept)
+func (fake *FakeClient) ModifyUserEmailAccount(userID string, label string, formValues ModifyUserEmailAccountParams) (ModifyEmailAccountResponse, error) {
+	if err := fake.begin("ModifyUserEmailAccount", formValues, userID, label, formValues); err != nil {
+		return ModifyEmailAccountResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return ModifyEmailAccountResponse{}, err
+	}
+	if len(formValues.Status) > 0 {
+		account.account.Status = AccountStatus(formValues.Status)
+	}
+	return ModifyEmailAccountResponse{Success: true}, nil
+}
+
+// DeleteUserEmailAccount deletes an email account, along with its connect tokens
+func (fake *FakeClient) DeleteUserEmailAccount(userID string, label string) (DeleteEmailAccountResponse, error) {
+	if err := fake.begin("DeleteUserEmailAccount", nil, userID, label); err != nil {
+		return DeleteEmailAccountResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	user, account, err := fake.emailAccount(userID, label)
+	if err != nil {
+		return DeleteEmailAccountResponse{}, err
+	}
+	for i, existing := range user.accounts {
+		if existing == account {
+			user.accounts = append(user.accounts[:i], user.accounts[i+1:]...)
+			break
+		}
+	}
+	fake.deleteConnectTokens(func(connectToken *fakeConnectToken) bool {
+		return connectToken.userID == userID && connectToken.label == label
+	})
+	return DeleteEmailAccountResponse{Success: true}, nil
+}
+
+// deleteConnectTokens deletes every connect token matching
+func (fake *FakeClient) deleteConnectTokens(matching func(connectToken *fakeConnectToken) bool) {
+	kept := fake.connectTokens[:0]
+	for _, connectToken := range fake.connectTokens {
+		if !matching(connectToken) {
+			kept = append(kept, connectToken)
+		}
+	}
+	fake.connectTokens = kept
+}
+
+// createWebhook returns a new webhook from the params
+func (fake *FakeClient) createWebhook(formValues CreateUserWebhookParams) *GetUsersWebhooksResponse {
+	return &GetUsersWebhooksResponse{
+		CallbackURL:        formValues.CallbackURL,
+		WebhookID:          fake.newID(),
+		FilterTo:           formValues.FilterTo,
+		FilterFrom:         formValues.FilterFrom,
+		FilterCc:           formValues.FilterCC,
+		FilterSubject:      formValues.FilterSubject,
+		FilterThread:       formValues.FilterThread,
+		FilterNewImportant: formValues.FilterNewImportant,
+		FilterFileName:     formValues.FilterFileName,
+		FilterFolderAdded:  formValues.FilterFolderAdded,
+		FilterToDomain:     formValues.FilterToDomain,
+		FilterFromDomain:   formValues.FilterFromDomain,
+		BodyType:           formValues.BodyType,
+		Active:             true,
+		IncludeBody:        formValues.IncludeBody,
+		IncludeHeader:      formValues.IncludeHeader,
+		ReceiveDrafts:      formValues.ReceiveDrafts,
+		ReceiveAllChanges:  formValues.ReceiveAllChanges,
+		ReceiveHistorical:  formValues.ReceiveHistorical,
+	}
+}
+
+// userWebhooks returns the webhooks of the user, or of the app if userID is empty
+func (fake *FakeClient) userWebhooks(userID string) (*[]*GetUsersWebhooksResponse, error) {
+	if len(userID) == 0 {
+		return &fake.webhooks, nil
+	}
+	user, err := fake.user(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user.webhooks, nil
+}
+
+// getWebhooks gets the webhooks of the user, or of the app
+func (fake *FakeClient) getWebhooks(method string, userID string) ([]GetUsersWebhooksResponse, error) {
+	if err := fake.begin(method, nil, userID); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	webhooks, err := fake.userWebhooks(userID)
+	if err != nil {
+		return nil, err
+	}
+	var responses []GetUsersWebhooksResponse
+	for _, webhook := range *webhooks {
+		responses = append(responses, *webhook)
+	}
+	return responses, nil
+}
+
+// getWebhook gets a webhook of the user, or of the app
+func (fake *FakeClient) getWebhook(userID string, webhookID string) (*GetUsersWebhooksResponse, int, *[]*GetUsersWebhooksResponse, error) {
+	webhooks, err := fake.userWebhooks(userID)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	for i, webhook := range *webhooks {
+		if webhook.WebhookID == webhookID {
+			return webhook, i, webhooks, nil
+		}
+	}
+	return nil, 0, nil, FakeRequestError(404, "Webhook not found: "+webhookID)
+}
+
+// GetUserWebhooks gets the webhooks of a user
+func (fake *FakeClient) GetUserWebhooks(userID string) ([]GetUsersWebhooksResponse, error) {
+	return fake.getWebhooks("GetUserWebhooks", userID)
+}
+
+// GetUserWebhook gets a webhook of a user
+func (fake *FakeClient) GetUserWebhook(userID string, webhookID string) (GetUsersWebhooksResponse, error) {
+	if err := fake.begin("GetUserWebhook", nil, userID, webhookID); err != nil {
+		return GetUsersWebhooksResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	webhook, _, _, err := fake.getWebhook(userID, webhookID)
+	if err != nil {
+		return GetUsersWebhooksResponse{}, err
+	}
+	return *webhook, nil
+}
+
+// CreateUserWebhook creates an active webhook for a user
+func (fake *FakeClient) CreateUserWebhook(userID string, formValues CreateUserWebhookParams) (CreateUserWebhookResponse, error) {
+	return fake.createUserWebhook("CreateUserWebhook", userID, formValues)
+}
+
+// createUserWebhook creates an active webhook for the user, or for the app
+func (fake *FakeClient) createUserWebhook(method string, userID string, formValues CreateUserWebhookParams) (CreateUserWebhookResponse, error) {
+	if err := fake.begin(method, formValues, userID, formValues); err != nil {
+		return CreateUserWebhookResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	webhooks, err := fake.userWebhooks(userID)
+	if err != nil {
+		return CreateUserWebhookResponse{}, err
+	}
+	webhook := fake.createWebhook(formValues)
+	*webhooks = append(*webhooks, webhook)
+	return CreateUserWebhookResponse{Success: true, WebhookID: webhook.WebhookID}, nil
+}
+
+// ModifyUserWebhook activates or deactivates a webhook of a user
+func (fake *FakeClient) ModifyUserWebhook(userID string, webhookID string, formValues ModifyUserWebhookParams) (ModifyWebhookResponse, error) {
+	return fake.modifyUserWebhook("ModifyUserWebhook", userID, webhookID, formValues)
+}
+
+// modifyUserWebhook activates or deactivates a webhook of the user, or of the app
+func (fake *FakeClient) modifyUserWebhook(method string, userID string, webhookID string, formValues ModifyUserWebhookParams) (ModifyWebhookResponse, error) {
+	if err := fake.begin(method, formValues, userID, webhookID, formValues); err != nil {
+		return ModifyWebhookResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	webhook, _, _, err := fake.getWebhook(userID, webhookID)
+	if err != nil {
+		return ModifyWebhookResponse{}, err
+	}
+	webhook.Active = formValues.Active
+	return ModifyWebhookResponse{Success: true}, nil
+}
+
+// DeleteUserWebhookAccount deletes a webhook of a user
+func (fake *FakeClient) DeleteUserWebhookAccount(userID string, webhookID string) (DeleteWebhookResponse, error) {
+	return fake.deleteUserWebhook("DeleteUserWebhookAccount", userID, webhookID)
+}
+
+// deleteUserWebhook deletes a webhook of the user, or of the app
+func (fake *FakeClient) deleteUserWebhook(method string, userID string, webhookID string) (DeleteWebhookResponse, error) {
+	if err := fake.begin(method, nil, userID, webhookID); err != nil {
+		return DeleteWebhookResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	_, i, webhooks, err := fake.getWebhook(userID, webhookID)
+	if err != nil {
+		return DeleteWebhookResponse{}, err
+	}
+	*webhooks = append((*webhooks)[:i], (*webhooks)[i+1:]...)
+	return DeleteWebhookResponse{Success: true}, nil
+}
+
+// GetUsers gets the users, in the order they were created
+func (fake *FakeClient) GetUsers(queryValues GetUsersParams) ([]GetUsersResponse, error) {
+	if err := fake.begin("GetUsers", queryValues, queryValues); err != nil {
+		return nil, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	var users []GetUsersResponse
+	for _, user := range fake.users {
+		if !user.hasStatus(queryValues.Status, queryValues.StatusOK) {
+			continue
+		}
+		if len(queryValues.Email) > 0 {
+			found := false
+			for _, email := range user.user.EmailAddresses {
+				found = found || strings.EqualFold(email, queryValues.Email)
+			}
+			if !found {
+				continue
+			}
+		}
+		users = append(users, user.response())
+	}
+	start, end := page(len(users), queryValues.Limit, queryValues.Offset)
+	return users[start:end], nil
+}
+
+// GetUser gets a user
+func (fake *FakeClient) GetUser(userID string) (GetUsersResponse, error) {
+	if err := fake.begin("GetUser", nil, userID); err != nil {
+		return GetUsersResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	user, err := fake.user(userID)
+	if err != nil {
+		return GetUsersResponse{}, err
+	}
+	return user.response(), nil
+}
+
+// CreateUser creates a user, along with an email account if formValues has an Email
+func (fake *FakeClient) CreateUser(formValues CreateUserParams) (CreateUserResponse, error) {
+	if err := fake.begin("CreateUser", formValues, formValues); err != nil {
+		return CreateUserResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	user := fake.createUser(formValues)
+	response := CreateUserResponse{Success: true, ID: user.user.ID}
+	if len(user.accounts) > 0 {
+		response.EmailAccount = CreateEmailAccountResponse{Status: user.accounts[0].account.Status, Label: user.accounts[0].account.Label}
+	}
+	return response, nil
+}
+
+// ModifyUser modifies the name of a user
+func (fake *FakeClient) ModifyUser(userID string, formValues ModifyUserParams) (ModifyUserResponse, error) {
+	if err := fake.begin("ModifyUser", formValues, userID, formValues); err != nil {
+		return ModifyUserResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	user, err := fake.user(userID)
+	if err != nil {
+		return ModifyUserResponse{}, err
+	}
+	user.user.FirstName, user.user.LastName = formValues.FirstName, formValues.LastName
+	return ModifyUserResponse{Success: true}, nil
+}
+
+// DeleteUser deletes a user, along with its email accounts, webhooks, and connect tokens
+func (fake *FakeClient) DeleteUser(userID string) (DeleteUserResponse, error) {
+	if err := fake.begin("DeleteUser", nil, userID); err != nil {
+		return DeleteUserResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	user, err := fake.user(userID)
+	if err != nil {
+		return DeleteUserResponse{}, err
+	}
+	for i, existing := range fake.users {
+		if existing == user {
+			fake.users = append(fake.users[:i], fake.users[i+1:]...)
+			break
+		}
+	}
+	fake.deleteConnectTokens(func(connectToken *fakeConnectToken) bool {
+		return connectToken.userID == userID
+	})
+	return DeleteUserResponse{Success: true}, nil
+}
+
+// GetWebhooks gets the app's webhooks
+func (fake *FakeClient) GetWebhooks() ([]GetUsersWebhooksResponse, error) {
+	return fake.getWebhooks("GetWebhooks", "")
+}
+
+// GetWebhook gets an app webhook
+func (fake *FakeClient) GetWebhook(webhookID string) (GetUsersWebhooksResponse, error) {
+	if err := fake.begin("GetWebhook", nil, webhookID); err != nil {
+		return GetUsersWebhooksResponse{}, err
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	webhook, _, _, err := fake.getWebhook("", webhookID)
+	if err != nil {
+		return GetUsersWebhooksResponse{}, err
+	}
+	return *webhook, nil
+}
+
+// CreateWebhook creates an active app webhook
+func (fake *FakeClient) CreateWebhook(formValues CreateUserWebhookParams) (CreateUserWebhookResponse, error) {
+	return fake.createUserWebhook("CreateWebhook", "", formValues)
+}
+
+// ModifyWebhook activates or deactivates an app webhook
+func (fake *FakeClient) ModifyWebhook(webhookID string, formValues ModifyUserWebhookParams) (ModifyWebhookResponse, error) {
+	return fake.modifyUserWebhook("ModifyWebhook", "", webhookID, formValues)
+}
+
+// DeleteWebhookAccount deletes an app webhook
+func (fake *FakeClient) DeleteWebhookAccount(webhookID string) (DeleteWebhookResponse, error) {
+	return fake.deleteUserWebhook("DeleteWebhookAccount", "", webhookID)
+}
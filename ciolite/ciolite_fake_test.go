@@ -0,0 +1,229 @@
+package ciolite
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Make sure FakeClient implements Interface
+var _ Interface = &FakeClient{}
+
+// newFakeClientWithAccount returns a FakeClient with one user, with one email account
+func newFakeClientWithAccount(t *testing.T) (*FakeClient, string, string) {
+	fake := NewFakeClient()
+	user, err := fake.CreateUser(CreateUserParams{Email: "test@example.com", Server: "imap.example.com", Username: "test@example.com", UseSSL: true, Port: 993, Type: "imap", Password: "secret"})
+	if err != nil || len(user.ID) == 0 || len(user.EmailAccount.Label) == 0 {
+		t.Fatal("Expected a user with an email account; Got: ", user, "; With Error: ", err)
+	}
+	return fake, user.ID, user.EmailAccount.Label
+}
+
+// TestFakeClientMoveMessage tests that moving a message removes it from its folder
+func TestFakeClientMoveMessage(t *testing.T) {
+	t.Parallel()
+
+	fake, userID, label := newFakeClientWithAccount(t)
+	messageID, err := fake.AddMessage(userID, label, "INBOX", FakeMessage{GetUsersEmailAccountFolderMessagesResponse: GetUsersEmailAccountFolderMessagesResponse{Subject: "Hello"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The destination must exist
+	_, err = fake.MoveUserEmailAccountFolderMessage(userID, label, "INBOX", messageID, MoveUserEmailAccountFolderMessageParams{NewFolderID: "Archive"})
+	if ErrorStatusCode(err) != 404 {
+		t.Error("Expected a 404 for a missing folder; Got: ", err)
+	}
+
+	if _, err = fake.CreateUserEmailAccountFolder(userID, label, "Archive", EmailAccountFolderDelimiterParam{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fake.MoveUserEmailAccountFolderMessage(userID, label, "INBOX", messageID, MoveUserEmailAccountFolderMessageParams{NewFolderID: "Archive"}); err != nil {
+		t.Fatal(err)
+	}
+
+	inbox, err := fake.GetUserEmailAccountsFolderMessages(userID, label, "INBOX", GetUserEmailAccountsFolderMessageParams{})
+	if err != nil || len(inbox) != 0 {
+		t.Error("Expected an empty INBOX; Got: ", inbox, "; With Error: ", err)
+	}
+	message, err := fake.GetUserEmailAccountFolderMessage(userID, label, "Archive", messageID, GetUserEmailAccountsFolderMessageParams{})
+	if err != nil || message.Subject != "Hello" || !reflect.DeepEqual(message.Folders, []string{"Archive"}) {
+		t.Error("Expected the message in Archive; Got: ", message, "; With Error: ", err)
+	}
+
+	var streamed []string
+	err = fake.StreamUserEmailAccountsMessages(userID, label, GetUserEmailAccountsMessageParams{}, func(message GetUsersEmailAccountMessagesResponse) error {
+		streamed = append(streamed, message.Folders...)
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(streamed, []string{"Archive"}) {
+		t.Error("Expected to stream the message in Archive; Got: ", streamed, "; With Error: ", err)
+	}
+}
+
+// TestFakeClientFolders tests that SafeCreateUserEmailAccountFolder is idempotent, and renames include sub-folders
+func TestFakeClientFolders(t *testing.T) {
+	t.Parallel()
+
+	fake, userID, label := newFakeClientWithAccount(t)
+
+	created, err := fake.SafeCreateUserEmailAccountFolder(userID, label, "Work", EmailAccountFolderDelimiterParam{})
+	if err != nil || !created {
+		t.Error("Expected the folder to be created; Got: ", created, "; With Error: ", err)
+	}
+	created, err = fake.SafeCreateUserEmailAccountFolder(userID, label, "Work", EmailAccountFolderDelimiterParam{})
+	if err != nil || created {
+		t.Error("Expected the folder to already exist; Got: ", created, "; With Error: ", err)
+	}
+	if _, err = fake.CreateUserEmailAccountFolder(userID, label, "Work", EmailAccountFolderDelimiterParam{}); ErrorStatusCode(err) != 400 {
+		t.Error("Expected a 400 for an existing folder; Got: ", err)
+	}
+
+	if _, err = fake.SafeCreateAllUserEmailAccountFolders(userID, label, "Work.Projects", EmailAccountFolderDelimiterParam{Delimiter: "."}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fake.RenameUserEmailAccountFolder(userID, label, "Work", RenameUserEmailAccountFolderParams{NewFolderID: "Job"}); err != nil {
+		t.Fatal(err)
+	}
+
+	folders, err := fake.GetUserEmailAccountsFolders(userID, label, GetUserEmailAccountsFoldersParams{IncludeNamesOnly: true})
+	expected := []GetUsersEmailAccountFoldersResponse{{Name: "INBOX"}, {Name: "Job"}, {Name: "Job/Projects"}}
+	if err != nil || !reflect.DeepEqual(folders, expected) {
+		t.Error("Expected: ", expected, "; Got: ", folders, "; With Error: ", err)
+	}
+}
+
+// TestFakeClientFlags tests modifying the flags of a message
+func TestFakeClientFlags(t *testing.T) {
+	t.Parallel()
+
+	fake, userID, label := newFakeClientWithAccount(t)
+	messageID, err := fake.AddMessage(userID, label, "INBOX", FakeMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = fake.MarkUserEmailAccountsFolderMessageRead(userID, label, "INBOX", messageID, EmailAccountFolderDelimiterParam{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fake.SetUserEmailAccountsFolderMessageFlag(userID, label, "INBOX", messageID, MessageFlag("Important"), EmailAccountFolderDelimiterParam{}); err != nil {
+		t.Fatal(err)
+	}
+	flags, err := fake.GetUserEmailAccountsFolderMessageFlags(userID, label, "INBOX", messageID, EmailAccountFolderDelimiterParam{})
+	expected := UserEmailAccountsFolderMessageFlags{Read: true, Keywords: []string{"Important"}}
+	if err != nil || !reflect.DeepEqual(flags.Flags, expected) {
+		t.Error("Expected: ", expected, "; Got: ", flags.Flags, "; With Error: ", err)
+	}
+
+	folder, err := fake.GetUserEmailAccountFolder(userID, label, "INBOX", EmailAccountFolderDelimiterParam{})
+	if err != nil || folder.NbMessages != 1 || folder.NbUnseenMessages != 0 {
+		t.Error("Expected 1 seen message; Got: ", folder, "; With Error: ", err)
+	}
+}
+
+// TestFakeClientDeleteUser tests that deleting a user deletes everything that belongs to it
+func TestFakeClientDeleteUser(t *testing.T) {
+	t.Parallel()
+
+	fake, userID, label := newFakeClientWithAccount(t)
+	if _, err := fake.CreateUserWebhook(userID, CreateUserWebhookParams{CallbackURL: "https://example.com/webhook"}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := fake.CreateUserEmailAccountConnectToken(userID, label, CreateConnectTokenParams{CallbackURL: "https://example.com/callback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = fake.DeleteUser(userID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = fake.GetUser(userID); ErrorStatusCode(err) != 404 {
+		t.Error("Expected a 404 for a deleted user; Got: ", err)
+	}
+	if _, err = fake.GetUserEmailAccount(userID, label); ErrorStatusCode(err) != 404 {
+		t.Error("Expected a 404 for an email account of a deleted user; Got: ", err)
+	}
+	if err = fake.UseConnectToken(token.Token, CreateUserParams{}); ErrorStatusCode(err) != 404 {
+		t.Error("Expected a 404 for a connect token of a deleted user; Got: ", err)
+	}
+	users, err := fake.GetUsers(GetUsersParams{})
+	if err != nil || len(users) != 0 {
+		t.Error("Expected no users; Got: ", users, "; With Error: ", err)
+	}
+}
+
+// TestFakeClientConnectToken tests creating a user by using a connect token
+func TestFakeClientConnectToken(t *testing.T) {
+	t.Parallel()
+
+	fake := NewFakeClient()
+	created, err := fake.CreateConnectToken(CreateConnectTokenParams{CallbackURL: "https://example.com/callback", Email: "new@example.com", FirstName: "New"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = fake.UseConnectToken(created.Token, CreateUserParams{Server: "imap.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := fake.GetConnectToken(created.Token)
+	if err != nil || token.Used.IsZero() || len(token.User.ID) == 0 || token.User.FirstName != "New" {
+		t.Error("Expected a used token with a user; Got: ", token, "; With Error: ", err)
+	}
+	if err = fake.CheckConnectToken(token, "new@example.com"); err != nil {
+		t.Error("Expected the token to check out; Got: ", err)
+	}
+
+	users, err := fake.GetUsers(GetUsersParams{Email: "NEW@example.com"})
+	if err != nil || len(users) != 1 || len(users[0].EmailAccounts) != 1 || !users[0].EmailAccounts[0].Status.IsOK() {
+		t.Error("Expected a user with an OK email account; Got: ", users, "; With Error: ", err)
+	}
+}
+
+// TestFakeClientErrors tests injecting errors, and validation
+func TestFakeClientErrors(t *testing.T) {
+	t.Parallel()
+
+	fake, userID, _ := newFakeClientWithAccount(t)
+
+	fake.FailNext("GetUser", FakeRequestError(503, "Unavailable"))
+	if _, err := fake.GetUser(userID); ErrorStatusCode(err) != 503 {
+		t.Error("Expected the injected error; Got: ", err)
+	}
+	if _, err := fake.GetUser(userID); err != nil {
+		t.Error("Expected only the next call to fail; Got: ", err)
+	}
+
+	fake.Fail("GetUsers", errors.New("down"))
+	for i := 0; i < 2; i++ {
+		if _, err := fake.GetUsers(GetUsersParams{}); err == nil {
+			t.Error("Expected every call to fail")
+		}
+	}
+	fake.Fail("GetUsers", nil)
+	if _, err := fake.GetUsers(GetUsersParams{}); err != nil {
+		t.Error("Expected calls to succeed again; Got: ", err)
+	}
+
+	fake.ErrorHook = func(method string, args ...interface{}) error {
+		if method == "DeleteUser" && args[0] == userID {
+			return FakeRequestError(403, "Forbidden")
+		}
+		return nil
+	}
+	if _, err := fake.DeleteUser(userID); ErrorStatusCode(err) != 403 {
+		t.Error("Expected the hook's error; Got: ", err)
+	}
+	if _, err := fake.GetUser(userID); err != nil {
+		t.Error("Expected the failed call to change nothing; Got: ", err)
+	}
+
+	if _, err := fake.CreateUserWebhook(userID, CreateUserWebhookParams{CallbackURL: "not a url"}); err == nil {
+		t.Error("Expected a validation error")
+	}
+
+	if calls := fake.Calls(); len(calls) != 9 || calls[0] != "CreateUser" || calls[8] != "CreateUserWebhook" {
+		t.Error("Expected the calls to be recorded; Got: ", calls)
+	}
+}
@@ -368,6 +368,45 @@ func (mr *MockInterfaceMockRecorder) GetUserEmailAccountsFolderMessageFlags(user
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEmailAccountsFolderMessageFlags", reflect.TypeOf((*MockInterface)(nil).GetUserEmailAccountsFolderMessageFlags), userID, label, folder, messageID, queryValues)
 }
 
+// ModifyUserEmailAccountsFolderMessageFlags mocks base method
+func (m *MockInterface) ModifyUserEmailAccountsFolderMessageFlags(userID, label, folder, messageID string, formValues ModifyUserEmailAccountsFolderMessageFlagsParams) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	ret := m.ctrl.Call(m, "ModifyUserEmailAccountsFolderMessageFlags", userID, label, folder, messageID, formValues)
+	ret0, _ := ret[0].(ModifyUserEmailAccountsFolderMessageFlagsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModifyUserEmailAccountsFolderMessageFlags indicates an expected call of ModifyUserEmailAccountsFolderMessageFlags
+func (mr *MockInterfaceMockRecorder) ModifyUserEmailAccountsFolderMessageFlags(userID, label, folder, messageID, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyUserEmailAccountsFolderMessageFlags", reflect.TypeOf((*MockInterface)(nil).ModifyUserEmailAccountsFolderMessageFlags), userID, label, folder, messageID, formValues)
+}
+
+// SetUserEmailAccountsFolderMessageFlag mocks base method
+func (m *MockInterface) SetUserEmailAccountsFolderMessageFlag(userID, label, folder, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	ret := m.ctrl.Call(m, "SetUserEmailAccountsFolderMessageFlag", userID, label, folder, messageID, flag, formValues)
+	ret0, _ := ret[0].(ModifyUserEmailAccountsFolderMessageFlagsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserEmailAccountsFolderMessageFlag indicates an expected call of SetUserEmailAccountsFolderMessageFlag
+func (mr *MockInterfaceMockRecorder) SetUserEmailAccountsFolderMessageFlag(userID, label, folder, messageID, flag, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserEmailAccountsFolderMessageFlag", reflect.TypeOf((*MockInterface)(nil).SetUserEmailAccountsFolderMessageFlag), userID, label, folder, messageID, flag, formValues)
+}
+
+// ClearUserEmailAccountsFolderMessageFlag mocks base method
+func (m *MockInterface) ClearUserEmailAccountsFolderMessageFlag(userID, label, folder, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	ret := m.ctrl.Call(m, "ClearUserEmailAccountsFolderMessageFlag", userID, label, folder, messageID, flag, formValues)
+	ret0, _ := ret[0].(ModifyUserEmailAccountsFolderMessageFlagsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearUserEmailAccountsFolderMessageFlag indicates an expected call of ClearUserEmailAccountsFolderMessageFlag
+func (mr *MockInterfaceMockRecorder) ClearUserEmailAccountsFolderMessageFlag(userID, label, folder, messageID, flag, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearUserEmailAccountsFolderMessageFlag", reflect.TypeOf((*MockInterface)(nil).ClearUserEmailAccountsFolderMessageFlag), userID, label, folder, messageID, flag, formValues)
+}
+
 // GetUserEmailAccountsFolderMessageHeaders mocks base method
 func (m *MockInterface) GetUserEmailAccountsFolderMessageHeaders(userID, label, folder, messageID string, queryValues GetUserEmailAccountsFolderMessageHeadersParams) (GetUserEmailAccountsFolderMessageHeadersResponse, error) {
 	ret := m.ctrl.Call(m, "GetUserEmailAccountsFolderMessageHeaders", userID, label, folder, messageID, queryValues)
@@ -433,6 +472,18 @@ func (mr *MockInterfaceMockRecorder) GetUserEmailAccountsFolderMessages(userID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEmailAccountsFolderMessages", reflect.TypeOf((*MockInterface)(nil).GetUserEmailAccountsFolderMessages), userID, label, folder, queryValues)
 }
 
+// StreamUserEmailAccountsFolderMessages mocks base method
+func (m *MockInterface) StreamUserEmailAccountsFolderMessages(userID, label, folder string, queryValues GetUserEmailAccountsFolderMessageParams, fn func(GetUsersEmailAccountFolderMessagesResponse) error) error {
+	ret := m.ctrl.Call(m, "StreamUserEmailAccountsFolderMessages", userID, label, folder, queryValues, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamUserEmailAccountsFolderMessages indicates an expected call of StreamUserEmailAccountsFolderMessages
+func (mr *MockInterfaceMockRecorder) StreamUserEmailAccountsFolderMessages(userID, label, folder, queryValues, fn interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamUserEmailAccountsFolderMessages", reflect.TypeOf((*MockInterface)(nil).StreamUserEmailAccountsFolderMessages), userID, label, folder, queryValues, fn)
+}
+
 // GetUserEmailAccountFolderMessage mocks base method
 func (m *MockInterface) GetUserEmailAccountFolderMessage(userID, label, folder, messageID string, queryValues GetUserEmailAccountsFolderMessageParams) (GetUsersEmailAccountFolderMessagesResponse, error) {
 	ret := m.ctrl.Call(m, "GetUserEmailAccountFolderMessage", userID, label, folder, messageID, queryValues)
@@ -511,6 +562,71 @@ func (mr *MockInterfaceMockRecorder) SafeCreateUserEmailAccountFolder(userID, la
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeCreateUserEmailAccountFolder", reflect.TypeOf((*MockInterface)(nil).SafeCreateUserEmailAccountFolder), userID, label, folder, formValues)
 }
 
+// RenameUserEmailAccountFolder mocks base method
+func (m *MockInterface) RenameUserEmailAccountFolder(userID, label, folder string, queryValues RenameUserEmailAccountFolderParams) (RenameEmailAccountFolderResponse, error) {
+	ret := m.ctrl.Call(m, "RenameUserEmailAccountFolder", userID, label, folder, queryValues)
+	ret0, _ := ret[0].(RenameEmailAccountFolderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameUserEmailAccountFolder indicates an expected call of RenameUserEmailAccountFolder
+func (mr *MockInterfaceMockRecorder) RenameUserEmailAccountFolder(userID, label, folder, queryValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUserEmailAccountFolder", reflect.TypeOf((*MockInterface)(nil).RenameUserEmailAccountFolder), userID, label, folder, queryValues)
+}
+
+// DeleteUserEmailAccountFolder mocks base method
+func (m *MockInterface) DeleteUserEmailAccountFolder(userID, label, folder string, formValues EmailAccountFolderDelimiterParam) (DeleteEmailAccountFolderResponse, error) {
+	ret := m.ctrl.Call(m, "DeleteUserEmailAccountFolder", userID, label, folder, formValues)
+	ret0, _ := ret[0].(DeleteEmailAccountFolderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserEmailAccountFolder indicates an expected call of DeleteUserEmailAccountFolder
+func (mr *MockInterfaceMockRecorder) DeleteUserEmailAccountFolder(userID, label, folder, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserEmailAccountFolder", reflect.TypeOf((*MockInterface)(nil).DeleteUserEmailAccountFolder), userID, label, folder, formValues)
+}
+
+// SafeCreateAllUserEmailAccountFolders mocks base method
+func (m *MockInterface) SafeCreateAllUserEmailAccountFolders(userID, label, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	ret := m.ctrl.Call(m, "SafeCreateAllUserEmailAccountFolders", userID, label, folder, formValues)
+	ret0, _ := ret[0].([]FolderOperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SafeCreateAllUserEmailAccountFolders indicates an expected call of SafeCreateAllUserEmailAccountFolders
+func (mr *MockInterfaceMockRecorder) SafeCreateAllUserEmailAccountFolders(userID, label, folder, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeCreateAllUserEmailAccountFolders", reflect.TypeOf((*MockInterface)(nil).SafeCreateAllUserEmailAccountFolders), userID, label, folder, formValues)
+}
+
+// SafeRenameUserEmailAccountFolder mocks base method
+func (m *MockInterface) SafeRenameUserEmailAccountFolder(userID, label, folder, newFolder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	ret := m.ctrl.Call(m, "SafeRenameUserEmailAccountFolder", userID, label, folder, newFolder, formValues)
+	ret0, _ := ret[0].([]FolderOperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SafeRenameUserEmailAccountFolder indicates an expected call of SafeRenameUserEmailAccountFolder
+func (mr *MockInterfaceMockRecorder) SafeRenameUserEmailAccountFolder(userID, label, folder, newFolder, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeRenameUserEmailAccountFolder", reflect.TypeOf((*MockInterface)(nil).SafeRenameUserEmailAccountFolder), userID, label, folder, newFolder, formValues)
+}
+
+// SafeDeleteAllUserEmailAccountFolders mocks base method
+func (m *MockInterface) SafeDeleteAllUserEmailAccountFolders(userID, label, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	ret := m.ctrl.Call(m, "SafeDeleteAllUserEmailAccountFolders", userID, label, folder, formValues)
+	ret0, _ := ret[0].([]FolderOperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SafeDeleteAllUserEmailAccountFolders indicates an expected call of SafeDeleteAllUserEmailAccountFolders
+func (mr *MockInterfaceMockRecorder) SafeDeleteAllUserEmailAccountFolders(userID, label, folder, formValues interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SafeDeleteAllUserEmailAccountFolders", reflect.TypeOf((*MockInterface)(nil).SafeDeleteAllUserEmailAccountFolders), userID, label, folder, formValues)
+}
+
 // GetUserEmailAccountsMessages mocks base method
 func (m *MockInterface) GetUserEmailAccountsMessages(userID, label string, queryValues GetUserEmailAccountsMessageParams) ([]GetUsersEmailAccountMessagesResponse, error) {
 	ret := m.ctrl.Call(m, "GetUserEmailAccountsMessages", userID, label, queryValues)
@@ -524,6 +640,18 @@ func (mr *MockInterfaceMockRecorder) GetUserEmailAccountsMessages(userID, label,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEmailAccountsMessages", reflect.TypeOf((*MockInterface)(nil).GetUserEmailAccountsMessages), userID, label, queryValues)
 }
 
+// StreamUserEmailAccountsMessages mocks base method
+func (m *MockInterface) StreamUserEmailAccountsMessages(userID, label string, queryValues GetUserEmailAccountsMessageParams, fn func(GetUsersEmailAccountMessagesResponse) error) error {
+	ret := m.ctrl.Call(m, "StreamUserEmailAccountsMessages", userID, label, queryValues, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamUserEmailAccountsMessages indicates an expected call of StreamUserEmailAccountsMessages
+func (mr *MockInterfaceMockRecorder) StreamUserEmailAccountsMessages(userID, label, queryValues, fn interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamUserEmailAccountsMessages", reflect.TypeOf((*MockInterface)(nil).StreamUserEmailAccountsMessages), userID, label, queryValues, fn)
+}
+
 // GetUserEmailAccountMessage mocks base method
 func (m *MockInterface) GetUserEmailAccountMessage(userID, label, messageID string, queryValues GetUserEmailAccountsMessageParams) (GetUsersEmailAccountMessagesResponse, error) {
 	ret := m.ctrl.Call(m, "GetUserEmailAccountMessage", userID, label, messageID, queryValues)
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"sync"
 	"testing"
 	"time"
 )
@@ -88,15 +89,26 @@ func Must(err error) {
 	}
 }
 
-// TestLogger is a *bytes.Buffer that implements the logging interface
+// TestLogger is a *bytes.Buffer that implements the logging interface,
+// and is safe to use from concurrent requests
 type TestLogger struct {
 	*bytes.Buffer
+	mu sync.Mutex
 }
 
 // Printf prints the arguments to the buffer, using fmt.Sprintf
 func (l *TestLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	_, err := l.Write([]byte(fmt.Sprintf(format, v...)))
 	if err != nil {
 		panic("Error writing to test logger: " + err.Error())
 	}
 }
+
+// String returns the contents of the buffer
+func (l *TestLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Buffer.String()
+}
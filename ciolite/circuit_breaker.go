@@ -0,0 +1,301 @@
+package ciolite
+
+// Circuit breaker, failing requests fast for endpoints and accounts that keep failing
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CircuitState is the state of a circuit of a CircuitBreaker
+type CircuitState int
+
+// CircuitState values
+const (
+	// CircuitClosed lets requests through, counting consecutive failures
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails requests fast with a CircuitOpenError, until OpenDuration has passed
+	CircuitOpen
+
+	// CircuitHalfOpen lets up to HalfOpenRequests trial requests through:
+	// a success closes the circuit, and a failure opens it again
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(state))
+}
+
+// CircuitOpenError is the cause of the RequestError returned, without sending anything, while a circuit is open
+type CircuitOpenError struct {
+	// Key is the circuit's key (ex: "GET /lite/users/{id}/email_accounts/{id}/folders", or "userID/label")
+	Key string
+
+	// RetryAt is when a trial request will be let through
+	RetryAt time.Time
+}
+
+// Error returns the circuit's key, and when a trial request will be let through
+func (e CircuitOpenError) Error() string {
+	return fmt.Sprintf("CIO: Circuit open for %s until %s", e.Key, e.RetryAt.Format(time.RFC3339))
+}
+
+// IsCircuitOpen returns true if the request failed fast because its circuit is open
+func IsCircuitOpen(err error) bool {
+	_, ok := errors.Cause(err).(CircuitOpenError)
+	return ok
+}
+
+// CircuitBreaker fails requests fast (with a CircuitOpenError) once their circuit has failed FailureThreshold times in a row,
+// ex: so that workers do not pile up waiting for timeouts while an account's IMAP server is down.
+// By default every request goes through two circuits, one per endpoint (see EndpointTemplate) and one per account
+// (userID/label, or userID for user requests), and fails fast if either is open: a failing endpoint does not
+// take down every account, and a failing account (ex: its IMAP server) does not take down the endpoint.
+// Set it as the CircuitBreaker of one or more CioLite's; it is safe for concurrent use.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens a circuit, defaults to 5
+	FailureThreshold int
+
+	// OpenDuration is how long a circuit stays open before letting trial requests through, defaults to 30s
+	OpenDuration time.Duration
+
+	// HalfOpenRequests is the number of concurrent trial requests let through a half-open circuit, defaults to 1
+	HalfOpenRequests int
+
+	// Key, if set, returns the only circuit of a request, instead of the endpoint and account circuits
+	// (ex: EndpointTemplate(request.Method, request.Path) + " " + request.UserID, for a circuit per endpoint and user)
+	Key func(request ClientRequest) string
+
+	// IsFailure, if set, returns true for the errors that count as failures.
+	// Defaults to errors without a status code (ex: timeouts) and 5xx status codes.
+	IsFailure func(err error) bool
+
+	// OnStateChange, if set, is called (ex: for alerting) every time a circuit changes state
+	OnStateChange func(key string, from CircuitState, to CircuitState)
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of a single circuit
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+}
+
+// NewCircuitBreaker returns a CircuitBreaker that opens after failureThreshold consecutive failures, for openDuration
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{FailureThreshold: failureThreshold, OpenDuration: openDuration}
+}
+
+// endpointCollections are the path segments of the api that are followed by an id (or label, folder, token, etc)
+var endpointCollections = map[string]bool{
+	"users":           true,
+	"email_accounts":  true,
+	"folders":         true,
+	"messages":        true,
+	"attachments":     true,
+	"connect_tokens":  true,
+	"webhooks":        true,
+	"oauth_providers": true,
+	"accounts":        true,
+	"sources":         true,
+	"contacts":        true,
+	"files":           true,
+	"threads":         true,
+}
+
+// EndpointTemplate returns the method and path with the ids (the segments following collections,
+// such as users or folders) replaced by {id}, ex: "GET /lite/users/{id}/email_accounts/{id}/folders".
+// Other segments, ex: "GET /app/status_callback_url", are kept.
+func EndpointTemplate(method string, path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if endpointCollections[segments[i]] {
+			i++
+			segments[i] = "{id}"
+		}
+	}
+	return method + " /" + strings.Join(segments, "/")
+}
+
+// State returns the state of a circuit
+func (cb *CircuitBreaker) State(key string) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c, ok := cb.circuits[key]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// keys returns the circuits of the request: Key, or else the endpoint and account circuits
+func (cb *CircuitBreaker) keys(request ClientRequest) []string {
+	if cb.Key != nil {
+		return []string{cb.Key(request)}
+	}
+	keys := []string{EndpointTemplate(request.Method, request.Path)}
+	if len(request.UserID) > 0 {
+		account := request.UserID
+		if len(request.AccountLabel) > 0 {
+			account += "/" + request.AccountLabel
+		}
+		keys = append(keys, account)
+	}
+	return keys
+}
+
+// now returns the current time
+func (cb *CircuitBreaker) now() time.Time {
+	if cb.Now != nil {
+		return cb.Now()
+	}
+	return time.Now()
+}
+
+// isFailure returns true if the error counts as a failure
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	if cb.IsFailure != nil {
+		return cb.IsFailure(err)
+	}
+	if err == nil {
+		return false
+	}
+	statusCode := ErrorStatusCode(err)
+	return statusCode == 0 || statusCode >= 500
+}
+
+// failureThreshold returns the FailureThreshold, or its default
+func (cb *CircuitBreaker) failureThreshold() int {
+	if cb.FailureThreshold > 0 {
+		return cb.FailureThreshold
+	}
+	return 5
+}
+
+// openDuration returns the OpenDuration, or its default
+func (cb *CircuitBreaker) openDuration() time.Duration {
+	if cb.OpenDuration > 0 {
+		return cb.OpenDuration
+	}
+	return 30 * time.Second
+}
+
+// halfOpenRequests returns the HalfOpenRequests, or its default
+func (cb *CircuitBreaker) halfOpenRequests() int {
+	if cb.HalfOpenRequests > 0 {
+		return cb.HalfOpenRequests
+	}
+	return 1
+}
+
+// allow returns a CircuitOpenError if any of the circuits does not let the request through.
+// The circuits are all checked before any is changed, so that a trial request is only counted if it is let through.
+func (cb *CircuitBreaker) allow(keys ...string) error {
+	cb.mu.Lock()
+	now := cb.now()
+	for _, key := range keys {
+		c, ok := cb.circuits[key]
+		if !ok {
+			continue
+		}
+		if c.state == CircuitOpen {
+			if retryAt := c.openedAt.Add(cb.openDuration()); now.Before(retryAt) {
+				cb.mu.Unlock()
+				return CircuitOpenError{Key: key, RetryAt: retryAt}
+			}
+		} else if c.state == CircuitHalfOpen && c.trials >= cb.halfOpenRequests() {
+			cb.mu.Unlock()
+			return CircuitOpenError{Key: key, RetryAt: now}
+		}
+	}
+
+	// Closed circuits are not kept, so the states of missing circuits stay closed
+	from, to := make([]CircuitState, len(keys)), make([]CircuitState, len(keys))
+	for i, key := range keys {
+		c, ok := cb.circuits[key]
+		if !ok {
+			continue
+		}
+		from[i] = c.state
+		if c.state == CircuitOpen {
+			c.state, c.trials = CircuitHalfOpen, 0
+		}
+		if c.state == CircuitHalfOpen {
+			c.trials++
+		}
+		to[i] = c.state
+	}
+	cb.mu.Unlock()
+
+	for i, key := range keys {
+		cb.stateChanged(key, from[i], to[i])
+	}
+	return nil
+}
+
+// record records the outcome of a request let through the circuit
+func (cb *CircuitBreaker) record(key string, err error) {
+	failed := cb.isFailure(err)
+
+	cb.mu.Lock()
+	c, ok := cb.circuits[key]
+	if !ok {
+		if !failed {
+			cb.mu.Unlock()
+			return
+		}
+		if cb.circuits == nil {
+			cb.circuits = map[string]*circuit{}
+		}
+		c = &circuit{}
+		cb.circuits[key] = c
+	}
+
+	from := c.state
+	switch {
+	case c.state == CircuitHalfOpen && failed:
+		c.state, c.openedAt, c.trials = CircuitOpen, cb.now(), 0
+	case c.state == CircuitHalfOpen:
+		c.state = CircuitClosed
+		delete(cb.circuits, key)
+	case c.state == CircuitClosed && failed:
+		c.failures++
+		if c.failures >= cb.failureThreshold() {
+			c.state, c.openedAt = CircuitOpen, cb.now()
+		}
+	case c.state == CircuitClosed:
+		// Closed circuits without failures are forgotten
+		delete(cb.circuits, key)
+	}
+	to := c.state
+	cb.mu.Unlock()
+
+	cb.stateChanged(key, from, to)
+}
+
+// stateChanged calls OnStateChange, if the state changed
+func (cb *CircuitBreaker) stateChanged(key string, from CircuitState, to CircuitState) {
+	if from != to && cb.OnStateChange != nil {
+		cb.OnStateChange(key, from, to)
+	}
+}
@@ -0,0 +1,235 @@
+package ciolite
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestEndpointTemplate tests replacing the ids of paths
+func TestEndpointTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"/lite/discovery":          "GET /lite/discovery",
+		"/lite/users/u1":           "GET /lite/users/{id}",
+		"/app/status_callback_url": "GET /app/status_callback_url",
+		"/lite/oauth_providers/k1": "GET /lite/oauth_providers/{id}",
+		"/lite/users/u1/email_accounts/0/folders/Work%2FProjects/messages":        "GET /lite/users/{id}/email_accounts/{id}/folders/{id}/messages",
+		"/lite/users/u1/email_accounts/0/folders/messages/messages/m1/body":       "GET /lite/users/{id}/email_accounts/{id}/folders/{id}/messages/{id}/body",
+		"/lite/users/u1/email_accounts/0/folders/INBOX/messages/m1/attachments/1": "GET /lite/users/{id}/email_accounts/{id}/folders/{id}/messages/{id}/attachments/{id}",
+		"/2.0/accounts/a1/messages/m1/body":                                       "GET /2.0/accounts/{id}/messages/{id}/body",
+		"/2.0/accounts/a1/contacts/c1/files":                                      "GET /2.0/accounts/{id}/contacts/{id}/files",
+		"/2.0/accounts/a1/sources/0/sync":                                         "GET /2.0/accounts/{id}/sources/{id}/sync",
+	}
+	for path, expected := range tests {
+		if template := EndpointTemplate("GET", path); template != expected {
+			t.Error("Expected: ", expected, "; Got: ", template)
+		}
+	}
+}
+
+// TestSimulatedCircuitBreaker tests that failing accounts and endpoints fail fast, without affecting other accounts
+// and endpoints, and recover
+func TestSimulatedCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu           sync.Mutex
+		down         = true
+		messagesDown = false
+		calls        = map[string]int{}
+		now          = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/u1/email_accounts/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.URL.Path]++
+		failing := down && r.URL.Path == "/lite/users/u1/email_accounts/down/folders" || messagesDown && strings.HasSuffix(r.URL.Path, "/messages")
+		mu.Unlock()
+
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/lite/users/u1/email_accounts/0/folders/Missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		Must(json.NewEncoder(w).Encode([]GetUsersEmailAccountFoldersResponse{{Name: "INBOX"}}))
+	})
+
+	var changes []string
+	circuitBreaker := NewCircuitBreaker(2, time.Minute)
+	circuitBreaker.Now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	circuitBreaker.OnStateChange = func(key string, from CircuitState, to CircuitState) {
+		changes = append(changes, key+": "+from.String()+" -> "+to.String())
+	}
+
+	cioLite, testServer := NewTestCioLiteServer(mux, WithCircuitBreaker(circuitBreaker))
+	defer testServer.Close()
+
+	getCalls := func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls[path]
+	}
+	advance := func(changeState func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(time.Minute)
+		changeState()
+	}
+
+	// Consecutive failures of an account open its circuit, but not the endpoint's while other accounts succeed
+	for i := 0; i < 2; i++ {
+		if _, err := cioLite.GetUserEmailAccountsFolders("u1", "down", GetUserEmailAccountsFoldersParams{}); ErrorStatusCode(err) != 503 {
+			t.Error("Expected a 503; Got: ", err)
+		}
+		if _, err := cioLite.GetUserEmailAccountsFolders("u1", "0", GetUserEmailAccountsFoldersParams{}); err != nil {
+			t.Error("Expected another account to succeed; Got: ", err)
+		}
+	}
+	accountKey, foldersKey := "u1/down", "GET /lite/users/{id}/email_accounts/{id}/folders"
+	if state := circuitBreaker.State(accountKey); state != CircuitOpen {
+		t.Error("Expected the account circuit to be open; Got: ", state)
+	}
+	if state := circuitBreaker.State(foldersKey); state != CircuitClosed {
+		t.Error("Expected the endpoint circuit to be closed; Got: ", state)
+	}
+
+	// An open account circuit fails fast, for every endpoint
+	_, err := cioLite.GetUserEmailAccountsFolders("u1", "down", GetUserEmailAccountsFoldersParams{})
+	if !IsCircuitOpen(err) || getCalls("/lite/users/u1/email_accounts/down/folders") != 2 {
+		t.Error("Expected a circuit open error, without a request; Got: ", err)
+	}
+	_, err = cioLite.GetUserEmailAccountsMessages("u1", "down", GetUserEmailAccountsMessageParams{})
+	if !IsCircuitOpen(err) || getCalls("/lite/users/u1/email_accounts/down/messages") != 0 {
+		t.Error("Expected a circuit open error for another endpoint, without a request; Got: ", err)
+	}
+
+	// Other accounts, and 4xx errors, are not affected
+	for i := 0; i < 3; i++ {
+		if _, err = cioLite.GetUserEmailAccountFolder("u1", "0", "Missing", EmailAccountFolderDelimiterParam{}); ErrorStatusCode(err) != 404 {
+			t.Error("Expected a 404; Got: ", err)
+		}
+	}
+	if _, err = cioLite.GetUserEmailAccountsFolders("u1", "0", GetUserEmailAccountsFoldersParams{}); err != nil {
+		t.Error("Expected another account to succeed; Got: ", err)
+	}
+
+	// Consecutive failures of an endpoint, across accounts, open its circuit for every account
+	mu.Lock()
+	messagesDown = true
+	mu.Unlock()
+	for _, label := range []string{"0", "1"} {
+		if _, err = cioLite.GetUserEmailAccountsMessages("u1", label, GetUserEmailAccountsMessageParams{}); ErrorStatusCode(err) != 503 {
+			t.Error("Expected a 503; Got: ", err)
+		}
+	}
+	messagesKey := "GET /lite/users/{id}/email_accounts/{id}/messages"
+	if state := circuitBreaker.State(messagesKey); state != CircuitOpen {
+		t.Error("Expected the endpoint circuit to be open; Got: ", state)
+	}
+	_, err = cioLite.GetUserEmailAccountsMessages("u1", "2", GetUserEmailAccountsMessageParams{})
+	if !IsCircuitOpen(err) || getCalls("/lite/users/u1/email_accounts/2/messages") != 0 {
+		t.Error("Expected a circuit open error for another account, without a request; Got: ", err)
+	}
+	if _, err = cioLite.GetUserEmailAccountsFolders("u1", "0", GetUserEmailAccountsFoldersParams{}); err != nil {
+		t.Error("Expected another endpoint to succeed; Got: ", err)
+	}
+
+	// A failed trial opens the circuit again
+	advance(func() {})
+	if _, err = cioLite.GetUserEmailAccountsFolders("u1", "down", GetUserEmailAccountsFoldersParams{}); ErrorStatusCode(err) != 503 {
+		t.Error("Expected a trial request; Got: ", err)
+	}
+	if _, err = cioLite.GetUserEmailAccountsFolders("u1", "down", GetUserEmailAccountsFoldersParams{}); !IsCircuitOpen(err) {
+		t.Error("Expected the circuit to be open again; Got: ", err)
+	}
+
+	// A successful trial closes the circuit
+	advance(func() { down, messagesDown = false, false })
+	for i := 0; i < 2; i++ {
+		if _, err = cioLite.GetUserEmailAccountsFolders("u1", "down", GetUserEmailAccountsFoldersParams{}); err != nil {
+			t.Error("Expected the account to recover; Got: ", err)
+		}
+	}
+	if _, err = cioLite.GetUserEmailAccountsMessages("u1", "2", GetUserEmailAccountsMessageParams{}); err != nil {
+		t.Error("Expected the endpoint to recover; Got: ", err)
+	}
+
+	expected := []string{
+		accountKey + ": closed -> open",
+		messagesKey + ": closed -> open",
+		accountKey + ": open -> half-open", accountKey + ": half-open -> open",
+		accountKey + ": open -> half-open", accountKey + ": half-open -> closed",
+		messagesKey + ": open -> half-open", messagesKey + ": half-open -> closed",
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Error("Expected: ", expected, "; Got: ", changes)
+	}
+}
+
+// TestCircuitBreakerAllowAll tests that a request is only let through (using up a trial) if all its circuits let it through
+func TestCircuitBreakerAllowAll(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	circuitBreaker := &CircuitBreaker{FailureThreshold: 1, OpenDuration: time.Minute, Now: func() time.Time { return now }}
+	circuitBreaker.record("endpoint", RequestError{ErrorMetaData: ErrorMetaData{StatusCode: 500}})
+	now = now.Add(30 * time.Second)
+	circuitBreaker.record("account", RequestError{ErrorMetaData: ErrorMetaData{StatusCode: 500}})
+
+	// The endpoint's trial is not used up while the account is open
+	now = now.Add(30 * time.Second)
+	if err := circuitBreaker.allow("endpoint", "account"); !IsCircuitOpen(err) || err.(CircuitOpenError).Key != "account" {
+		t.Error("Expected the account circuit to be open; Got: ", err)
+	}
+	if state := circuitBreaker.State("endpoint"); state != CircuitOpen {
+		t.Error("Expected the endpoint circuit to be unchanged; Got: ", state)
+	}
+	if err := circuitBreaker.allow("endpoint", "other"); err != nil {
+		t.Error("Expected a trial request; Got: ", err)
+	}
+	if state := circuitBreaker.State("endpoint"); state != CircuitHalfOpen {
+		t.Error("Expected the endpoint circuit to be half-open; Got: ", state)
+	}
+}
+
+// TestCircuitBreakerHalfOpenRequests tests that a half-open circuit only lets HalfOpenRequests trial requests through
+func TestCircuitBreakerHalfOpenRequests(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	circuitBreaker := &CircuitBreaker{FailureThreshold: 1, HalfOpenRequests: 2, Now: func() time.Time { return now }}
+	circuitBreaker.record("key", RequestError{ErrorMetaData: ErrorMetaData{StatusCode: 500}})
+
+	if err := circuitBreaker.allow("key"); !IsCircuitOpen(err) {
+		t.Error("Expected the circuit to be open; Got: ", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	for i := 0; i < 2; i++ {
+		if err := circuitBreaker.allow("key"); err != nil {
+			t.Error("Expected a trial request; Got: ", err)
+		}
+	}
+	if err := circuitBreaker.allow("key"); !IsCircuitOpen(err) {
+		t.Error("Expected no more trial requests; Got: ", err)
+	}
+
+	circuitBreaker.record("key", nil)
+	if state := circuitBreaker.State("key"); state != CircuitClosed {
+		t.Error("Expected the circuit to be closed; Got: ", state)
+	}
+}
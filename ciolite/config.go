@@ -0,0 +1,232 @@
+package ciolite
+
+// Configuration loading, from the environment or from json or yaml files
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the configuration of a CioLite, as loaded by ConfigFromEnv or LoadConfig
+type Config struct {
+	// Required:
+	Key    string `json:"key" yaml:"key" valid:"required"`
+	Secret string `json:"secret" yaml:"secret" valid:"required"`
+
+	// Optional:
+	Host string `json:"host,omitempty" yaml:"host,omitempty" valid:"url"`
+
+	// Timeout and RetryBackoff are durations, ex: "30s".
+	// Retries are made with WithRetry, so only GET requests are retried on errors other than 429.
+	Timeout      string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Retries      int    `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryBackoff string `json:"retry_backoff,omitempty" yaml:"retry_backoff,omitempty"`
+
+	// RateLimit is the number of requests allowed each second, with bursts of up to RateBurst
+	RateLimit float64 `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	RateBurst int     `json:"rate_burst,omitempty" yaml:"rate_burst,omitempty"`
+
+	// AppName and AppVersion are sent in the User-Agent header
+	AppName    string `json:"app_name,omitempty" yaml:"app_name,omitempty"`
+	AppVersion string `json:"app_version,omitempty" yaml:"app_version,omitempty"`
+
+	// Compression asks for compressed responses, and MaxResponseBytes limits their size
+	Compression      bool  `json:"compression,omitempty" yaml:"compression,omitempty"`
+	MaxResponseBytes int64 `json:"max_response_bytes,omitempty" yaml:"max_response_bytes,omitempty"`
+
+	// MaxIdleConnsPerHost, if set, uses NewHighFanoutTransport
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty" yaml:"max_idle_conns_per_host,omitempty"`
+}
+
+// configEnv are the environment variables read by ConfigFromEnv, for each config key
+var configEnv = []struct {
+	key  string
+	vars []string
+}{
+	{"key", []string{"CIO_API_KEY", "CONTEXTIO_API_KEY"}},
+	{"secret", []string{"CIO_API_SECRET", "CONTEXTIO_API_SECRET"}},
+	{"host", []string{"CIO_API_HOST"}},
+	{"timeout", []string{"CIO_API_TIMEOUT"}},
+	{"retries", []string{"CIO_API_RETRIES"}},
+	{"retry_backoff", []string{"CIO_API_RETRY_BACKOFF"}},
+	{"rate_limit", []string{"CIO_API_RATE_LIMIT"}},
+	{"rate_burst", []string{"CIO_API_RATE_BURST"}},
+	{"app_name", []string{"CIO_APP_NAME"}},
+	{"app_version", []string{"CIO_APP_VERSION"}},
+	{"compression", []string{"CIO_API_COMPRESSION"}},
+	{"max_response_bytes", []string{"CIO_API_MAX_RESPONSE_BYTES"}},
+	{"max_idle_conns_per_host", []string{"CIO_API_MAX_IDLE_CONNS_PER_HOST"}},
+}
+
+// FromEnv returns a CioLite configured from the environment (see ConfigFromEnv),
+// with the options applied after the configuration.
+func FromEnv(opts ...Option) (CioLite, error) {
+	config, err := ConfigFromEnv(os.Getenv)
+	if err != nil {
+		return CioLite{}, err
+	}
+	return config.New(opts...)
+}
+
+// ConfigFromEnv returns the Config from the environment variables:
+// CIO_API_KEY and CIO_API_SECRET (or CONTEXTIO_API_KEY and CONTEXTIO_API_SECRET), CIO_API_HOST,
+// CIO_API_TIMEOUT, CIO_API_RETRIES, CIO_API_RETRY_BACKOFF, CIO_API_RATE_LIMIT, CIO_API_RATE_BURST,
+// CIO_APP_NAME, CIO_APP_VERSION, CIO_API_COMPRESSION, CIO_API_MAX_RESPONSE_BYTES, and CIO_API_MAX_IDLE_CONNS_PER_HOST.
+// getenv is usually os.Getenv.
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	var config Config
+	for _, env := range configEnv {
+		for _, name := range env.vars {
+			if value := getenv(name); len(value) > 0 {
+				if err := config.set(env.key, value); err != nil {
+					return config, errors.Wrapf(err, "CIO: Invalid %s", name)
+				}
+				break
+			}
+		}
+	}
+	return config, config.Validate()
+}
+
+// LoadConfig returns the Config from a json (.json) or yaml (.yaml or .yml) file.
+// Yaml files have the same keys as json files, and unknown keys are an error.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, errors.Wrapf(err, "CIO: Unable to read config %s", path)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &config)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &config)
+	default:
+		return config, errors.Errorf("CIO: Unknown config format: %s", path)
+	}
+	if err != nil {
+		return config, errors.Wrapf(err, "CIO: Unable to parse config %s", path)
+	}
+	return config, config.Validate()
+}
+
+// set sets the config key (as named in json) from a string
+func (config *Config) set(key string, value string) error {
+	var err error
+	switch key {
+	case "key":
+		config.Key = value
+	case "secret":
+		config.Secret = value
+	case "host":
+		config.Host = value
+	case "timeout":
+		config.Timeout = value
+	case "retries":
+		config.Retries, err = strconv.Atoi(value)
+	case "retry_backoff":
+		config.RetryBackoff = value
+	case "rate_limit":
+		config.RateLimit, err = strconv.ParseFloat(value, 64)
+	case "rate_burst":
+		config.RateBurst, err = strconv.Atoi(value)
+	case "app_name":
+		config.AppName = value
+	case "app_version":
+		config.AppVersion = value
+	case "compression":
+		config.Compression, err = strconv.ParseBool(value)
+	case "max_response_bytes":
+		config.MaxResponseBytes, err = strconv.ParseInt(value, 10, 64)
+	case "max_idle_conns_per_host":
+		config.MaxIdleConnsPerHost, err = strconv.Atoi(value)
+	default:
+		return errors.Errorf("Unknown config key: %s", key)
+	}
+	return errors.Wrapf(err, "Invalid %s: %s", key, value)
+}
+
+// Validate returns an error if a required value is missing.
+// Other values (ex: durations) are checked when New builds the options.
+func (config Config) Validate() error {
+	return validateParams(config)
+}
+
+// Options returns the options for the configuration (other than the key and secret), or an error if a value is invalid
+func (config Config) Options() ([]Option, error) {
+	var opts []Option
+
+	if len(config.Host) > 0 {
+		opts = append(opts, WithHost(config.Host))
+	}
+
+	if len(config.Timeout) > 0 {
+		timeout, err := time.ParseDuration(config.Timeout)
+		if err != nil || timeout < 0 {
+			return nil, errors.Errorf("CIO: Invalid config timeout: %s", config.Timeout)
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+
+	if config.Retries < 0 {
+		return nil, errors.Errorf("CIO: Invalid config retries: %d", config.Retries)
+	}
+	if config.Retries > 0 || len(config.RetryBackoff) > 0 {
+		backoff := time.Second
+		if len(config.RetryBackoff) > 0 {
+			var err error
+			if backoff, err = time.ParseDuration(config.RetryBackoff); err != nil || backoff < 0 {
+				return nil, errors.Errorf("CIO: Invalid config retry backoff: %s", config.RetryBackoff)
+			}
+		}
+		opts = append(opts, WithRetry(config.Retries, backoff))
+	}
+
+	if config.RateLimit < 0 || config.RateBurst < 0 {
+		return nil, errors.Errorf("CIO: Invalid config rate limit: %v with burst %d", config.RateLimit, config.RateBurst)
+	}
+	if config.RateLimit > 0 {
+		opts = append(opts, WithLimiter(NewLimiter(config.RateLimit, config.RateBurst)))
+	}
+
+	if len(config.AppName) > 0 {
+		opts = append(opts, WithUserAgent(config.AppName, config.AppVersion))
+	}
+
+	if config.MaxResponseBytes < 0 || config.MaxIdleConnsPerHost < 0 {
+		return nil, errors.Errorf("CIO: Invalid config max response bytes %d or max idle conns per host %d", config.MaxResponseBytes, config.MaxIdleConnsPerHost)
+	}
+	if config.Compression {
+		opts = append(opts, WithCompression())
+	}
+	if config.MaxResponseBytes > 0 {
+		opts = append(opts, WithMaxResponseBytes(config.MaxResponseBytes))
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		opts = append(opts, WithHighFanoutTransport(config.MaxIdleConnsPerHost))
+	}
+
+	return opts, nil
+}
+
+// New returns a CioLite for the configuration, with the options applied after the configuration
+func (config Config) New(opts ...Option) (CioLite, error) {
+	if err := config.Validate(); err != nil {
+		return CioLite{}, err
+	}
+	configOpts, err := config.Options()
+	if err != nil {
+		return CioLite{}, err
+	}
+	return New(config.Key, config.Secret, append(configOpts, opts...)...)
+}
@@ -0,0 +1,106 @@
+package ciolite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestLoadConfig tests loading json and yaml config files
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "ciolite-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	expected := Config{
+		Key:          "key",
+		Secret:       "se:cret",
+		Host:         "https://example.com",
+		Timeout:      "30s",
+		Retries:      2,
+		RetryBackoff: "500ms",
+		RateLimit:    2.5,
+		RateBurst:    5,
+		AppName:      "myapp",
+		AppVersion:   "1.0",
+	}
+
+	files := map[string]string{
+		"config.json": `{"key": "key", "secret": "se:cret", "host": "https://example.com", "timeout": "30s", "retries": 2,
+			"retry_backoff": "500ms", "rate_limit": 2.5, "rate_burst": 5, "app_name": "myapp", "app_version": "1.0"}`,
+		"config.yaml": "# CIO\nkey: key\nsecret: \"se:cret\"\nhost: https://example.com # us\n\ntimeout: 30s\nretries: 2\n" +
+			"retry_backoff: 500ms\nrate_limit: 2.5\nrate_burst: 5\napp_name: 'myapp'\napp_version: \"1.0\"\n",
+		"flow.yml": "---\n{key: key, secret: \"se:cret\", host: \"https://example.com\", timeout: 30s, retries: 2,\n" +
+			"  retry_backoff: 500ms, rate_limit: 2.5, rate_burst: 5, app_name: myapp, app_version: \"1.0\"}\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		Must(ioutil.WriteFile(path, []byte(contents), 0600))
+
+		config, err := LoadConfig(path)
+		if err != nil || !reflect.DeepEqual(config, expected) {
+			t.Error("Expected: ", expected, "; Got: ", config, "; With Error: ", err, "; For: ", name)
+		}
+	}
+
+	invalid := map[string]string{
+		"missing.json": `{"key": "key"}`,
+		"unknown.yml":  "key: key\nsecret: secret\ncolor: blue\n",
+		"list.yaml":    "key: [key]\nsecret: secret\n",
+		"config.toml":  `key = "key"`,
+	}
+	for name, contents := range invalid {
+		path := filepath.Join(dir, name)
+		Must(ioutil.WriteFile(path, []byte(contents), 0600))
+
+		if config, err := LoadConfig(path); err == nil {
+			t.Error("Expected an error; Got: ", config, "; For: ", name)
+		}
+	}
+
+	// Values other than the key and secret are only checked when creating the client
+	path := filepath.Join(dir, "timeout.json")
+	Must(ioutil.WriteFile(path, []byte(`{"key": "key", "secret": "secret", "timeout": "soon"}`), 0600))
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Error("Expected no error loading an invalid timeout; Got: ", err)
+	}
+	if _, err = config.New(); err == nil {
+		t.Error("Expected an error for an invalid timeout; Got: ", config)
+	}
+}
+
+// TestConfigFromEnv tests loading the config from the environment, and creating a client from it
+func TestConfigFromEnv(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"CONTEXTIO_API_KEY":    "key",
+		"CONTEXTIO_API_SECRET": "secret",
+		"CIO_API_SECRET":       "cio-secret",
+		"CIO_API_TIMEOUT":      "10s",
+		"CIO_APP_NAME":         "myapp",
+	}
+	config, err := ConfigFromEnv(func(name string) string { return env[name] })
+	expected := Config{Key: "key", Secret: "cio-secret", Timeout: "10s", AppName: "myapp"}
+	if err != nil || !reflect.DeepEqual(config, expected) {
+		t.Error("Expected: ", expected, "; Got: ", config, "; With Error: ", err)
+	}
+
+	cioLite, err := config.New()
+	if err != nil || cioLite.HTTPClient.Timeout != 10*time.Second || cioLite.UserAgent != "myapp Golang CIO Library" || cioLite.Host != DefaultHost {
+		t.Error("Expected a configured client; Got: ", cioLite, "; With Error: ", err)
+	}
+
+	env["CIO_API_RETRIES"] = "many"
+	if _, err = ConfigFromEnv(func(name string) string { return env[name] }); err == nil {
+		t.Error("Expected an error for invalid retries")
+	}
+}
@@ -0,0 +1,222 @@
+package ciolite
+
+// Janitor that sweeps expired and abandoned connect_tokens at every scope
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ConnectTokenScope is the level a connect token was created at
+type ConnectTokenScope string
+
+// ConnectTokenScope values
+const (
+	ConnectTokenScopeApp          ConnectTokenScope = "app"
+	ConnectTokenScopeUser         ConnectTokenScope = "user"
+	ConnectTokenScopeEmailAccount ConnectTokenScope = "email_account"
+)
+
+// ConnectTokenClass is how the janitor classified a connect token
+type ConnectTokenClass string
+
+// ConnectTokenClass values
+const (
+	// ConnectTokenExpired tokens were never used, and their expiry time has passed
+	ConnectTokenExpired ConnectTokenClass = "expired"
+	// ConnectTokenUnused tokens were never used, and were created longer ago than MaxUnusedAge
+	ConnectTokenUnused ConnectTokenClass = "unused"
+	// ConnectTokenUsed tokens have already been used to connect an account
+	ConnectTokenUsed ConnectTokenClass = "used"
+	// ConnectTokenActive tokens are still waiting to be used, and are always kept
+	ConnectTokenActive ConnectTokenClass = "active"
+)
+
+// ConnectTokenJanitorOptions configure SweepConnectTokens.
+// The zero value deletes expired and used tokens, and does not look at token age.
+type ConnectTokenJanitorOptions struct {
+	// MaxUnusedAge, if set, classifies unused tokens created longer ago than this as ConnectTokenUnused
+	MaxUnusedAge time.Duration
+
+	// KeepUsed keeps tokens that have already been used, instead of deleting them
+	KeepUsed bool
+
+	// DryRun classifies and reports the tokens without deleting anything
+	DryRun bool
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// ConnectTokenSweepResult is the outcome for a single connect token
+type ConnectTokenSweepResult struct {
+	Scope  ConnectTokenScope
+	UserID string
+	Label  string
+	Token  string
+	Email  string
+	Class  ConnectTokenClass
+
+	// Deleted is true if the token was deleted (always false on a dry run)
+	Deleted bool
+
+	// Err is any error received while deleting the token
+	Err error
+}
+
+// ConnectTokenSweepReport is the report produced by SweepConnectTokens
+type ConnectTokenSweepReport struct {
+	DryRun bool
+
+	Results []ConnectTokenSweepResult
+
+	// Counts holds the number of tokens in each class
+	Counts map[ConnectTokenClass]int
+
+	Deleted int
+	Failed  int
+
+	// ListErrors holds the errors received while listing tokens, users, or accounts.
+	// A failed listing does not stop the sweep of other scopes.
+	ListErrors []error
+}
+
+// SweepConnectTokens lists the connect tokens of the app, of every user, and of every user email account,
+// classifies them, and deletes the expired, unused, and (unless KeepUsed) used ones through the
+// matching Delete call for their scope. The returned error is only set if the users could not be listed.
+func SweepConnectTokens(client Interface, options ConnectTokenJanitorOptions) (ConnectTokenSweepReport, error) {
+
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	report := ConnectTokenSweepReport{
+		DryRun: options.DryRun,
+		Counts: map[ConnectTokenClass]int{},
+	}
+	now := options.Now()
+
+	// Tokens can show up at more than one scope, so only handle each once
+	seen := map[string]bool{}
+	sweep := func(scope ConnectTokenScope, userID string, label string, tokens []GetConnectTokenResponse) {
+		for _, token := range tokens {
+			if seen[token.Token] {
+				continue
+			}
+			seen[token.Token] = true
+			report.add(sweepConnectToken(client, options, now, scope, userID, label, token))
+		}
+	}
+
+	// App
+	tokens, err := client.GetConnectTokens()
+	if err != nil {
+		report.ListErrors = append(report.ListErrors, err)
+	}
+
+	// Users and their email accounts
+	users, err := listAllUsers(client)
+	if err != nil {
+		return report, err
+	}
+
+	// User and email account tokens are swept at their own scope first, so they are deleted through the most specific call
+	for _, user := range users {
+		for _, account := range user.EmailAccounts {
+			accountTokens, err := client.GetUserEmailAccountConnectTokens(user.ID, account.Label)
+			if err != nil {
+				report.ListErrors = append(report.ListErrors, err)
+				continue
+			}
+			sweep(ConnectTokenScopeEmailAccount, user.ID, account.Label, accountTokens)
+		}
+
+		userTokens, err := client.GetUserConnectTokens(user.ID)
+		if err != nil {
+			report.ListErrors = append(report.ListErrors, err)
+			continue
+		}
+		sweep(ConnectTokenScopeUser, user.ID, "", userTokens)
+	}
+
+	sweep(ConnectTokenScopeApp, "", "", tokens)
+
+	return report, nil
+}
+
+// ClassifyConnectToken returns the class of the connect token at the time now.
+// Tokens are only classified as ConnectTokenUnused if maxUnusedAge is greater than zero.
+func ClassifyConnectToken(token GetConnectTokenResponse, now time.Time, maxUnusedAge time.Duration) ConnectTokenClass {
+	if !token.Used.IsZero() || !token.Expires.Unused() {
+		return ConnectTokenUsed
+	}
+	if int64(token.Expires.Timestamp()) <= now.Unix() {
+		return ConnectTokenExpired
+	}
+	if maxUnusedAge > 0 && !token.Created.IsZero() && now.Sub(token.Created.Time()) > maxUnusedAge {
+		return ConnectTokenUnused
+	}
+	return ConnectTokenActive
+}
+
+// sweepConnectToken classifies and (unless this is a dry run or the token is kept) deletes a single token
+func sweepConnectToken(client Interface, options ConnectTokenJanitorOptions, now time.Time, scope ConnectTokenScope, userID string, label string, token GetConnectTokenResponse) ConnectTokenSweepResult {
+
+	result := ConnectTokenSweepResult{
+		Scope:  scope,
+		UserID: userID,
+		Label:  label,
+		Token:  token.Token,
+		Email:  token.Email,
+		Class:  ClassifyConnectToken(token, now, options.MaxUnusedAge),
+	}
+
+	if options.DryRun || result.Class == ConnectTokenActive || (result.Class == ConnectTokenUsed && options.KeepUsed) {
+		return result
+	}
+
+	var response DeleteConnectTokenResponse
+	switch scope {
+	case ConnectTokenScopeEmailAccount:
+		response, result.Err = client.DeleteUserEmailAccountConnectToken(userID, label, token.Token)
+	case ConnectTokenScopeUser:
+		response, result.Err = client.DeleteUserConnectToken(userID, token.Token)
+	default:
+		response, result.Err = client.DeleteConnectToken(token.Token)
+	}
+	if result.Err == nil && !response.Success {
+		result.Err = errors.New("Unable to delete connect token. CIO returned 200 but with Success=false")
+	}
+	result.Deleted = result.Err == nil
+	return result
+}
+
+// add adds a single result to the report
+func (report *ConnectTokenSweepReport) add(result ConnectTokenSweepResult) {
+	report.Results = append(report.Results, result)
+	report.Counts[result.Class]++
+	if result.Deleted {
+		report.Deleted++
+	}
+	if result.Err != nil {
+		report.Failed++
+	}
+}
+
+// listAllUsers pages through GetUsers and returns every user
+func listAllUsers(client Interface) ([]GetUsersResponse, error) {
+	const pageSize = 100
+
+	var users []GetUsersResponse
+	for offset := 0; ; offset += pageSize {
+		page, err := client.GetUsers(GetUsersParams{Limit: pageSize, Offset: offset})
+		if err != nil {
+			return users, err
+		}
+		users = append(users, page...)
+		if len(page) < pageSize {
+			return users, nil
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ciolite
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSimulatedSweepConnectTokens tests SweepConnectTokens with a simulated server
+func TestSimulatedSweepConnectTokens(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+	recordDelete := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != "DELETE" {
+			return false
+		}
+		mu.Lock()
+		deleted = append(deleted, r.URL.Path)
+		mu.Unlock()
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+		return true
+	}
+
+	// now is 1500000000; expired 1400000000, future 1600000000
+	mux.HandleFunc("/lite/connect_tokens", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `[
+			{"token": "appExpired", "created": 1399000000, "used": 0, "expires": 1400000000},
+			{"token": "appActive", "created": 1499990000, "used": 0, "expires": 1600000000},
+			{"token": "userStale", "created": 1400000000, "used": 0, "expires": 1600000000}
+		]`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/connect_tokens/", func(w http.ResponseWriter, r *http.Request) {
+		recordDelete(w, r)
+	})
+	mux.HandleFunc("/lite/users", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `[{"id": "u1", "email_accounts": [{"label": "0", "status": "OK"}]}]`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/u1/connect_tokens", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `[{"token": "userStale", "created": 1400000000, "used": 0, "expires": 1600000000}]`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/u1/connect_tokens/", func(w http.ResponseWriter, r *http.Request) {
+		recordDelete(w, r)
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/connect_tokens", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `[{"token": "accountUsed", "created": 1400000000, "used": 1400000100, "expires": false}]`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/connect_tokens/", func(w http.ResponseWriter, r *http.Request) {
+		recordDelete(w, r)
+	})
+
+	options := ConnectTokenJanitorOptions{
+		MaxUnusedAge: 24 * time.Hour,
+		DryRun:       true,
+		Now:          func() time.Time { return time.Unix(1500000000, 0) },
+	}
+
+	// Dry run
+	report, err := SweepConnectTokens(cioLite, options)
+	expectedCounts := map[ConnectTokenClass]int{
+		ConnectTokenExpired: 1,
+		ConnectTokenActive:  1,
+		ConnectTokenUnused:  1,
+		ConnectTokenUsed:    1,
+	}
+	if err != nil || len(report.ListErrors) > 0 || !reflect.DeepEqual(report.Counts, expectedCounts) || report.Deleted != 0 || len(deleted) != 0 {
+		t.Error("Expected dry run counts: ", expectedCounts, "; Got: ", report, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+
+	// Actual sweep
+	options.DryRun = false
+	report, err = SweepConnectTokens(cioLite, options)
+	sort.Strings(deleted)
+	expectedDeleted := []string{
+		"/lite/connect_tokens/appExpired",
+		"/lite/users/u1/connect_tokens/userStale",
+		"/lite/users/u1/email_accounts/0/connect_tokens/accountUsed",
+	}
+	if err != nil || report.Deleted != 3 || report.Failed != 0 || !reflect.DeepEqual(deleted, expectedDeleted) {
+		t.Error("Expected deleted: ", expectedDeleted, "; Got: ", deleted, "; With Report: ", report, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+}
@@ -0,0 +1,266 @@
+package ciolite
+
+// Rotation of an email account's credentials (OAuth refresh token or password), with verification and rollback
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Credentials are what an email account authenticates with: either an OAuth refresh token
+// (and the consumer key of the provider it was issued by), or a password
+type Credentials struct {
+	Password             string
+	ProviderRefreshToken string
+	ProviderConsumerKey  string
+}
+
+// IsEmpty returns true if there is neither a password nor a refresh token
+func (c Credentials) IsEmpty() bool {
+	return len(c.Password) == 0 && len(c.ProviderRefreshToken) == 0
+}
+
+// params returns the form values that set the credentials and force a status check
+func (c Credentials) params() ModifyUserEmailAccountParams {
+	return ModifyUserEmailAccountParams{
+		Password:             c.Password,
+		ProviderRefreshToken: c.ProviderRefreshToken,
+		ProviderConsumerKey:  c.ProviderConsumerKey,
+		ForceStatusCheck:     true,
+	}
+}
+
+// CredentialOutcome is how a credential change turned out
+type CredentialOutcome string
+
+// CredentialOutcome values
+const (
+	// CredentialOK means the account connected with the credentials
+	CredentialOK CredentialOutcome = "ok"
+	// CredentialInvalid means the mail server (or OAuth provider) rejected the credentials
+	CredentialInvalid CredentialOutcome = "invalid_credentials"
+	// CredentialConnectionFailed means the mail server could not be reached, so the credentials were not tested
+	CredentialConnectionFailed CredentialOutcome = "connection_failed"
+	// CredentialTemporaryFailure means CIO or the mail server failed in a way that is worth retrying later
+	CredentialTemporaryFailure CredentialOutcome = "temporary_failure"
+	// CredentialDisabled means the account is disabled, so the credentials were not tested
+	CredentialDisabled CredentialOutcome = "disabled"
+	// CredentialRejected means CIO refused the request itself (ex: an unknown consumer key)
+	CredentialRejected CredentialOutcome = "rejected"
+	// CredentialUnknown means the outcome could not be determined
+	CredentialUnknown CredentialOutcome = "unknown"
+)
+
+// credentialFeedback maps words found in feedback codes and connection logs to outcomes,
+// checked in order (so that ex: "temporary authentication failure" is not mistaken for invalid credentials)
+var credentialFeedback = []struct {
+	words   []string
+	outcome CredentialOutcome
+}{
+	{[]string{"temp_disabled", "temporar", "throttl", "too many", "rate limit", "try again", "unavailable"}, CredentialTemporaryFailure},
+	{[]string{"invalid_credentials", "invalid_grant", "invalid_token", "authenticat", "credential", "password", "login failed", "logon failure", "refresh token", "unauthorized", "revoked"}, CredentialInvalid},
+	{[]string{"connection_impossible", "connect", "timed out", "timeout", "unreachable", "resolve", "no such host", "ssl", "tls", "certificate"}, CredentialConnectionFailed},
+	{[]string{"disabled"}, CredentialDisabled},
+}
+
+// ClassifyCredentialFeedback interprets the response of ModifyUserEmailAccount (ex: with ForceStatusCheck).
+// A recognized FeedbackCode is preferred. The ConnectionLog is only looked at when the response is not a
+// Success, because the log of a successful login also mentions authentication, passwords, ssl, etc.
+// A Success without a feedback code is CredentialOK.
+func ClassifyCredentialFeedback(response ModifyEmailAccountResponse) CredentialOutcome {
+	if outcome, ok := classifyFeedbackText(response.FeedbackCode); ok {
+		return outcome
+	}
+	if response.Success {
+		if len(strings.TrimSpace(response.FeedbackCode)) == 0 {
+			return CredentialOK
+		}
+		return CredentialUnknown
+	}
+	if outcome, ok := classifyFeedbackText(response.ConnectionLog); ok {
+		return outcome
+	}
+	return CredentialUnknown
+}
+
+// classifyFeedbackText returns the outcome of the first words of credentialFeedback found in the text
+func classifyFeedbackText(text string) (CredentialOutcome, bool) {
+	text = strings.ToLower(text)
+	for _, feedback := range credentialFeedback {
+		for _, word := range feedback.words {
+			if strings.Contains(text, word) {
+				return feedback.outcome, true
+			}
+		}
+	}
+	return CredentialUnknown, false
+}
+
+// ClassifyAccountStatus interprets an email account status
+func ClassifyAccountStatus(status AccountStatus) CredentialOutcome {
+	switch status {
+	case AccountStatusOK:
+		return CredentialOK
+	case AccountStatusInvalidCredentials:
+		return CredentialInvalid
+	case AccountStatusConnectionImpossible:
+		return CredentialConnectionFailed
+	case AccountStatusTempDisabled:
+		return CredentialTemporaryFailure
+	case AccountStatusDisabled:
+		return CredentialDisabled
+	}
+	return CredentialUnknown
+}
+
+// classifyCredentialError interprets an error returned by ModifyUserEmailAccount
+func classifyCredentialError(err error) CredentialOutcome {
+	statusCode := ErrorStatusCode(err)
+	switch {
+	case statusCode == 429 || statusCode >= 500 || statusCode == 0 || statusCode == UnknownStatusCode:
+		return CredentialTemporaryFailure
+	case statusCode == 401 || statusCode == 403:
+		if outcome, _ := classifyFeedbackText(ErrorPayload(err)); outcome == CredentialInvalid {
+			return outcome
+		}
+	}
+	return CredentialRejected
+}
+
+// CredentialRotationOptions configure RotateCredentials.
+// The zero value checks the account status once after the change, and does not roll back.
+type CredentialRotationOptions struct {
+	// Previous, if set, are restored if the new credentials are CredentialInvalid or CredentialRejected.
+	// Other outcomes (ex: CredentialConnectionFailed) mean the new credentials were never tested,
+	// so the account is left with them rather than going back to credentials that may have been revoked.
+	Previous *Credentials
+
+	// VerifyAttempts is how many times the account status is read after the change, waiting VerifyDelay
+	// before each, until it is no longer failing. CIO updates the status asynchronously, so a failing
+	// status straight after a change may be the status from before it. Defaults to 1.
+	VerifyAttempts int
+	VerifyDelay    time.Duration
+}
+
+// CredentialRotationResult is the outcome of RotateCredentials
+type CredentialRotationResult struct {
+	UserID string
+	Label  string
+
+	// Outcome is how the new credentials turned out
+	Outcome CredentialOutcome
+
+	// Response is the response to the credential change
+	Response ModifyEmailAccountResponse
+
+	// Status is the last account status read while verifying (empty if it was never read)
+	Status AccountStatus
+
+	// RolledBack is true if the previous credentials were restored, and RollbackOutcome is how they turned out
+	RolledBack      bool
+	RollbackOutcome CredentialOutcome
+}
+
+// CredentialRotationError is returned by RotateCredentials when the account did not come back OK
+type CredentialRotationError struct {
+	Result CredentialRotationResult
+
+	// Err is the error received while changing the credentials, if any
+	Err error
+
+	// RollbackErr is the error received while restoring the previous credentials, if any
+	RollbackErr error
+}
+
+// Error returns a description of the failed rotation
+func (e CredentialRotationError) Error() string {
+	msg := fmt.Sprintf("CIO: Credential rotation of %s/%s failed: %s", e.Result.UserID, e.Result.Label, e.Result.Outcome)
+	if len(e.Result.Response.FeedbackCode) > 0 {
+		msg += " (feedback code: " + e.Result.Response.FeedbackCode + ")"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	switch {
+	case e.RollbackErr != nil:
+		msg += "; rollback failed: " + e.RollbackErr.Error()
+	case e.Result.RolledBack:
+		msg += "; rolled back to previous credentials: " + string(e.Result.RollbackOutcome)
+	}
+	return msg
+}
+
+// RotateCredentials sets new credentials on an email account, forces a status check, and verifies that the
+// account comes back OK. If it does not, a CredentialRotationError describing the outcome is returned alongside
+// the result, after restoring the previous credentials (when given in the options) if the new ones were refused.
+func RotateCredentials(client Interface, userID string, label string, credentials Credentials, options CredentialRotationOptions) (CredentialRotationResult, error) {
+
+	result := CredentialRotationResult{UserID: userID, Label: label}
+
+	if credentials.IsEmpty() {
+		return result, errors.New("CIO: Credential rotation requires a Password or ProviderRefreshToken")
+	}
+
+	outcome, response, err := changeCredentials(client, userID, label, credentials, options, &result.Status)
+	result.Outcome = outcome
+	result.Response = response
+	if outcome == CredentialOK {
+		return result, nil
+	}
+
+	rotationErr := CredentialRotationError{Err: err}
+	refused := outcome == CredentialInvalid || outcome == CredentialRejected
+	if refused && options.Previous != nil && !options.Previous.IsEmpty() {
+		var previousStatus AccountStatus
+		result.RollbackOutcome, _, rotationErr.RollbackErr = changeCredentials(client, userID, label, *options.Previous, options, &previousStatus)
+		result.RolledBack = rotationErr.RollbackErr == nil
+	}
+	rotationErr.Result = result
+
+	return result, rotationErr
+}
+
+// changeCredentials sets the credentials and verifies the account status, returning the outcome
+func changeCredentials(client Interface, userID string, label string, credentials Credentials, options CredentialRotationOptions, status *AccountStatus) (CredentialOutcome, ModifyEmailAccountResponse, error) {
+
+	response, err := client.ModifyUserEmailAccount(userID, label, credentials.params())
+	if err != nil {
+		return classifyCredentialError(err), response, err
+	}
+
+	// The feedback from the forced status check is definitive when it reports a failure
+	if outcome := ClassifyCredentialFeedback(response); outcome != CredentialOK && outcome != CredentialUnknown {
+		return outcome, response, nil
+	}
+	if !response.Success {
+		return CredentialRejected, response, errors.New("CIO returned 200 but with Success=false")
+	}
+
+	attempts := options.VerifyAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	outcome := CredentialUnknown
+	for i := 0; i < attempts; i++ {
+		if options.VerifyDelay > 0 {
+			time.Sleep(options.VerifyDelay)
+		}
+
+		var account GetUsersEmailAccountsResponse
+		account, err = client.GetUserEmailAccount(userID, label)
+		if err != nil {
+			return CredentialUnknown, response, errors.Wrap(err, "Unable to verify email account status")
+		}
+
+		*status = account.Status
+		outcome = ClassifyAccountStatus(account.Status)
+		if outcome == CredentialOK || outcome == CredentialDisabled {
+			break
+		}
+	}
+
+	return outcome, response, nil
+}
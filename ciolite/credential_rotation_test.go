@@ -0,0 +1,167 @@
+package ciolite
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestSimulatedRotateCredentials tests RotateCredentials with a simulated server
+func TestSimulatedRotateCredentials(t *testing.T) {
+	t.Parallel()
+
+	cioLite, _, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	// The account is OK with the "good" and "old" refresh tokens, "bad" is rejected, "unknown" is refused by CIO,
+	// the mail server of "unreachable" cannot be reached, and "boom" breaks CIO
+	var (
+		mu       sync.Mutex
+		status   = "OK"
+		tokens   []string
+		modified int
+	)
+	mux.HandleFunc("/lite/users/u1/email_accounts/0", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == "GET" {
+			_, err := io.WriteString(w, `{"label": "0", "status": "`+status+`"}`)
+			Must(err)
+			return
+		}
+
+		if r.FormValue("force_status_check") != "1" {
+			t.Errorf("Expected a forced status check: %s", r.Form.Encode())
+		}
+		token := r.FormValue("provider_refresh_token")
+		tokens = append(tokens, token)
+		modified++
+
+		switch token {
+		case "good", "old":
+			status = "OK"
+			_, err := io.WriteString(w, `{"success": true, "connection_log": "A1 AUTHENTICATE XOAUTH2 dXNlcj0=\r\nA1 OK Success"}`)
+			Must(err)
+		case "bad":
+			status = "INVALID_CREDENTIALS"
+			_, err := io.WriteString(w, `{"success": false, "feedback_code": "invalid_credentials", "connection_log": "AUTHENTICATE failed"}`)
+			Must(err)
+		case "unreachable":
+			status = "CONNECTION_IMPOSSIBLE"
+			_, err := io.WriteString(w, `{"success": false, "feedback_code": "connection_impossible"}`)
+			Must(err)
+		case "unknown":
+			w.WriteHeader(400)
+			_, err := io.WriteString(w, `{"type": "error", "value": "Unknown provider_consumer_key"}`)
+			Must(err)
+		case "stale":
+			// Accepted, but the status check fails afterwards
+			status = "INVALID_CREDENTIALS"
+			_, err := io.WriteString(w, `{"success": true}`)
+			Must(err)
+		default:
+			w.WriteHeader(500)
+			_, err := io.WriteString(w, `{"type": "error", "value": "boom"}`)
+			Must(err)
+		}
+	})
+
+	previous := &Credentials{ProviderRefreshToken: "old", ProviderConsumerKey: "key"}
+
+	// Good credentials
+	result, err := RotateCredentials(cioLite, "u1", "0", Credentials{ProviderRefreshToken: "good", ProviderConsumerKey: "key"}, CredentialRotationOptions{Previous: previous})
+	if err != nil || result.Outcome != CredentialOK || result.Status != "OK" || result.RolledBack {
+		t.Errorf("Unexpected good rotation: %+v %v", result, err)
+	}
+
+	// Bad credentials are rolled back
+	result, err = RotateCredentials(cioLite, "u1", "0", Credentials{ProviderRefreshToken: "bad"}, CredentialRotationOptions{Previous: previous})
+	rotationErr, ok := err.(CredentialRotationError)
+	if !ok || result.Outcome != CredentialInvalid || !result.RolledBack || result.RollbackOutcome != CredentialOK || rotationErr.Result.Outcome != CredentialInvalid {
+		t.Errorf("Unexpected bad rotation: %+v %v", result, err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "invalid_credentials") || !strings.Contains(err.Error(), "rolled back") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+
+	// Credentials failing the status check are found while verifying
+	result, err = RotateCredentials(cioLite, "u1", "0", Credentials{ProviderRefreshToken: "stale"}, CredentialRotationOptions{VerifyAttempts: 2})
+	if err == nil || result.Outcome != CredentialInvalid || result.Status != "INVALID_CREDENTIALS" || result.RolledBack {
+		t.Errorf("Unexpected stale rotation: %+v %v", result, err)
+	}
+
+	// Credentials refused by CIO are rolled back, and the rollback error is reported
+	result, err = RotateCredentials(cioLite, "u1", "0", Credentials{ProviderRefreshToken: "unknown"}, CredentialRotationOptions{Previous: &Credentials{ProviderRefreshToken: "boom"}})
+	rotationErr, ok = err.(CredentialRotationError)
+	if !ok || result.Outcome != CredentialRejected || result.RolledBack || rotationErr.Err == nil || rotationErr.RollbackErr == nil {
+		t.Errorf("Unexpected refused rotation: %+v %v", result, err)
+	}
+
+	// Untested credentials are not rolled back: the mail server could not be reached, or CIO failed
+	for _, token := range []string{"unreachable", "boom"} {
+		result, err = RotateCredentials(cioLite, "u1", "0", Credentials{ProviderRefreshToken: token}, CredentialRotationOptions{Previous: previous})
+		rotationErr, ok = err.(CredentialRotationError)
+		if !ok || result.Outcome == CredentialOK || result.Outcome == CredentialInvalid || result.RolledBack || rotationErr.RollbackErr != nil {
+			t.Errorf("Unexpected untested rotation: %+v %v", result, err)
+		}
+	}
+	if result.Outcome != CredentialTemporaryFailure {
+		t.Errorf("Expected server errors to be temporary: %+v", result)
+	}
+
+	// Empty credentials are refused without calling CIO
+	mu.Lock()
+	before := modified
+	mu.Unlock()
+	if _, err = RotateCredentials(cioLite, "u1", "0", Credentials{}, CredentialRotationOptions{}); err == nil {
+		t.Error("Expected an error for empty credentials")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if modified != before {
+		t.Error("Expected no request for empty credentials")
+	}
+	expectedTokens := []string{"good", "bad", "old", "stale", "unknown", "boom", "unreachable", "boom"}
+	if strings.Join(tokens, ",") != strings.Join(expectedTokens, ",") {
+		t.Errorf("Expected tokens %v, got %v", expectedTokens, tokens)
+	}
+}
+
+// TestClassifyCredentialFeedback tests ClassifyCredentialFeedback
+func TestClassifyCredentialFeedback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		success  bool
+		code     string
+		log      string
+		expected CredentialOutcome
+	}{
+		{true, "", "", CredentialOK},
+		{false, "", "", CredentialUnknown},
+		{false, "invalid_credentials", "", CredentialInvalid},
+		{false, "", "* NO [AUTHENTICATIONFAILED] Invalid credentials (Failure)", CredentialInvalid},
+		{false, "", "Token has been expired or revoked: invalid_grant", CredentialInvalid},
+		{false, "", "connect to imap.example.com:993 timed out", CredentialConnectionFailed},
+		{false, "connection_impossible", "", CredentialConnectionFailed},
+		{false, "temp_disabled", "", CredentialTemporaryFailure},
+		{false, "", "* NO [UNAVAILABLE] Temporary authentication failure", CredentialTemporaryFailure},
+		{false, "something_new", "", CredentialUnknown},
+		{false, "something_new", "Too many simultaneous connections", CredentialTemporaryFailure},
+
+		// The logs of successful logins are not failures
+		{true, "", "* OK [CAPABILITY IMAP4rev1 AUTH=XOAUTH2] ready\r\nA1 AUTHENTICATE XOAUTH2 dXNlcj0=\r\nA1 OK Success", CredentialOK},
+		{true, "", "* OK imap.example.com ready (SSL)\r\nA1 LOGIN user password\r\nA1 OK LOGIN completed", CredentialOK},
+		{true, "something_new", "A1 LOGIN user password\r\nA1 OK LOGIN completed", CredentialUnknown},
+	}
+
+	for _, test := range tests {
+		response := ModifyEmailAccountResponse{Success: test.success, FeedbackCode: test.code, ConnectionLog: test.log}
+		if actual := ClassifyCredentialFeedback(response); actual != test.expected {
+			t.Errorf("Expected %q for %v/%q/%q, got %q", test.expected, test.success, test.code, test.log, actual)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package ciolite
+
+// Local discovery of IMAP settings, for when CIO's discovery does not know a domain:
+// Mozilla autoconfig, Microsoft autodiscover, SRV records (RFC 6186), and MX based heuristics
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/publicsuffix"
+)
+
+// LocalDiscovery discovers IMAP settings without CIO. Every lookup is injectable, so that tests can stand in for DNS and HTTP.
+type LocalDiscovery struct {
+	// HTTPClient fetches autoconfig and autodiscover documents
+	HTTPClient *http.Client
+
+	// LookupSRV and LookupMX resolve DNS records, with the signatures of net.LookupSRV and net.LookupMX
+	LookupSRV func(service string, proto string, name string) (string, []*net.SRV, error)
+	LookupMX  func(name string) ([]*net.MX, error)
+
+	// ISPDBURL is the base url of the Thunderbird ISP database, which autoconfig falls back to (empty to skip it)
+	ISPDBURL string
+}
+
+// NewLocalDiscovery returns a LocalDiscovery using the system resolver, and an http client with a short timeout
+func NewLocalDiscovery() *LocalDiscovery {
+	return &LocalDiscovery{
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		LookupSRV:  net.LookupSRV,
+		LookupMX:   net.LookupMX,
+		ISPDBURL:   "https://autoconfig.thunderbird.net/v1.1/",
+	}
+}
+
+// GetDiscoveryWithFallback calls GetDiscovery, and if CIO does not find the email address (or fails),
+// discovers its settings with the LocalDiscovery instead. CIO's response (and error) is returned if both miss.
+func GetDiscoveryWithFallback(client Interface, local *LocalDiscovery, queryValues GetDiscoveryParams) (GetDiscoveryResponse, error) {
+	response, err := client.GetDiscovery(queryValues)
+	if err == nil && response.Found {
+		return response, nil
+	}
+
+	localResponse, localErr := local.Discover(queryValues.Email)
+	if localErr == nil && localResponse.Found {
+		return localResponse, nil
+	}
+	return response, err
+}
+
+// Discover tries, in order: Mozilla autoconfig (including the ISP database), Microsoft autodiscover,
+// SRV records, and the MX records of the domain. The first source that finds IMAP settings wins.
+// If none does, the response has Found set to false; an error is only returned for an invalid email address.
+func (d *LocalDiscovery) Discover(email string) (GetDiscoveryResponse, error) {
+
+	response := GetDiscoveryResponse{Email: email}
+
+	at := strings.LastIndex(email, "@")
+	if at < 1 || at == len(email)-1 {
+		return response, errors.Errorf("Invalid email address: %s", email)
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	sources := []func(email string, domain string) (GetDiscoveryIMAPResponse, AccountType, bool){
+		d.autoconfig,
+		d.autodiscover,
+		d.srv,
+		d.mx,
+	}
+	for _, source := range sources {
+		if imap, accountType, ok := source(email, domain); ok {
+			response.Found = true
+			response.Type = accountType
+			response.IMAP = imap
+			return response, nil
+		}
+	}
+	return response, nil
+}
+
+// autoconfigDocument is the part of a Mozilla autoconfig document that describes IMAP servers
+type autoconfigDocument struct {
+	Servers []struct {
+		Type           string   `xml:"type,attr"`
+		Hostname       string   `xml:"hostname"`
+		Port           int      `xml:"port"`
+		SocketType     string   `xml:"socketType"`
+		Username       string   `xml:"username"`
+		Authentication []string `xml:"authentication"`
+	} `xml:"emailProvider>incomingServer"`
+}
+
+// autoconfig fetches the domain's autoconfig document, trying the ISP database last
+func (d *LocalDiscovery) autoconfig(email string, domain string) (GetDiscoveryIMAPResponse, AccountType, bool) {
+	urls := []string{
+		"https://autoconfig." + domain + "/mail/config-v1.1.xml?emailaddress=" + url.QueryEscape(email),
+		"https://" + domain + "/.well-known/autoconfig/mail/config-v1.1.xml",
+	}
+	if len(d.ISPDBURL) > 0 {
+		urls = append(urls, d.ISPDBURL+domain)
+	}
+
+	for _, u := range urls {
+		body, ok := d.fetch("GET", u, nil)
+		if !ok {
+			continue
+		}
+		if imap, ok := parseAutoconfig(body, email); ok {
+			return imap, AccountTypeIMAP, true
+		}
+	}
+	return GetDiscoveryIMAPResponse{}, "", false
+}
+
+// parseAutoconfig returns the first IMAP server in the autoconfig document, preferring SSL over STARTTLS over plain
+func parseAutoconfig(body []byte, email string) (GetDiscoveryIMAPResponse, bool) {
+	var doc autoconfigDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return GetDiscoveryIMAPResponse{}, false
+	}
+
+	var (
+		best     GetDiscoveryIMAPResponse
+		bestRank = -1
+	)
+	for _, server := range doc.Servers {
+		if !strings.EqualFold(server.Type, "imap") || len(server.Hostname) == 0 {
+			continue
+		}
+
+		rank := 0
+		switch strings.ToUpper(server.SocketType) {
+		case "SSL":
+			rank = 2
+		case "STARTTLS":
+			rank = 1
+		}
+		if rank <= bestRank {
+			continue
+		}
+
+		imap := GetDiscoveryIMAPResponse{
+			Server:   expandAutoconfig(server.Hostname, email),
+			Username: expandAutoconfig(server.Username, email),
+			UseSSL:   rank == 2,
+			Port:     server.Port,
+		}
+		for _, auth := range server.Authentication {
+			if strings.EqualFold(auth, "OAuth2") {
+				imap.OAuth = true
+			}
+		}
+		if imap.Port == 0 {
+			imap.Port = defaultIMAPPort(imap.UseSSL)
+		}
+		best, bestRank = imap, rank
+	}
+	return best, bestRank >= 0
+}
+
+// expandAutoconfig replaces the autoconfig placeholders with parts of the email address
+func expandAutoconfig(s string, email string) string {
+	at := strings.LastIndex(email, "@")
+	return strings.NewReplacer(
+		"%EMAILADDRESS%", email,
+		"%EMAILLOCALPART%", email[:at],
+		"%EMAILDOMAIN%", email[at+1:],
+	).Replace(s)
+}
+
+// autodiscoverRequest is the request body of Microsoft's POX autodiscover
+const autodiscoverRequest = `<?xml version="1.0" encoding="utf-8"?>
+<Autodiscover xmlns="http://schemas.microsoft.com/exchange/autodiscover/outlook/requestschema/2006">
+  <Request>
+    <EMailAddress>%s</EMailAddress>
+    <AcceptableResponseSchema>http://schemas.microsoft.com/exchange/autodiscover/outlook/responseschema/2006a</AcceptableResponseSchema>
+  </Request>
+</Autodiscover>`
+
+// autodiscoverDocument is the part of an autodiscover response that describes the account's protocols
+type autodiscoverDocument struct {
+	Protocols []struct {
+		Type      string `xml:"Type"`
+		Server    string `xml:"Server"`
+		Port      int    `xml:"Port"`
+		SSL       string `xml:"SSL"`
+		LoginName string `xml:"LoginName"`
+	} `xml:"Response>Account>Protocol"`
+}
+
+// autodiscover posts an autodiscover request to the domain's autodiscover endpoints
+func (d *LocalDiscovery) autodiscover(email string, domain string) (GetDiscoveryIMAPResponse, AccountType, bool) {
+	urls := []string{
+		"https://autodiscover." + domain + "/autodiscover/autodiscover.xml",
+		"https://" + domain + "/autodiscover/autodiscover.xml",
+	}
+
+	var request bytes.Buffer
+	if err := xml.EscapeText(&request, []byte(email)); err != nil {
+		return GetDiscoveryIMAPResponse{}, "", false
+	}
+	body := strings.Replace(autodiscoverRequest, "%s", request.String(), 1)
+
+	for _, u := range urls {
+		response, ok := d.fetch("POST", u, strings.NewReader(body))
+		if !ok {
+			continue
+		}
+
+		var doc autodiscoverDocument
+		if err := xml.Unmarshal(response, &doc); err != nil {
+			continue
+		}
+		for _, protocol := range doc.Protocols {
+			if !strings.EqualFold(protocol.Type, "IMAP") || len(protocol.Server) == 0 {
+				continue
+			}
+			imap := GetDiscoveryIMAPResponse{
+				Server:   protocol.Server,
+				Username: protocol.LoginName,
+				UseSSL:   !strings.EqualFold(protocol.SSL, "off"),
+				Port:     protocol.Port,
+			}
+			if len(imap.Username) == 0 {
+				imap.Username = email
+			}
+			if imap.Port == 0 {
+				imap.Port = defaultIMAPPort(imap.UseSSL)
+			}
+			return imap, AccountTypeIMAP, true
+		}
+	}
+	return GetDiscoveryIMAPResponse{}, "", false
+}
+
+// srv looks up the RFC 6186 SRV records, preferring imaps over imap
+func (d *LocalDiscovery) srv(email string, domain string) (GetDiscoveryIMAPResponse, AccountType, bool) {
+	if d.LookupSRV == nil {
+		return GetDiscoveryIMAPResponse{}, "", false
+	}
+
+	for _, service := range []string{"imaps", "imap"} {
+		_, records, err := d.LookupSRV(service, "tcp", domain)
+		if err != nil {
+			continue
+		}
+		// Records are sorted by priority and weight; a target of "." means the service is not available
+		for _, record := range records {
+			target := strings.TrimSuffix(record.Target, ".")
+			if len(target) == 0 {
+				break
+			}
+			return GetDiscoveryIMAPResponse{
+				Server:   target,
+				Username: email,
+				UseSSL:   service == "imaps",
+				Port:     int(record.Port),
+			}, AccountTypeIMAP, true
+		}
+	}
+	return GetDiscoveryIMAPResponse{}, "", false
+}
+
+// mxProviders maps the domain suffixes of well known mail hosts to their IMAP settings
+var mxProviders = []struct {
+	suffix      string
+	accountType AccountType
+	imap        GetDiscoveryIMAPResponse
+}{
+	{"google.com", AccountTypeGoogleApps, GetDiscoveryIMAPResponse{Server: "imap.gmail.com", UseSSL: true, OAuth: true, Port: 993}},
+	{"googlemail.com", AccountTypeGoogleApps, GetDiscoveryIMAPResponse{Server: "imap.gmail.com", UseSSL: true, OAuth: true, Port: 993}},
+	{"outlook.com", AccountTypeOffice365, GetDiscoveryIMAPResponse{Server: "outlook.office365.com", UseSSL: true, OAuth: true, Port: 993}},
+	{"yahoodns.net", AccountTypeIMAP, GetDiscoveryIMAPResponse{Server: "imap.mail.yahoo.com", UseSSL: true, Port: 993}},
+	{"zoho.com", AccountTypeIMAP, GetDiscoveryIMAPResponse{Server: "imap.zoho.com", UseSSL: true, Port: 993}},
+	{"messagingengine.com", AccountTypeIMAP, GetDiscoveryIMAPResponse{Server: "imap.fastmail.com", UseSSL: true, Port: 993}},
+	{"icloud.com", AccountTypeIMAP, GetDiscoveryIMAPResponse{Server: "imap.mail.me.com", UseSSL: true, Port: 993}},
+}
+
+// mx looks up the domain's mail exchangers, and recognizes well known hosts, or else
+// looks up the mail exchanger's own domain in the ISP database (as Thunderbird does)
+func (d *LocalDiscovery) mx(email string, domain string) (GetDiscoveryIMAPResponse, AccountType, bool) {
+	if d.LookupMX == nil {
+		return GetDiscoveryIMAPResponse{}, "", false
+	}
+	records, err := d.LookupMX(domain)
+	if err != nil {
+		return GetDiscoveryIMAPResponse{}, "", false
+	}
+
+	for _, record := range records {
+		host := strings.ToLower(strings.TrimSuffix(record.Host, "."))
+		for _, provider := range mxProviders {
+			if host == provider.suffix || strings.HasSuffix(host, "."+provider.suffix) {
+				imap := provider.imap
+				imap.Username = email
+				return imap, provider.accountType, true
+			}
+		}
+	}
+
+	if len(d.ISPDBURL) == 0 {
+		return GetDiscoveryIMAPResponse{}, "", false
+	}
+	for _, record := range records {
+		mxDomain := baseDomain(strings.ToLower(strings.TrimSuffix(record.Host, ".")))
+		if len(mxDomain) == 0 || mxDomain == domain {
+			continue
+		}
+		if body, ok := d.fetch("GET", d.ISPDBURL+mxDomain, nil); ok {
+			if imap, ok := parseAutoconfig(body, email); ok {
+				return imap, AccountTypeIMAP, true
+			}
+		}
+	}
+	return GetDiscoveryIMAPResponse{}, "", false
+}
+
+// baseDomain returns the registered domain of the host (ex: mx1.example.co.uk becomes example.co.uk), or "" if there is none
+func baseDomain(host string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
+
+// fetch makes an http request, and returns the body of a successful response
+func (d *LocalDiscovery) fetch(method string, u string, body io.Reader) ([]byte, bool) {
+	if d.HTTPClient == nil {
+		return nil, false
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, false
+	}
+	if method == "POST" {
+		req.Header.Set("Content-Type", "text/xml")
+	}
+
+	res, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	// Documents are small, so guard against being sent something that is not
+	content, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// defaultIMAPPort returns the standard IMAP port
+func defaultIMAPPort(ssl bool) int {
+	if ssl {
+		return 993
+	}
+	return 143
+}
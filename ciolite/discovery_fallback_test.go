@@ -0,0 +1,181 @@
+package ciolite
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// roundTripFunc is an http.RoundTripper standing in for the internet
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls the func
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestLocalDiscovery returns a LocalDiscovery that serves documents by url, and dns records by domain
+func newTestLocalDiscovery(documents map[string]string, srv map[string][]*net.SRV, mx map[string][]*net.MX) *LocalDiscovery {
+	return &LocalDiscovery{
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			key := req.Method + " " + req.URL.String()
+			document, ok := documents[key]
+			if !ok {
+				return nil, errors.New("no such host")
+			}
+			if req.Method == "POST" {
+				body, err := ioutil.ReadAll(req.Body)
+				if err != nil || !strings.Contains(string(body), "<EMailAddress>") {
+					return &http.Response{StatusCode: 400, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				}
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(document))}, nil
+		})},
+		LookupSRV: func(service string, proto string, name string) (string, []*net.SRV, error) {
+			records, ok := srv["_"+service+"._"+proto+"."+name]
+			if !ok {
+				return "", nil, errors.New("no such host")
+			}
+			return "", records, nil
+		},
+		LookupMX: func(name string) ([]*net.MX, error) {
+			records, ok := mx[name]
+			if !ok {
+				return nil, errors.New("no such host")
+			}
+			return records, nil
+		},
+		ISPDBURL: "https://ispdb.test/",
+	}
+}
+
+// TestLocalDiscovery tests LocalDiscovery.Discover against stand-in dns and http
+func TestLocalDiscovery(t *testing.T) {
+	t.Parallel()
+
+	autoconfig := `<?xml version="1.0"?>
+<clientConfig version="1.1">
+  <emailProvider id="example">
+    <incomingServer type="pop3"><hostname>pop.%EMAILDOMAIN%</hostname><port>995</port><socketType>SSL</socketType></incomingServer>
+    <incomingServer type="imap"><hostname>imap.%EMAILDOMAIN%</hostname><port>143</port><socketType>STARTTLS</socketType><username>%EMAILLOCALPART%</username></incomingServer>
+    <incomingServer type="imap"><hostname>secure.%EMAILDOMAIN%</hostname><port>993</port><socketType>SSL</socketType><username>%EMAILADDRESS%</username><authentication>OAuth2</authentication></incomingServer>
+  </emailProvider>
+</clientConfig>`
+
+	autodiscover := `<?xml version="1.0" encoding="utf-8"?>
+<Autodiscover xmlns="http://schemas.microsoft.com/exchange/autodiscover/responseschema/2006">
+  <Response xmlns="http://schemas.microsoft.com/exchange/autodiscover/outlook/responseschema/2006a">
+    <Account>
+      <Protocol><Type>EXCH</Type><Server>exch.corp.test</Server></Protocol>
+      <Protocol><Type>IMAP</Type><Server>mail.corp.test</Server><Port>993</Port><SSL>on</SSL><LoginName>jane</LoginName></Protocol>
+    </Account>
+  </Response>
+</Autodiscover>`
+
+	local := newTestLocalDiscovery(
+		map[string]string{
+			"GET https://autoconfig.example.com/mail/config-v1.1.xml?emailaddress=jane%40example.com": autoconfig,
+			"GET https://ispdb.test/isp.test":                      autoconfig,
+			"POST https://corp.test/autodiscover/autodiscover.xml": autodiscover,
+			"GET https://ispdb.test/hoster.test":                   autoconfig,
+			"GET https://ispdb.test/hoster.co.uk":                  autoconfig,
+		},
+		map[string][]*net.SRV{
+			"_imaps._tcp.srv.test": {{Target: ".", Port: 0}},
+			"_imap._tcp.srv.test":  {{Target: "imap.srv.test.", Port: 1143}},
+		},
+		map[string][]*net.MX{
+			"apps.test":   {{Host: "aspmx.l.google.com.", Pref: 1}},
+			"o365.test":   {{Host: "o365-test.mail.protection.outlook.com.", Pref: 0}},
+			"hosted.test": {{Host: "mx1.hoster.test.", Pref: 10}},
+			"hosted.uk":   {{Host: "mx1.eu.hoster.co.uk.", Pref: 10}},
+		},
+	)
+
+	tests := []struct {
+		email    string
+		expected GetDiscoveryResponse
+	}{
+		// autoconfig on the domain, preferring SSL
+		{"jane@example.com", GetDiscoveryResponse{Email: "jane@example.com", Type: "imap", Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "secure.example.com", Username: "jane@example.com", UseSSL: true, OAuth: true, Port: 993}}},
+		// the ISP database
+		{"jane@isp.test", GetDiscoveryResponse{Email: "jane@isp.test", Type: "imap", Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "secure.isp.test", Username: "jane@isp.test", UseSSL: true, OAuth: true, Port: 993}}},
+		// autodiscover, on the domain itself
+		{"jane@corp.test", GetDiscoveryResponse{Email: "jane@corp.test", Type: "imap", Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "mail.corp.test", Username: "jane", UseSSL: true, Port: 993}}},
+		// SRV, skipping the unavailable imaps service
+		{"jane@srv.test", GetDiscoveryResponse{Email: "jane@srv.test", Type: "imap", Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "imap.srv.test", Username: "jane@srv.test", Port: 1143}}},
+		// MX of a well known provider
+		{"jane@apps.test", GetDiscoveryResponse{Email: "jane@apps.test", Type: AccountTypeGoogleApps, Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "imap.gmail.com", Username: "jane@apps.test", UseSSL: true, OAuth: true, Port: 993}}},
+		{"jane@o365.test", GetDiscoveryResponse{Email: "jane@o365.test", Type: AccountTypeOffice365, Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "outlook.office365.com", Username: "jane@o365.test", UseSSL: true, OAuth: true, Port: 993}}},
+		// MX's domain in the ISP database
+		{"jane@hosted.test", GetDiscoveryResponse{Email: "jane@hosted.test", Type: "imap", Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "secure.hosted.test", Username: "jane@hosted.test", UseSSL: true, OAuth: true, Port: 993}}},
+		// MX's registered domain in the ISP database, not its public suffix
+		{"jane@hosted.uk", GetDiscoveryResponse{Email: "jane@hosted.uk", Type: "imap", Found: true,
+			IMAP: GetDiscoveryIMAPResponse{Server: "secure.hosted.uk", Username: "jane@hosted.uk", UseSSL: true, OAuth: true, Port: 993}}},
+		// nothing
+		{"jane@nowhere.test", GetDiscoveryResponse{Email: "jane@nowhere.test"}},
+	}
+
+	for _, test := range tests {
+		actual, err := local.Discover(test.email)
+		if err != nil || !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Expected %+v for %s, got %+v (%v)", test.expected, test.email, actual, err)
+		}
+	}
+
+	if _, err := local.Discover("not an email"); err == nil {
+		t.Error("Expected an error for an invalid email address")
+	}
+}
+
+// TestSimulatedGetDiscoveryWithFallback tests GetDiscoveryWithFallback with a simulated server
+func TestSimulatedGetDiscoveryWithFallback(t *testing.T) {
+	t.Parallel()
+
+	cioLite, _, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/lite/discovery", func(w http.ResponseWriter, r *http.Request) {
+		email := r.FormValue("email")
+		if email == "jane@gmail.com" {
+			_, err := io.WriteString(w, `{"email": "jane@gmail.com", "type": "gmail", "found": true, "imap": {"server": "imap.gmail.com", "port": 993}}`)
+			Must(err)
+			return
+		}
+		_, err := io.WriteString(w, `{"email": "`+email+`", "found": false}`)
+		Must(err)
+	})
+
+	local := newTestLocalDiscovery(nil, map[string][]*net.SRV{"_imaps._tcp.srv.test": {{Target: "imap.srv.test", Port: 993}}}, nil)
+
+	// Found by CIO
+	response, err := GetDiscoveryWithFallback(cioLite, local, GetDiscoveryParams{Email: "jane@gmail.com"})
+	if err != nil || response.Type != "gmail" || response.IMAP.Server != "imap.gmail.com" {
+		t.Errorf("Unexpected response from CIO: %+v %v", response, err)
+	}
+
+	// Found locally
+	response, err = GetDiscoveryWithFallback(cioLite, local, GetDiscoveryParams{Email: "jane@srv.test"})
+	if err != nil || !response.Found || response.IMAP.Server != "imap.srv.test" || !response.IMAP.UseSSL {
+		t.Errorf("Unexpected local response: %+v %v", response, err)
+	}
+
+	// Found by neither
+	response, err = GetDiscoveryWithFallback(cioLite, local, GetDiscoveryParams{Email: "jane@nowhere.test"})
+	if err != nil || response.Found || response.Email != "jane@nowhere.test" {
+		t.Errorf("Unexpected missing response: %+v %v", response, err)
+	}
+}
@@ -0,0 +1,555 @@
+// Package export dumps the messages of an email account to mboxrd files (one per folder),
+// or to a directory (optionally zipped) of .eml files, along with a manifest of every exported message.
+//
+// Exports are resumable: the manifest entries of every page of messages are appended to ManifestEntriesFile,
+// and a resumed export skips the messages already in it.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/contextio/contextio-go/ciolite"
+	"github.com/pkg/errors"
+)
+
+// Format is the format messages are exported in
+type Format string
+
+// Format values
+const (
+	// FormatMbox writes one mboxrd file per folder
+	FormatMbox Format = "mbox"
+	// FormatEML writes one .eml file per message, in a directory per folder
+	FormatEML Format = "eml"
+)
+
+// ManifestFile is the name of the manifest within the export directory, which has every field except its Messages
+const ManifestFile = "manifest.json"
+
+// ManifestEntriesFile is the name of the manifest's Messages within the export directory, one json ManifestEntry per line
+const ManifestEntriesFile = "manifest.jsonl"
+
+// Options configure Export. Format and Dir are required.
+type Options struct {
+	Format Format
+
+	// Dir is the directory the export is written to
+	Dir string
+
+	// ZipPath, if set with FormatEML, is where the finished export directory is zipped to
+	ZipPath string
+
+	// Folders, if set, limits the export to these folders
+	Folders []string
+
+	// Resume continues an earlier export in Dir, instead of refusing to overwrite it
+	Resume bool
+
+	// Concurrency is how many raw messages are fetched at the same time, defaults to 4
+	Concurrency int
+
+	// PageSize is how many messages are listed at a time, defaults to 100
+	PageSize int
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Manifest lists every exported message
+type Manifest struct {
+	UserID string `json:"user_id"`
+	Label  string `json:"label"`
+	Format Format `json:"format"`
+
+	StartedAt   int64 `json:"started_at"`
+	CompletedAt int64 `json:"completed_at,omitempty"`
+
+	// Messages are kept in ManifestEntriesFile, which is appended to as messages are exported
+	Messages []ManifestEntry `json:"-"`
+}
+
+// ManifestEntry is a single exported message
+type ManifestEntry struct {
+	Folder    string `json:"folder"`
+	MessageID string `json:"message_id"`
+
+	// File is relative to the export directory
+	File string `json:"file"`
+
+	// Offset and Length are where the message is within an mbox file (including its From line)
+	Offset int64 `json:"offset,omitempty"`
+	Length int64 `json:"length,omitempty"`
+
+	// Size and SHA256 are of the raw message, as it was fetched
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// MessageError is a message that could not be exported
+type MessageError struct {
+	Folder    string
+	MessageID string
+	Err       error
+}
+
+// Report is the outcome of Export
+type Report struct {
+	Manifest Manifest
+
+	// Exported is how many messages were exported by this run,
+	// and Skipped how many were already in the manifest of a resumed export
+	Exported int
+	Skipped  int
+
+	// Failed are the messages that could not be exported, which a resumed export will retry
+	Failed []MessageError
+}
+
+// Export exports the messages of the email account.
+// Messages that cannot be fetched are reported in Report.Failed, rather than stopping the export;
+// an error is only returned if the export itself cannot continue.
+func Export(client ciolite.Interface, userID string, label string, options Options) (Report, error) {
+
+	if options.Format != FormatMbox && options.Format != FormatEML {
+		return Report{}, errors.Errorf("Unknown export format: %s", options.Format)
+	}
+	if len(options.Dir) == 0 {
+		return Report{}, errors.New("Export directory is required")
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+	if options.PageSize <= 0 {
+		options.PageSize = 100
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	exporter := &exporter{client: client, userID: userID, label: label, options: options, done: map[string]bool{}}
+	defer exporter.close()
+	if err := exporter.open(); err != nil {
+		return exporter.report, err
+	}
+
+	folders, err := client.GetUserEmailAccountsFolders(userID, label, ciolite.GetUserEmailAccountsFoldersParams{})
+	if err != nil {
+		return exporter.report, errors.Wrap(err, "Unable to list folders")
+	}
+
+	for _, folder := range folders {
+		if !includes(options.Folders, folder.Name) {
+			continue
+		}
+		if err = exporter.exportFolder(folder); err != nil {
+			return exporter.report, errors.Wrapf(err, "Unable to export folder %s", folder.Name)
+		}
+	}
+
+	err = exporter.entries.Close()
+	exporter.entries = nil
+	if err != nil {
+		return exporter.report, errors.Wrap(err, "Unable to close manifest entries")
+	}
+	exporter.report.Manifest.CompletedAt = options.Now().Unix()
+	if err = exporter.saveManifest(); err != nil {
+		return exporter.report, err
+	}
+
+	if options.Format == FormatEML && len(options.ZipPath) > 0 {
+		if err = zipDir(options.Dir, options.ZipPath); err != nil {
+			return exporter.report, err
+		}
+	}
+
+	return exporter.report, nil
+}
+
+// exporter is the state of a single export
+type exporter struct {
+	client  ciolite.Interface
+	userID  string
+	label   string
+	options Options
+
+	report Report
+
+	// done are the folder and message ids already in the manifest
+	done map[string]bool
+
+	// entries is ManifestEntriesFile, open for appending, and page the entries not yet appended to it
+	entries *os.File
+	page    []ManifestEntry
+}
+
+// open creates the export directory, or loads the manifest of the export being resumed,
+// and then opens the manifest entries for appending
+func (e *exporter) open() error {
+
+	if err := os.MkdirAll(e.options.Dir, 0700); err != nil {
+		return errors.Wrap(err, "Unable to create export directory")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(e.options.Dir, ManifestFile))
+	switch {
+	case os.IsNotExist(err):
+		e.report.Manifest = Manifest{UserID: e.userID, Label: e.label, Format: e.options.Format, StartedAt: e.options.Now().Unix()}
+		if err = e.saveManifest(); err != nil {
+			return err
+		}
+	case err != nil:
+		return errors.Wrap(err, "Unable to read manifest")
+	case !e.options.Resume:
+		return errors.New("Export directory already contains an export, and Resume is not set")
+	default:
+		if err = json.Unmarshal(data, &e.report.Manifest); err != nil {
+			return errors.Wrap(err, "Unable to unmarshal manifest")
+		}
+		if e.report.Manifest.Format != e.options.Format || e.report.Manifest.UserID != e.userID || e.report.Manifest.Label != e.label {
+			return errors.New("Export directory contains a different export")
+		}
+		e.report.Manifest.CompletedAt = 0
+	}
+
+	e.entries, err = os.OpenFile(filepath.Join(e.options.Dir, ManifestEntriesFile), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Unable to open manifest entries")
+	}
+	data, err = ioutil.ReadAll(e.entries)
+	if err != nil {
+		return errors.Wrap(err, "Unable to read manifest entries")
+	}
+
+	// A partially written last line (from an interrupted export) is dropped
+	end := int64(bytes.LastIndexByte(data, '\n') + 1)
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry ManifestEntry
+		if err = json.Unmarshal(line, &entry); err != nil {
+			return errors.Wrap(err, "Unable to unmarshal manifest entry")
+		}
+		e.report.Manifest.Messages = append(e.report.Manifest.Messages, entry)
+		e.done[doneKey(entry.Folder, entry.MessageID)] = true
+	}
+	if err = e.entries.Truncate(end); err != nil {
+		return errors.Wrap(err, "Unable to truncate manifest entries")
+	}
+	if _, err = e.entries.Seek(end, io.SeekStart); err != nil {
+		return errors.Wrap(err, "Unable to seek manifest entries")
+	}
+	return nil
+}
+
+// close closes the manifest entries, if they are still open
+func (e *exporter) close() {
+	if e.entries != nil {
+		_ = e.entries.Close()
+		e.entries = nil
+	}
+}
+
+// fetched is a raw message fetched for a page
+type fetched struct {
+	messageID string
+	raw       []byte
+	sender    string
+	date      time.Time
+	err       error
+}
+
+// exportFolder exports every message of the folder, a page at a time
+func (e *exporter) exportFolder(folder ciolite.GetUsersEmailAccountFoldersResponse) error {
+
+	var mbox *os.File
+	if e.options.Format == FormatMbox {
+		var err error
+		if mbox, err = e.openMbox(folder.Name); err != nil {
+			return err
+		}
+		defer mbox.Close()
+	} else if err := os.MkdirAll(filepath.Join(e.options.Dir, fileName(folder.Name)), 0700); err != nil {
+		return errors.Wrap(err, "Unable to create folder directory")
+	}
+
+	for offset := 0; ; {
+		page, err := e.client.GetUserEmailAccountsFolderMessages(e.userID, e.label, folder.Name,
+			ciolite.GetUserEmailAccountsFolderMessageParams{Delimiter: folder.Delimiter, Limit: e.options.PageSize, Offset: offset})
+		if err != nil {
+			return errors.Wrap(err, "Unable to list messages")
+		}
+		offset += len(page)
+
+		var messages []ciolite.GetUsersEmailAccountFolderMessagesResponse
+		for _, message := range page {
+			switch {
+			case len(message.MessageID) == 0:
+				e.report.Failed = append(e.report.Failed, MessageError{Folder: folder.Name, Err: errors.New("Message has no message_id")})
+			case e.done[doneKey(folder.Name, message.MessageID)]:
+				e.report.Skipped++
+			default:
+				messages = append(messages, message)
+			}
+		}
+
+		for _, f := range e.fetch(folder, messages) {
+			if f.err != nil {
+				e.report.Failed = append(e.report.Failed, MessageError{Folder: folder.Name, MessageID: f.messageID, Err: f.err})
+				continue
+			}
+			if err = e.write(folder.Name, mbox, f); err != nil {
+				return err
+			}
+		}
+
+		if err = e.appendEntries(); err != nil {
+			return err
+		}
+		if len(page) < e.options.PageSize {
+			return nil
+		}
+	}
+}
+
+// fetch fetches the raw messages, with at most Concurrency requests at a time, returning them in order
+func (e *exporter) fetch(folder ciolite.GetUsersEmailAccountFoldersResponse, messages []ciolite.GetUsersEmailAccountFolderMessagesResponse) []fetched {
+
+	results := make([]fetched, len(messages))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < e.options.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				message := messages[i]
+				raw, err := e.client.GetUserEmailAccountsFolderMessageRaw(e.userID, e.label, folder.Name, message.MessageID,
+					ciolite.EmailAccountFolderDelimiterParam{Delimiter: folder.Delimiter})
+				results[i] = fetched{
+					messageID: message.MessageID,
+					raw:       []byte(raw),
+					sender:    mboxSender(message),
+					date:      mboxDate(message),
+					err:       errors.Wrap(err, "Unable to fetch raw message"),
+				}
+			}
+		}()
+	}
+
+	for i := range messages {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// write writes a fetched message, and adds it to the manifest
+func (e *exporter) write(folder string, mbox *os.File, f fetched) error {
+
+	sum := sha256.Sum256(f.raw)
+	entry := ManifestEntry{
+		Folder:    folder,
+		MessageID: f.messageID,
+		Size:      int64(len(f.raw)),
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+
+	if mbox != nil {
+		offset, err := mbox.Seek(0, io.SeekEnd)
+		if err != nil {
+			return errors.Wrap(err, "Unable to seek mbox")
+		}
+		n, err := mbox.Write(mboxrdMessage(f.sender, f.date, f.raw))
+		if err != nil {
+			return errors.Wrap(err, "Unable to write mbox")
+		}
+		entry.File = fileName(folder) + ".mbox"
+		entry.Offset = offset
+		entry.Length = int64(n)
+	} else {
+		entry.File = filepath.Join(fileName(folder), fileName(f.messageID)+".eml")
+		if err := ioutil.WriteFile(filepath.Join(e.options.Dir, entry.File), f.raw, 0600); err != nil {
+			return errors.Wrap(err, "Unable to write eml")
+		}
+	}
+
+	e.report.Manifest.Messages = append(e.report.Manifest.Messages, entry)
+	e.page = append(e.page, entry)
+	e.report.Exported++
+	e.done[doneKey(folder, f.messageID)] = true
+	return nil
+}
+
+// openMbox opens the folder's mbox file for appending, first truncating anything written
+// after the last message in the manifest (a message that was being written when an export was interrupted)
+func (e *exporter) openMbox(folder string) (*os.File, error) {
+
+	name := fileName(folder) + ".mbox"
+	var end int64
+	for _, entry := range e.report.Manifest.Messages {
+		if entry.File == name && entry.Offset+entry.Length > end {
+			end = entry.Offset + entry.Length
+		}
+	}
+
+	mbox, err := os.OpenFile(filepath.Join(e.options.Dir, name), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open mbox")
+	}
+	if err = mbox.Truncate(end); err != nil {
+		mbox.Close()
+		return nil, errors.Wrap(err, "Unable to truncate mbox")
+	}
+	return mbox, nil
+}
+
+// appendEntries appends the entries of the page to the manifest entries, one per line
+func (e *exporter) appendEntries() error {
+	var buf bytes.Buffer
+	for _, entry := range e.page {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return errors.Wrap(err, "Unable to marshal manifest entry")
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	e.page = nil
+	if _, err := e.entries.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "Unable to write manifest entries")
+	}
+	return nil
+}
+
+// saveManifest writes the manifest (without its entries) to a temporary file, and then renames it into place
+func (e *exporter) saveManifest() error {
+	data, err := json.MarshalIndent(e.report.Manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Unable to marshal manifest")
+	}
+	path := filepath.Join(e.options.Dir, ManifestFile)
+	if err = ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		return errors.Wrap(err, "Unable to write manifest")
+	}
+	if err = os.Rename(path+".tmp", path); err != nil {
+		return errors.Wrap(err, "Unable to replace manifest")
+	}
+	return nil
+}
+
+// fromLine matches lines that must be escaped in an mboxrd file
+var fromLine = regexp.MustCompile(`(?m)^(>*From )`)
+
+// mboxrdMessage returns the message as an mboxrd entry: a From line, the message with LF line endings
+// and any (already quoted) From lines quoted with another '>', and a trailing blank line
+func mboxrdMessage(sender string, date time.Time, raw []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From " + sender + " " + date.UTC().Format(time.ANSIC) + "\n")
+
+	body := bytes.Replace(raw, []byte("\r\n"), []byte("\n"), -1)
+	buf.Write(fromLine.ReplaceAll(body, []byte(">$1")))
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// mboxSender returns the envelope sender for the From line
+func mboxSender(message ciolite.GetUsersEmailAccountFolderMessagesResponse) string {
+	if len(message.Addresses.From) > 0 && len(message.Addresses.From[0].Email) > 0 {
+		return message.Addresses.From[0].Email
+	}
+	return "MAILER-DAEMON"
+}
+
+// mboxDate returns the date for the From line
+func mboxDate(message ciolite.GetUsersEmailAccountFolderMessagesResponse) time.Time {
+	if message.ReceivedAt > 0 {
+		return message.ReceivedAt.Time()
+	}
+	return message.SentAt.Time()
+}
+
+// zipDir zips every file within dir (except the zip itself) into zipPath
+func zipDir(dir string, zipPath string) error {
+
+	file, err := os.Create(zipPath)
+	if err != nil {
+		return errors.Wrap(err, "Unable to create zip")
+	}
+	defer file.Close()
+
+	absZip, _ := filepath.Abs(zipPath)
+	writer := zip.NewWriter(file)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if abs, _ := filepath.Abs(path); abs == absZip {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		w, err := writer.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "Unable to zip export")
+	}
+
+	if err = writer.Close(); err != nil {
+		return errors.Wrap(err, "Unable to finish zip")
+	}
+	return errors.Wrap(file.Close(), "Unable to close zip")
+}
+
+// fileName returns a name that is safe to use as a file name
+func fileName(name string) string {
+	return url.QueryEscape(name)
+}
+
+// doneKey returns the key identifying an exported message
+func doneKey(folder string, messageID string) string {
+	return folder + "\x00" + messageID
+}
+
+// includes returns true if folders is empty, or contains folder
+func includes(folders []string, folder string) bool {
+	if len(folders) == 0 {
+		return true
+	}
+	for _, f := range folders {
+		if f == folder {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package export
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// testRaw are the raw messages of the simulated mailbox
+var testRaw = map[string]string{
+	"<1@x>": "Subject: One\r\n\r\nFrom here on\r\n>From there\r\nbye\r\n",
+	"<2@x>": "Subject: Two\r\n\r\nHello",
+	"<3@x>": "Subject: Three\r\n\r\nSent\r\n",
+}
+
+// testMailbox simulates INBOX (with <1@x> and <2@x>) and Sent/2018 (with <3@x>)
+type testMailbox struct {
+	mu      sync.Mutex
+	failing map[string]bool
+}
+
+// ServeHTTP serves the folders, messages, and raw messages
+func (mailbox *testMailbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mailbox.mu.Lock()
+	defer mailbox.mu.Unlock()
+
+	folders := map[string][]string{"INBOX": {"<1@x>", "<2@x>"}, "Sent/2018": {"<3@x>"}}
+	const prefix = "/lite/users/u1/email_accounts/0/folders"
+
+	path := strings.TrimPrefix(r.URL.EscapedPath(), prefix)
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	var response interface{}
+	switch {
+	case path == "":
+		response = []ciolite.GetUsersEmailAccountFoldersResponse{{Name: "INBOX", Delimiter: "/"}, {Name: "Sent/2018", Delimiter: "/"}}
+	case len(parts) == 2 && parts[1] == "messages":
+		folder := pathUnescaper.Replace(parts[0])
+		var messages []ciolite.GetUsersEmailAccountFolderMessagesResponse
+		for _, id := range folders[folder] {
+			message := ciolite.GetUsersEmailAccountFolderMessagesResponse{MessageID: id, ReceivedAt: 1262304000}
+			message.Addresses.From = []ciolite.Address{{Email: "jane@example.com"}}
+			messages = append(messages, message)
+		}
+		response = messages
+	case len(parts) == 4 && parts[3] == "raw":
+		id := pathUnescaper.Replace(parts[2])
+		if mailbox.failing[id] {
+			http.Error(w, `{"type": "error", "value": "unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := w.Write([]byte(testRaw[id])); err != nil {
+			panic(err)
+		}
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		panic(err)
+	}
+}
+
+// pathUnescaper unescapes the characters used by the test folders and message ids
+var pathUnescaper = strings.NewReplacer("%2F", "/", "%3C", "<", "%3E", ">", "%40", "@")
+
+// testTempDir returns a new temporary directory
+func testTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// TestSimulatedExportMbox tests exporting to mboxrd files, and resuming after a failure
+func TestSimulatedExportMbox(t *testing.T) {
+	t.Parallel()
+
+	mailbox := &testMailbox{failing: map[string]bool{"<2@x>": true}}
+	cioLite, testServer := ciolite.NewTestCioLiteServer(mailbox)
+	defer testServer.Close()
+
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	options := Options{Format: FormatMbox, Dir: dir, Concurrency: 2, PageSize: 10}
+	report, err := Export(cioLite, "u1", "0", options)
+	if err != nil || report.Exported != 2 || len(report.Failed) != 1 || report.Failed[0].MessageID != "<2@x>" {
+		t.Error("Expected 2 exported and <2@x> failed; Got: ", report, "; With Error: ", err)
+	}
+
+	// Exporting again requires Resume
+	if _, err = Export(cioLite, "u1", "0", options); err == nil {
+		t.Error("Expected an error exporting over an existing export")
+	}
+
+	mailbox.mu.Lock()
+	mailbox.failing = nil
+	mailbox.mu.Unlock()
+
+	// An entry that was only partially appended is dropped
+	entries, err := os.OpenFile(filepath.Join(dir, ManifestEntriesFile), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = entries.Write([]byte(`{"folder": "INBOX", "message_id": "<2@`)); err != nil {
+		t.Fatal(err)
+	}
+	if err = entries.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	options.Resume = true
+	report, err = Export(cioLite, "u1", "0", options)
+	if err != nil || report.Exported != 1 || report.Skipped != 2 || len(report.Failed) != 0 || report.Manifest.CompletedAt == 0 || len(report.Manifest.Messages) != 3 {
+		t.Error("Expected 1 exported and 2 skipped; Got: ", report, "; With Error: ", err)
+	}
+	lines, err := ioutil.ReadFile(filepath.Join(dir, ManifestEntriesFile))
+	if err != nil || strings.Count(string(lines), "\n") != 3 || !strings.HasSuffix(string(lines), "\n") {
+		t.Errorf("Expected 3 manifest entries; Got:\n%s\nWith Error: %v", lines, err)
+	}
+
+	inbox, err := ioutil.ReadFile(filepath.Join(dir, "INBOX.mbox"))
+	expected := "From jane@example.com Fri Jan  1 00:00:00 2010\n" +
+		"Subject: One\n\n>From here on\n>>From there\nbye\n\n" +
+		"From jane@example.com Fri Jan  1 00:00:00 2010\n" +
+		"Subject: Two\n\nHello\n\n"
+	if err != nil || string(inbox) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s\nWith Error: %v", expected, inbox, err)
+	}
+
+	// The manifest has the checksum of every raw message, and where it is
+	for _, entry := range report.Manifest.Messages {
+		sum := sha256.Sum256([]byte(testRaw[entry.MessageID]))
+		if entry.SHA256 != hex.EncodeToString(sum[:]) || entry.Size != int64(len(testRaw[entry.MessageID])) {
+			t.Error("Expected the checksum of ", entry.MessageID, "; Got: ", entry)
+		}
+		if entry.MessageID == "<2@x>" && (entry.File != "INBOX.mbox" || entry.Offset != int64(len(inbox))-entry.Length) {
+			t.Error("Expected <2@x> at the end of INBOX.mbox; Got: ", entry)
+		}
+		if entry.MessageID == "<3@x>" && entry.File != "Sent%2F2018.mbox" {
+			t.Error("Expected <3@x> in Sent%2F2018.mbox; Got: ", entry)
+		}
+	}
+}
+
+// TestSimulatedExportEMLZip tests exporting to a zip of .eml files
+func TestSimulatedExportEMLZip(t *testing.T) {
+	t.Parallel()
+
+	cioLite, testServer := ciolite.NewTestCioLiteServer(&testMailbox{})
+	defer testServer.Close()
+
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	zipPath := filepath.Join(dir, "export.zip")
+	report, err := Export(cioLite, "u1", "0", Options{Format: FormatEML, Dir: filepath.Join(dir, "eml"), ZipPath: zipPath, Folders: []string{"INBOX"}})
+	if err != nil || report.Exported != 2 {
+		t.Error("Expected 2 exported; Got: ", report, "; With Error: ", err)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal("Unable to open zip: ", err)
+	}
+	defer reader.Close()
+
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	expected := []string{"INBOX/%3C1%40x%3E.eml", "INBOX/%3C2%40x%3E.eml", ManifestFile, ManifestEntriesFile}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Error("Expected: ", expected, "; Got: ", names)
+	}
+}
+
+// TestMboxDate tests the date of the From line falls back to SentAt, and then to the zero time
+func TestMboxDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message  ciolite.GetUsersEmailAccountFolderMessagesResponse
+		expected time.Time
+	}{
+		{ciolite.GetUsersEmailAccountFolderMessagesResponse{ReceivedAt: 200, SentAt: 100}, time.Unix(200, 0)},
+		{ciolite.GetUsersEmailAccountFolderMessagesResponse{SentAt: 100}, time.Unix(100, 0)},
+		{ciolite.GetUsersEmailAccountFolderMessagesResponse{}, time.Time{}},
+	}
+	for _, test := range tests {
+		if date := mboxDate(test.message); !date.Equal(test.expected) {
+			t.Error("Expected: ", test.expected, "; Got: ", date)
+		}
+	}
+}
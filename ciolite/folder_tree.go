@@ -0,0 +1,239 @@
+package ciolite
+
+// Folder hierarchy built from the flat list returned by GetUserEmailAccountsFolders
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FolderRole is the special-use role of a folder (RFC 6154), from its SymbolicName
+type FolderRole string
+
+// FolderRole values
+const (
+	FolderRoleNone   FolderRole = ""
+	FolderRoleInbox  FolderRole = `\Inbox`
+	FolderRoleSent   FolderRole = `\Sent`
+	FolderRoleTrash  FolderRole = `\Trash`
+	FolderRoleJunk   FolderRole = `\Junk`
+	FolderRoleDrafts FolderRole = `\Drafts`
+	FolderRoleAll    FolderRole = `\All`
+)
+
+// folderRoles maps the lowercased symbolic names (and some common aliases) to their FolderRole
+var folderRoles = map[string]FolderRole{
+	`\inbox`:  FolderRoleInbox,
+	`inbox`:   FolderRoleInbox,
+	`\sent`:   FolderRoleSent,
+	`\trash`:  FolderRoleTrash,
+	`\junk`:   FolderRoleJunk,
+	`\spam`:   FolderRoleJunk,
+	`\drafts`: FolderRoleDrafts,
+	`\all`:    FolderRoleAll,
+}
+
+// ParseFolderRole returns the FolderRole of a symbolic name, or FolderRoleNone if it has no known role
+func ParseFolderRole(symbolicName string) FolderRole {
+	return folderRoles[strings.ToLower(strings.TrimSpace(symbolicName))]
+}
+
+// SkipFolder can be returned by a FolderTree.Walk function to skip the children of the current folder
+var SkipFolder = errors.New("skip this folder")
+
+// FolderNode is a single folder within a FolderTree
+type FolderNode struct {
+	// Name is the last segment of the folder path
+	Name string
+
+	// Path is the full folder name, as used by the folder API calls
+	Path string
+
+	// Delimiter separates the segments of Path
+	Delimiter string
+
+	Role FolderRole
+
+	// Folder is the folder as returned by CIO, or nil if this node was only
+	// created because it is a missing parent of another folder
+	Folder *GetUsersEmailAccountFoldersResponse
+
+	Parent   *FolderNode
+	Children []*FolderNode
+}
+
+// FolderTree is the folder hierarchy of an email account
+type FolderTree struct {
+	// Roots are the top level folders, sorted by name (with the Inbox first)
+	Roots []*FolderNode
+
+	byPath map[string]*FolderNode
+	byRole map[FolderRole]*FolderNode
+}
+
+// NewFolderTree builds a FolderTree from a list of folders.
+// Each folder is split on its own Delimiter, so accounts mixing delimiters are supported,
+// and any missing intermediate parents are added as nodes without a Folder.
+func NewFolderTree(folders []GetUsersEmailAccountFoldersResponse) *FolderTree {
+
+	tree := &FolderTree{
+		byPath: map[string]*FolderNode{},
+		byRole: map[FolderRole]*FolderNode{},
+	}
+
+	for i := range folders {
+		folder := folders[i]
+		node := tree.node(folder.Name, folder.Delimiter)
+		node.Folder = &folder
+
+		role := ParseFolderRole(folder.SymbolicName)
+		if role == FolderRoleNone && node.Parent == nil && strings.EqualFold(folder.Name, "INBOX") {
+			role = FolderRoleInbox
+		}
+		node.Role = role
+		if _, exists := tree.byRole[role]; role != FolderRoleNone && !exists {
+			tree.byRole[role] = node
+		}
+	}
+
+	sortFolderNodes(tree.Roots)
+	return tree
+}
+
+// node returns the node for a path, creating it and any missing parents
+func (tree *FolderTree) node(path string, delimiter string) *FolderNode {
+
+	if node, ok := tree.byPath[path]; ok {
+		if len(node.Delimiter) == 0 {
+			node.Delimiter = delimiter
+		}
+		return node
+	}
+
+	node := &FolderNode{
+		Name:      path,
+		Path:      path,
+		Delimiter: delimiter,
+	}
+
+	if len(delimiter) > 0 {
+		if idx := strings.LastIndex(path, delimiter); idx > 0 && idx+len(delimiter) < len(path) {
+			node.Name = path[idx+len(delimiter):]
+			node.Parent = tree.node(path[:idx], delimiter)
+		}
+	}
+
+	tree.byPath[path] = node
+	if node.Parent != nil {
+		node.Parent.Children = append(node.Parent.Children, node)
+	} else {
+		tree.Roots = append(tree.Roots, node)
+	}
+	return node
+}
+
+// Find returns the folder with this full path, or nil if there is none
+func (tree *FolderTree) Find(path string) *FolderNode {
+	return tree.byPath[path]
+}
+
+// FindRole returns the folder with this special-use role, or nil if there is none
+func (tree *FolderTree) FindRole(role FolderRole) *FolderNode {
+	return tree.byRole[role]
+}
+
+// Len returns the number of folders in the tree, including missing parents
+func (tree *FolderTree) Len() int {
+	return len(tree.byPath)
+}
+
+// Walk calls fn for every folder in the tree, depth first, parents before their children.
+// Depth is 0 for the roots. If fn returns SkipFolder the children of that folder are skipped,
+// and any other error stops the walk and is returned.
+func (tree *FolderTree) Walk(fn func(node *FolderNode, depth int) error) error {
+	for _, root := range tree.Roots {
+		if err := root.walk(fn, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk calls fn for this node and its descendants
+func (node *FolderNode) walk(fn func(node *FolderNode, depth int) error, depth int) error {
+	if err := fn(node, depth); err != nil {
+		if err == SkipFolder {
+			return nil
+		}
+		return err
+	}
+	for _, child := range node.Children {
+		if err := child.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists returns true if this folder was returned by CIO, and false if it is only a missing parent
+func (node *FolderNode) Exists() bool {
+	return node.Folder != nil
+}
+
+// Depth returns the number of ancestors of this folder
+func (node *FolderNode) Depth() int {
+	depth := 0
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		depth++
+	}
+	return depth
+}
+
+// Messages returns the number of messages in this folder
+func (node *FolderNode) Messages() int {
+	if node.Folder == nil {
+		return 0
+	}
+	return node.Folder.NbMessages
+}
+
+// Unseen returns the number of unseen messages in this folder
+func (node *FolderNode) Unseen() int {
+	if node.Folder == nil {
+		return 0
+	}
+	return node.Folder.NbUnseenMessages
+}
+
+// TotalMessages returns the number of messages in this folder and all of its descendants
+func (node *FolderNode) TotalMessages() int {
+	total := node.Messages()
+	for _, child := range node.Children {
+		total += child.TotalMessages()
+	}
+	return total
+}
+
+// TotalUnseen returns the number of unseen messages in this folder and all of its descendants
+func (node *FolderNode) TotalUnseen() int {
+	total := node.Unseen()
+	for _, child := range node.Children {
+		total += child.TotalUnseen()
+	}
+	return total
+}
+
+// sortFolderNodes sorts the nodes (Inbox first, then by name) and all of their descendants
+func sortFolderNodes(nodes []*FolderNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if (nodes[i].Role == FolderRoleInbox) != (nodes[j].Role == FolderRoleInbox) {
+			return nodes[i].Role == FolderRoleInbox
+		}
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+	for _, node := range nodes {
+		sortFolderNodes(node.Children)
+	}
+}
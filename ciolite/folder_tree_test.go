@@ -0,0 +1,68 @@
+package ciolite
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFolderTree tests building, looking up, and walking a FolderTree
+func TestFolderTree(t *testing.T) {
+	t.Parallel()
+
+	tree := NewFolderTree([]GetUsersEmailAccountFoldersResponse{
+		{Name: "Work/Projects/Alpha", Delimiter: "/", NbMessages: 4, NbUnseenMessages: 2},
+		{Name: "INBOX", Delimiter: "/", NbMessages: 10, NbUnseenMessages: 3},
+		{Name: "Work", Delimiter: "/", NbMessages: 1, NbUnseenMessages: 1},
+		{Name: "[Gmail]/Sent Mail", Delimiter: "/", SymbolicName: `\Sent`},
+		{Name: "[Gmail]/Spam", Delimiter: "/", SymbolicName: `\Junk`, NbUnseenMessages: 7},
+		{Name: "Archive.2017", Delimiter: ".", NbMessages: 2},
+	})
+
+	if tree.Len() != 9 {
+		t.Error("Expected 9 folders; Got: ", tree.Len())
+	}
+
+	// Roles
+	if node := tree.FindRole(FolderRoleInbox); node == nil || node.Path != "INBOX" {
+		t.Error("Expected INBOX for role ", FolderRoleInbox, "; Got: ", node)
+	}
+	if node := tree.FindRole(FolderRoleSent); node == nil || node.Path != "[Gmail]/Sent Mail" || node.Name != "Sent Mail" {
+		t.Error("Expected [Gmail]/Sent Mail for role ", FolderRoleSent, "; Got: ", node)
+	}
+	if node := tree.FindRole(FolderRoleTrash); node != nil {
+		t.Error("Expected no folder for role ", FolderRoleTrash, "; Got: ", node)
+	}
+
+	// Missing parents
+	if node := tree.Find("Work/Projects"); node == nil || node.Exists() || node.Parent != tree.Find("Work") || node.Depth() != 1 {
+		t.Error("Expected missing parent Work/Projects under Work; Got: ", node)
+	}
+	if node := tree.Find("[Gmail]"); node == nil || node.Exists() || node.TotalUnseen() != 7 {
+		t.Error("Expected missing parent [Gmail] with 7 unseen; Got: ", node)
+	}
+
+	// Aggregated counts
+	if work := tree.Find("Work"); work == nil || work.TotalUnseen() != 3 || work.TotalMessages() != 5 || work.Unseen() != 1 {
+		t.Error("Expected Work with 3 total unseen and 5 total messages; Got: ", work)
+	}
+
+	// Mixed delimiters
+	if node := tree.Find("Archive.2017"); node == nil || node.Name != "2017" || node.Parent == nil || node.Parent.Path != "Archive" {
+		t.Error("Expected Archive.2017 under Archive; Got: ", node)
+	}
+
+	// Walk, skipping [Gmail]
+	var walked []string
+	err := tree.Walk(func(node *FolderNode, depth int) error {
+		walked = append(walked, strings.Repeat("-", depth)+node.Name)
+		if node.Path == "[Gmail]" {
+			return SkipFolder
+		}
+		return nil
+	})
+	expected := []string{"INBOX", "[Gmail]", "Archive", "-2017", "Work", "-Projects", "--Alpha"}
+	if err != nil || !reflect.DeepEqual(walked, expected) {
+		t.Error("Expected walk: ", expected, "; Got: ", walked, "; With Error: ", err)
+	}
+}
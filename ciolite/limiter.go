@@ -0,0 +1,74 @@
+package ciolite
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter paces calls made to CIO, so that bulk helpers stay under the API rate limits.
+// Bulk helpers (ex: SweepConnectTokens, RunMessageBatch, and the export and mailsync packages) make every call
+// through the client they are given, so they are paced by its Limiter (see WithLimiter), and retried by WithRetry.
+// Wait blocks until the next call is allowed.
+type Limiter interface {
+	Wait()
+}
+
+// NewLimiter returns a Limiter that allows at most perSecond calls each second,
+// with bursts of up to burst calls. A perSecond of zero or less returns a Limiter that never waits.
+func NewLimiter(perSecond float64, burst int) Limiter {
+	if perSecond <= 0 {
+		return unlimited{}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// unlimited is a Limiter that never waits
+type unlimited struct{}
+
+// Wait returns immediately
+func (unlimited) Wait() {}
+
+// tokenBucket is a simple token bucket Limiter
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// Wait blocks until a token is available, then consumes it
+func (tb *tokenBucket) Wait() {
+	tb.mu.Lock()
+	now := time.Now()
+	tb.tokens += float64(now.Sub(tb.last)) / float64(tb.interval)
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+	tb.tokens--
+	var sleep time.Duration
+	if tb.tokens < 0 {
+		sleep = time.Duration(-tb.tokens * float64(tb.interval))
+	}
+	tb.mu.Unlock()
+
+	if sleep > 0 {
+		time.Sleep(sleep)
+	}
+}
+
+// waitLimiter waits on the limiter, if there is one
+func waitLimiter(limiter Limiter) {
+	if limiter != nil {
+		limiter.Wait()
+	}
+}
@@ -27,8 +27,8 @@ type GetConnectTokenResponse struct {
 
 	AccountLite bool `json:"account_lite,omitempty"`
 
-	Created int `json:"created,omitempty"`
-	Used    int `json:"used,omitempty"`
+	Created UnixTime `json:"created,omitempty"`
+	Used    UnixTime `json:"used,omitempty"`
 
 	Expires ExpiresMixed `json:"expires,omitempty"`
 
@@ -43,7 +43,7 @@ type GetConnectTokenUserResponse struct {
 	EmailAddresses []string `json:"email_addresses,omitempty"`
 	FirstName      string   `json:"first_name,omitempty"`
 	LastName       string   `json:"last_name,omitempty"`
-	Created        int      `json:"created,omitempty"`
+	Created        UnixTime `json:"created,omitempty"`
 
 	EmailAccounts []GetUsersEmailAccountsResponse `json:"email_accounts,omitempty"`
 }
@@ -55,13 +55,13 @@ type GetConnectTokenUserResponse struct {
 // 	https://context.io/docs/lite/users/connect_tokens#post
 type CreateConnectTokenParams struct {
 	// Required:
-	CallbackURL string `json:"callback_url"`
+	CallbackURL string `json:"callback_url" valid:"required,url"`
 
 	// Optional:
-	Email             string `json:"email,omitempty"`
+	Email             string `json:"email,omitempty" valid:"email"`
 	FirstName         string `json:"first_name,omitempty"`
 	LastName          string `json:"last_name,omitempty"`
-	StatusCallbackURL string `json:"status_callback_url,omitempty"`
+	StatusCallbackURL string `json:"status_callback_url,omitempty" valid:"url"`
 }
 
 // CreateConnectTokenResponse data struct
@@ -86,7 +86,7 @@ type DeleteConnectTokenResponse struct {
 func (cioLite CioLite) GetConnectTokens() ([]GetConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   "/lite/connect_tokens",
 	}
@@ -105,7 +105,7 @@ func (cioLite CioLite) GetConnectTokens() ([]GetConnectTokenResponse, error) {
 func (cioLite CioLite) GetConnectToken(token string) (GetConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/connect_tokens/%s", token),
 	}
@@ -126,7 +126,7 @@ func (cioLite CioLite) GetConnectToken(token string) (GetConnectTokenResponse, e
 func (cioLite CioLite) CreateConnectToken(formValues CreateConnectTokenParams) (CreateConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       "/lite/connect_tokens",
 		FormValues: formValues,
@@ -146,7 +146,7 @@ func (cioLite CioLite) CreateConnectToken(formValues CreateConnectTokenParams) (
 func (cioLite CioLite) DeleteConnectToken(token string) (DeleteConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/lite/connect_tokens/%s", token),
 	}
@@ -169,6 +169,11 @@ func (user GetConnectTokenUserResponse) EmailAccountMatching(email string) (GetU
 // CheckConnectToken checks and returns nil if the connect token was used, the email
 // authorized matches the expected email, and that CIO has access to the account.
 func (cioLite CioLite) CheckConnectToken(connectToken GetConnectTokenResponse, email string) error {
+	return checkConnectToken(connectToken, email)
+}
+
+// checkConnectToken implements CheckConnectToken, which needs no client
+func checkConnectToken(connectToken GetConnectTokenResponse, email string) error {
 
 	// Confirm email matches
 	if strings.ToLower(connectToken.Email) != strings.ToLower(email) {
@@ -176,7 +181,7 @@ func (cioLite CioLite) CheckConnectToken(connectToken GetConnectTokenResponse, e
 	}
 
 	// Confirm token was used (accepted/authorized)
-	if connectToken.Used == 0 || connectToken.Expires.Unused() {
+	if connectToken.Used.IsZero() || connectToken.Expires.Unused() {
 		return errors.New("Context.io token not used yet")
 	}
 
@@ -187,7 +192,7 @@ func (cioLite CioLite) CheckConnectToken(connectToken GetConnectTokenResponse, e
 
 	// Confirm we have access
 	account, err := connectToken.User.EmailAccountMatching(email)
-	if err != nil || account.Status != "OK" {
+	if err != nil || !account.Status.IsOK() {
 		return errors.New("Unable to access account using Context.io")
 	}
 
@@ -198,6 +203,7 @@ func (cioLite CioLite) CheckConnectToken(connectToken GetConnectTokenResponse, e
 // 	Unix timestamp of when this token will expire and be purged.
 // 	Once the token is used, this property will be set to false
 // 	https://context.io/docs/lite/users/connect_tokens
+// Unlike UnixTime (which decodes false as zero), it keeps false distinct from a timestamp.
 type ExpiresMixed struct {
 	Expires *int
 }
@@ -6,13 +6,13 @@ package ciolite
 // Requires Email.
 type GetDiscoveryParams struct {
 	// Required:
-	Email string `json:"email"`
+	Email string `json:"email" valid:"required,email"`
 }
 
 // GetDiscoveryResponse data struct
 type GetDiscoveryResponse struct {
-	Email string `json:"email,omitempty"`
-	Type  string `json:"type,omitempty"`
+	Email string      `json:"email,omitempty"`
+	Type  AccountType `json:"type,omitempty"`
 
 	// Value only appears if there is an error message
 	Value string `json:"value,omitempty"`
@@ -40,7 +40,7 @@ type GetDiscoveryIMAPResponse struct {
 func (cioLite CioLite) GetDiscovery(queryValues GetDiscoveryParams) (GetDiscoveryResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:      "GET",
 		Path:        "/lite/discovery",
 		QueryValues: queryValues,
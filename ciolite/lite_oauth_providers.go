@@ -18,9 +18,9 @@ type GetOAuthProvidersResponse struct {
 // Requires Type, ProviderConsumerKey, ProviderConsumerSecret.
 type CreateOAuthProviderParams struct {
 	// Requires:
-	Type                   string `json:"type"`
-	ProviderConsumerKey    string `json:"provider_consumer_key"`
-	ProviderConsumerSecret string `json:"provider_consumer_secret"`
+	Type                   string `json:"type" valid:"required"`
+	ProviderConsumerKey    string `json:"provider_consumer_key" valid:"required"`
+	ProviderConsumerSecret string `json:"provider_consumer_secret" valid:"required"`
 }
 
 // CreateOAuthProviderResponse data struct
@@ -39,7 +39,7 @@ type DeleteOAuthProviderResponse struct {
 func (cioLite CioLite) GetOAuthProviders() ([]GetOAuthProvidersResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   "/lite/oauth_providers",
 	}
@@ -57,7 +57,7 @@ func (cioLite CioLite) GetOAuthProviders() ([]GetOAuthProvidersResponse, error)
 func (cioLite CioLite) GetOAuthProvider(key string) (GetOAuthProvidersResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/oauth_providers/%s", key),
 	}
@@ -76,7 +76,7 @@ func (cioLite CioLite) GetOAuthProvider(key string) (GetOAuthProvidersResponse,
 func (cioLite CioLite) CreateOAuthProvider(formValues CreateOAuthProviderParams) (CreateOAuthProviderResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       "/lite/oauth_providers",
 		FormValues: formValues,
@@ -95,7 +95,7 @@ func (cioLite CioLite) CreateOAuthProvider(formValues CreateOAuthProviderParams)
 func (cioLite CioLite) DeleteOAuthProvider(key string) (DeleteOAuthProviderResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/lite/oauth_providers/%s", key),
 	}
@@ -10,9 +10,9 @@ import (
 // Optional: Email, Status, StatusOK, Limit, Offset.
 type GetUsersParams struct {
 	// Optional:
-	Email    string `json:"email,omitempty"`
+	Email    string `json:"email,omitempty" valid:"email"`
 	Status   string `json:"status,omitempty"`
-	StatusOK string `json:"status_ok,omitempty"`
+	StatusOK string `json:"status_ok,omitempty" valid:"in(0|1)"`
 	Limit    int    `json:"limit,omitempty"`
 	Offset   int    `json:"offset,omitempty"`
 }
@@ -28,9 +28,9 @@ type GetUsersResponse struct {
 
 	EmailAccounts []GetUsersEmailAccountsResponse `json:"email_accounts,omitempty"`
 
-	Created         int `json:"created,omitempty"`
-	Suspended       int `json:"suspended,omitempty"`
-	PasswordExpired int `json:"password_expired,omitempty"`
+	Created         UnixTime `json:"created,omitempty"`
+	Suspended       UnixTime `json:"suspended,omitempty"`
+	PasswordExpired int      `json:"password_expired,omitempty"`
 }
 
 // CreateUserParams form values data struct.
@@ -58,7 +58,7 @@ type CreateUserParams struct {
 	Password string `json:"password,omitempty"`
 
 	// Optional:
-	StatusCallbackURL string `json:"status_callback_url,omitempty"`
+	StatusCallbackURL string `json:"status_callback_url,omitempty" valid:"url"`
 
 	// Optional for CreaseUser only (not used by CreateUserEmailAccount):
 	MigrateAccountID string `json:"migrate_account_id,omitempty"`
@@ -85,8 +85,8 @@ type CreateUserResponse struct {
 // Requires: FirstName, LastName.
 type ModifyUserParams struct {
 	// Requires:
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName string `json:"first_name" valid:"required"`
+	LastName  string `json:"last_name" valid:"required"`
 }
 
 // ModifyUserResponse data struct
@@ -106,7 +106,7 @@ type DeleteUserResponse struct {
 func (cioLite CioLite) GetUsers(queryValues GetUsersParams) ([]GetUsersResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:      "GET",
 		Path:        "/lite/users",
 		QueryValues: queryValues,
@@ -125,7 +125,7 @@ func (cioLite CioLite) GetUsers(queryValues GetUsersParams) ([]GetUsersResponse,
 func (cioLite CioLite) GetUser(userID string) (GetUsersResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/users/%s", userID),
 		UserID: userID,
@@ -150,7 +150,7 @@ func (cioLite CioLite) GetUser(userID string) (GetUsersResponse, error) {
 func (cioLite CioLite) CreateUser(formValues CreateUserParams) (CreateUserResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       "/lite/users",
 		FormValues: formValues,
@@ -170,7 +170,7 @@ func (cioLite CioLite) CreateUser(formValues CreateUserParams) (CreateUserRespon
 func (cioLite CioLite) ModifyUser(userID string, formValues ModifyUserParams) (ModifyUserResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       fmt.Sprintf("/lite/users/%s", userID),
 		FormValues: formValues,
@@ -190,7 +190,7 @@ func (cioLite CioLite) ModifyUser(userID string, formValues ModifyUserParams) (M
 func (cioLite CioLite) DeleteUser(userID string) (DeleteUserResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/lite/users/%s", userID),
 		UserID: userID,
@@ -10,7 +10,7 @@ import (
 func (cioLite CioLite) GetUserConnectTokens(userID string) ([]GetConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/users/%s/connect_tokens", userID),
 		UserID: userID,
@@ -29,7 +29,7 @@ func (cioLite CioLite) GetUserConnectTokens(userID string) ([]GetConnectTokenRes
 func (cioLite CioLite) GetUserConnectToken(userID string, token string) (GetConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/users/%s/connect_tokens/%s", userID, token),
 		UserID: userID,
@@ -50,7 +50,7 @@ func (cioLite CioLite) GetUserConnectToken(userID string, token string) (GetConn
 func (cioLite CioLite) CreateUserConnectToken(userID string, formValues CreateConnectTokenParams) (CreateConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       fmt.Sprintf("/lite/users/%s/connect_tokens", userID),
 		FormValues: formValues,
@@ -70,7 +70,7 @@ func (cioLite CioLite) CreateUserConnectToken(userID string, formValues CreateCo
 func (cioLite CioLite) DeleteUserConnectToken(userID string, token string) (DeleteConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/lite/users/%s/connect_tokens/%s", userID, token),
 		UserID: userID,
@@ -14,19 +14,19 @@ import (
 type GetUserEmailAccountsParams struct {
 	// Optional:
 	Status   string `json:"status,omitempty"`
-	StatusOK string `json:"status_ok,omitempty"`
+	StatusOK string `json:"status_ok,omitempty" valid:"in(0|1)"`
 }
 
 // GetUsersEmailAccountsResponse data struct
 type GetUsersEmailAccountsResponse struct {
-	Status               string `json:"status,omitempty"`
-	ResourceURL          string `json:"resource_url,omitempty"`
-	Type                 string `json:"type,omitempty"`
-	AuthenticationType   string `json:"authentication_type,omitempty"`
-	Server               string `json:"server,omitempty"`
-	Label                string `json:"label,omitempty"`
-	Username             string `json:"username,omitempty"`
-	MailServiceAccountID string `json:"mailservice_account_id,omitempty"`
+	Status               AccountStatus      `json:"status,omitempty"`
+	ResourceURL          string             `json:"resource_url,omitempty"`
+	Type                 AccountType        `json:"type,omitempty"`
+	AuthenticationType   AuthenticationType `json:"authentication_type,omitempty"`
+	Server               string             `json:"server,omitempty"`
+	Label                string             `json:"label,omitempty"`
+	Username             string             `json:"username,omitempty"`
+	MailServiceAccountID string             `json:"mailservice_account_id,omitempty"`
 
 	UseSSL bool `json:"use_ssl,omitempty"`
 
@@ -35,10 +35,10 @@ type GetUsersEmailAccountsResponse struct {
 
 // CreateEmailAccountResponse data struct
 type CreateEmailAccountResponse struct {
-	Status               string `json:"status,omitempty"`
-	Label                string `json:"label,omitempty"`
-	ResourceURL          string `json:"resource_url,omitempty"`
-	MailServiceAccountID string `json:"mailservice_account_id,omitempty"`
+	Status               AccountStatus `json:"status,omitempty"`
+	Label                string        `json:"label,omitempty"`
+	ResourceURL          string        `json:"resource_url,omitempty"`
+	MailServiceAccountID string        `json:"mailservice_account_id,omitempty"`
 }
 
 // ModifyUserEmailAccountParams form values data struct.
@@ -50,7 +50,7 @@ type ModifyUserEmailAccountParams struct {
 	Password             string `json:"password,omitempty"`
 	ProviderRefreshToken string `json:"provider_refresh_token,omitempty"`
 	ProviderConsumerKey  string `json:"provider_consumer_key,omitempty"`
-	StatusCallbackURL    string `json:"status_callback_url,omitempty"`
+	StatusCallbackURL    string `json:"status_callback_url,omitempty" valid:"url"`
 	ForceStatusCheck     bool   `json:"force_status_check,omitempty"`
 }
 
@@ -89,7 +89,7 @@ type StatusCallback struct {
 func (cioLite CioLite) GetUserEmailAccounts(userID string, queryValues GetUserEmailAccountsParams) ([]GetUsersEmailAccountsResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:      "GET",
 		Path:        fmt.Sprintf("/lite/users/%s/email_accounts", userID),
 		QueryValues: queryValues,
@@ -110,7 +110,7 @@ func (cioLite CioLite) GetUserEmailAccounts(userID string, queryValues GetUserEm
 func (cioLite CioLite) GetUserEmailAccount(userID string, label string) (GetUsersEmailAccountsResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s", userID, label),
 		UserID:       userID,
@@ -134,7 +134,7 @@ func (cioLite CioLite) GetUserEmailAccount(userID string, label string) (GetUser
 func (cioLite CioLite) CreateUserEmailAccount(userID string, formValues CreateUserParams) (CreateEmailAccountResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       fmt.Sprintf("/lite/users/%s/email_accounts", userID),
 		FormValues: formValues,
@@ -156,7 +156,7 @@ func (cioLite CioLite) CreateUserEmailAccount(userID string, formValues CreateUs
 func (cioLite CioLite) ModifyUserEmailAccount(userID string, label string, formValues ModifyUserEmailAccountParams) (ModifyEmailAccountResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "POST",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s", userID, label),
 		FormValues:   formValues,
@@ -177,7 +177,7 @@ func (cioLite CioLite) ModifyUserEmailAccount(userID string, label string, formV
 func (cioLite CioLite) DeleteUserEmailAccount(userID string, label string) (DeleteEmailAccountResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "DELETE",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s", userID, label),
 		UserID:       userID,
@@ -10,7 +10,7 @@ import (
 func (cioLite CioLite) GetUserEmailAccountConnectTokens(userID string, label string) ([]GetConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/users/%s/email_accounts/%s/connect_tokens", userID, label),
 		UserID: userID,
@@ -29,7 +29,7 @@ func (cioLite CioLite) GetUserEmailAccountConnectTokens(userID string, label str
 func (cioLite CioLite) GetUserEmailAccountConnectToken(userID string, label string, token string) (GetConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/users/%s/email_accounts/%s/connect_tokens/%s", userID, label, token),
 		UserID: userID,
@@ -49,7 +49,7 @@ func (cioLite CioLite) GetUserEmailAccountConnectToken(userID string, label stri
 func (cioLite CioLite) CreateUserEmailAccountConnectToken(userID string, label string, formValues CreateConnectTokenParams) (CreateConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       fmt.Sprintf("/lite/users/%s/email_accounts/%s/connect_tokens", userID, label),
 		FormValues: formValues,
@@ -69,7 +69,7 @@ func (cioLite CioLite) CreateUserEmailAccountConnectToken(userID string, label s
 func (cioLite CioLite) DeleteUserEmailAccountConnectToken(userID string, label string, token string) (DeleteConnectTokenResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/lite/users/%s/email_accounts/%s/connect_tokens/%s", userID, label, token),
 		UserID: userID,
@@ -38,12 +38,31 @@ type CreateEmailAccountFolderResponse struct {
 	Success bool `json:"success,omitempty"`
 }
 
+// RenameUserEmailAccountFolderParams query values data struct.
+// Requires: NewFolderID, and may optionally contain Delimiter.
+type RenameUserEmailAccountFolderParams struct {
+	// Required:
+	NewFolderID string `json:"new_folder_id" valid:"required"`
+	// Optional:
+	Delimiter string `json:"delimiter,omitempty"`
+}
+
+// RenameEmailAccountFolderResponse data struct
+type RenameEmailAccountFolderResponse struct {
+	Success bool `json:"success,omitempty"`
+}
+
+// DeleteEmailAccountFolderResponse data struct
+type DeleteEmailAccountFolderResponse struct {
+	Success bool `json:"success,omitempty"`
+}
+
 // GetUserEmailAccountsFolders gets a list of folders in an email account.
 // queryValues may optionally contain IncludeNamesOnly
 func (cioLite CioLite) GetUserEmailAccountsFolders(userID string, label string, queryValues GetUserEmailAccountsFoldersParams) ([]GetUsersEmailAccountFoldersResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders", userID, label),
 		QueryValues:  queryValues,
@@ -65,7 +84,7 @@ func (cioLite CioLite) GetUserEmailAccountsFolders(userID string, label string,
 func (cioLite CioLite) GetUserEmailAccountFolder(userID string, label string, folder string, queryValues EmailAccountFolderDelimiterParam) (GetUsersEmailAccountFoldersResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s", userID, label, url.QueryEscape(folder)),
 		QueryValues:  queryValues,
@@ -88,7 +107,7 @@ func (cioLite CioLite) GetUserEmailAccountFolder(userID string, label string, fo
 func (cioLite CioLite) CreateUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (CreateEmailAccountFolderResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "POST",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s", userID, label, url.QueryEscape(folder)),
 		FormValues:   formValues,
@@ -105,19 +124,70 @@ func (cioLite CioLite) CreateUserEmailAccountFolder(userID string, label string,
 	return response, err
 }
 
+// RenameUserEmailAccountFolder renames (or moves) a folder on an email account.
+// Any sub-folders are moved along with it.
+// queryValues requires NewFolderID, and may optionally contain Delimiter
+func (cioLite CioLite) RenameUserEmailAccountFolder(userID string, label string, folder string, queryValues RenameUserEmailAccountFolderParams) (RenameEmailAccountFolderResponse, error) {
+
+	// Make request
+	request := ClientRequest{
+		Method:       "PUT",
+		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s", userID, label, url.QueryEscape(folder)),
+		QueryValues:  queryValues,
+		UserID:       userID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response RenameEmailAccountFolderResponse
+
+	// Request
+	err := cioLite.doFormRequest(request, &response)
+
+	return response, err
+}
+
+// DeleteUserEmailAccountFolder deletes a folder on an email account.
+// Most servers will refuse to delete a folder that still has sub-folders.
+// formValues may optionally contain Delimiter
+func (cioLite CioLite) DeleteUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (DeleteEmailAccountFolderResponse, error) {
+
+	// Make request
+	request := ClientRequest{
+		Method:       "DELETE",
+		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s", userID, label, url.QueryEscape(folder)),
+		FormValues:   formValues,
+		UserID:       userID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response DeleteEmailAccountFolderResponse
+
+	// Request
+	err := cioLite.doFormRequest(request, &response)
+
+	return response, err
+}
+
 // SafeCreateUserEmailAccountFolder will safely check if a folder exists, and create it if it does not.
 // This function returns a bool representing whether it had to create a folder, and any errors it received.
 // queryValues may optionally contain Delimiter
 func (cioLite CioLite) SafeCreateUserEmailAccountFolder(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (bool, error) {
+	return safeCreateUserEmailAccountFolder(cioLite, userID, label, folder, formValues)
+}
+
+// safeCreateUserEmailAccountFolder implements SafeCreateUserEmailAccountFolder with any client
+func safeCreateUserEmailAccountFolder(client Interface, userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) (bool, error) {
 
-	existsResponse, err := cioLite.GetUserEmailAccountFolder(userID, label, folder, formValues)
+	existsResponse, err := client.GetUserEmailAccountFolder(userID, label, folder, formValues)
 	if err == nil && existsResponse.Name == folder {
 		// It exists already, so return false and no error
 		return false, nil
 	}
 
 	// CIO seems to have issues Getting a single specific folder, and Posting a new folder always gives an error if it already exists, so try getting the folder list and see if it is there already
-	allFolders, err := cioLite.GetUserEmailAccountsFolders(userID, label, GetUserEmailAccountsFoldersParams{IncludeNamesOnly: true})
+	allFolders, err := client.GetUserEmailAccountsFolders(userID, label, GetUserEmailAccountsFoldersParams{IncludeNamesOnly: true})
 	if err == nil {
 		for _, singleFolder := range allFolders {
 			if singleFolder.Name == folder {
@@ -126,7 +196,7 @@ func (cioLite CioLite) SafeCreateUserEmailAccountFolder(userID string, label str
 		}
 	}
 
-	createResponse, err := cioLite.CreateUserEmailAccountFolder(userID, label, folder, formValues)
+	createResponse, err := client.CreateUserEmailAccountFolder(userID, label, folder, formValues)
 	if err != nil {
 		return true, err
 	}
@@ -15,12 +15,12 @@ import (
 type GetUserEmailAccountsFolderMessageParams struct {
 	// Optional:
 	Delimiter    string `json:"delimiter,omitempty"`
-	BodyType     string `json:"body_type,omitempty"`
+	BodyType     string `json:"body_type,omitempty" valid:"in(text/plain|text/html)"`
 	IncludeBody  bool   `json:"include_body,omitempty"`
 	IncludeFlags bool   `json:"include_flags,omitempty"`
 
 	// IncludeHeaders can be "0", "1", or "raw"
-	IncludeHeaders string `json:"include_headers,omitempty"`
+	IncludeHeaders string `json:"include_headers,omitempty" valid:"in(0|1|raw)"`
 
 	// Optional for GetUserEmailAccountsFolderMessages (not used by GetUserEmailAccountFolderMessage):
 	Limit  int `json:"limit,omitempty"`
@@ -44,12 +44,15 @@ type GetUsersEmailAccountFolderMessagesResponse struct {
 
 	PersonInfo PersonInfo `json:"person_info,omitempty"`
 
+	// Flags is only present if IncludeFlags was set
+	Flags UserEmailAccountsFolderMessageFlags `json:"flags,omitempty"`
+
 	Attachments []UsersEmailAccountFolderMessageAttachment `json:"attachments,omitempty"`
 
 	Bodies []UsersEmailAccountFolderMessageBody `json:"bodies,omitempty"`
 
-	SentAt     int `json:"sent_at,omitempty"`
-	ReceivedAt int `json:"received_at,omitempty"`
+	SentAt     UnixTime `json:"sent_at,omitempty"`
+	ReceivedAt UnixTime `json:"received_at,omitempty"`
 }
 
 // UsersEmailAccountFolderMessageAttachment embedded data struct within GetUsersEmailAccountFolderMessagesResponse
@@ -169,7 +172,7 @@ func (m *GetUsersEmailAccountFolderMessageAddresses) UnmarshalJSON(b []byte) err
 // Requires: NewFolderID, and may optionally contain Delimiter.
 type MoveUserEmailAccountFolderMessageParams struct {
 	// Required:
-	NewFolderID string `json:"new_folder_id"`
+	NewFolderID string `json:"new_folder_id" valid:"required"`
 	// Optional:
 	Delimiter string `json:"delimiter,omitempty"`
 }
@@ -185,7 +188,7 @@ type MoveUserEmailAccountFolderMessageResponse struct {
 func (cioLite CioLite) GetUserEmailAccountsFolderMessages(userID string, label string, folder string, queryValues GetUserEmailAccountsFolderMessageParams) ([]GetUsersEmailAccountFolderMessagesResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages", userID, label, url.QueryEscape(folder)),
 		QueryValues:  queryValues,
@@ -202,12 +205,36 @@ func (cioLite CioLite) GetUserEmailAccountsFolderMessages(userID string, label s
 	return response, err
 }
 
+// StreamUserEmailAccountsFolderMessages is GetUserEmailAccountsFolderMessages, but decodes the messages one at a time as they are received,
+// calling fn with each, so that only one message is held in memory instead of the whole listing.
+// An error returned by fn stops the stream, and is returned as is. Requests are not retried once fn has been called.
+func (cioLite CioLite) StreamUserEmailAccountsFolderMessages(userID string, label string, folder string, queryValues GetUserEmailAccountsFolderMessageParams, fn func(GetUsersEmailAccountFolderMessagesResponse) error) error {
+
+	// Make request
+	request := ClientRequest{
+		Method:       "GET",
+		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages", userID, label, url.QueryEscape(folder)),
+		QueryValues:  queryValues,
+		UserID:       userID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	stream := &streamResult{next: func() (interface{}, func() error) {
+		var message GetUsersEmailAccountFolderMessagesResponse
+		return &message, func() error { return fn(message) }
+	}}
+
+	// Request
+	return cioLite.doFormRequest(request, stream)
+}
+
 // GetUserEmailAccountFolderMessage gets file, contact and other information about a given email message.
 // queryValues may optionally contain Delimiter, IncludeBody, BodyType, IncludeHeaders, IncludeFlags
 func (cioLite CioLite) GetUserEmailAccountFolderMessage(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageParams) (GetUsersEmailAccountFolderMessagesResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages/%s", userID, label, url.QueryEscape(folder), url.QueryEscape(messageID)),
 		QueryValues:  queryValues,
@@ -229,7 +256,7 @@ func (cioLite CioLite) GetUserEmailAccountFolderMessage(userID string, label str
 func (cioLite CioLite) MoveUserEmailAccountFolderMessage(userID string, label string, folder string, messageID string, queryValues MoveUserEmailAccountFolderMessageParams) (MoveUserEmailAccountFolderMessageResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "PUT",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages/%s", userID, label, url.QueryEscape(folder), url.QueryEscape(messageID)),
 		QueryValues:  queryValues,
@@ -12,7 +12,7 @@ import (
 type GetUserEmailAccountsFolderMessageBodyParams struct {
 	// Optional:
 	Delimiter string `json:"delimiter,omitempty"`
-	Type      string `json:"type,omitempty"`
+	Type      string `json:"type,omitempty" valid:"in(text/plain|text/html)"`
 }
 
 // GetUserEmailAccountsFolderMessageBodyResponse data struct
@@ -28,7 +28,7 @@ type GetUserEmailAccountsFolderMessageBodyResponse struct {
 func (cioLite CioLite) GetUserEmailAccountsFolderMessageBody(userID string, label string, folder string, messageID string, queryValues GetUserEmailAccountsFolderMessageBodyParams) ([]GetUserEmailAccountsFolderMessageBodyResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages/%s/body", userID, label, url.QueryEscape(folder), url.QueryEscape(messageID)),
 		QueryValues:  queryValues,
@@ -20,6 +20,32 @@ type UserEmailAccountsFolderMessageFlags struct {
 	Answered bool `json:"answered,omitempty"`
 	Flagged  bool `json:"flagged,omitempty"`
 	Draft    bool `json:"draft,omitempty"`
+	Deleted  bool `json:"deleted,omitempty"`
+
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// ModifyUserEmailAccountsFolderMessageFlagsParams form values data struct.
+// Seen, Answered, Flagged, Deleted, and Draft can be "1" to set the flag, "0" to clear it,
+// or left empty to leave it unchanged. AddKeywords and RemoveKeywords are comma separated custom IMAP keywords.
+// Optional: Delimiter, Seen, Answered, Flagged, Deleted, Draft, AddKeywords, RemoveKeywords.
+type ModifyUserEmailAccountsFolderMessageFlagsParams struct {
+	// Optional:
+	Delimiter      string `json:"delimiter,omitempty"`
+	Seen           string `json:"seen,omitempty" valid:"in(0|1)"`
+	Answered       string `json:"answered,omitempty" valid:"in(0|1)"`
+	Flagged        string `json:"flagged,omitempty" valid:"in(0|1)"`
+	Deleted        string `json:"deleted,omitempty" valid:"in(0|1)"`
+	Draft          string `json:"draft,omitempty" valid:"in(0|1)"`
+	AddKeywords    string `json:"add_keywords,omitempty"`
+	RemoveKeywords string `json:"remove_keywords,omitempty"`
+}
+
+// ModifyUserEmailAccountsFolderMessageFlagsResponse data struct
+type ModifyUserEmailAccountsFolderMessageFlagsResponse struct {
+	Success bool `json:"success,omitempty"`
+
+	Flags UserEmailAccountsFolderMessageFlags `json:"flags,omitempty"`
 }
 
 // GetUserEmailAccountsFolderMessageFlags returns the message flags.
@@ -27,7 +53,7 @@ type UserEmailAccountsFolderMessageFlags struct {
 func (cioLite CioLite) GetUserEmailAccountsFolderMessageFlags(userID string, label string, folder string, messageID string, queryValues EmailAccountFolderDelimiterParam) (GetUserEmailAccountsFolderMessageFlagsResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages/%s/flags", userID, label, url.QueryEscape(folder), url.QueryEscape(messageID)),
 		QueryValues:  queryValues,
@@ -43,3 +69,37 @@ func (cioLite CioLite) GetUserEmailAccountsFolderMessageFlags(userID string, lab
 
 	return response, err
 }
+
+// ModifyUserEmailAccountsFolderMessageFlags sets and clears the message flags and keywords.
+// formValues may optionally contain Delimiter, Seen, Answered, Flagged, Deleted, Draft, AddKeywords, RemoveKeywords
+func (cioLite CioLite) ModifyUserEmailAccountsFolderMessageFlags(userID string, label string, folder string, messageID string, formValues ModifyUserEmailAccountsFolderMessageFlagsParams) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+
+	// Make request
+	request := ClientRequest{
+		Method:       "POST",
+		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages/%s/flags", userID, label, url.QueryEscape(folder), url.QueryEscape(messageID)),
+		FormValues:   formValues,
+		UserID:       userID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	var response ModifyUserEmailAccountsFolderMessageFlagsResponse
+
+	// Request
+	err := cioLite.doFormRequest(request, &response)
+
+	return response, err
+}
+
+// SetUserEmailAccountsFolderMessageFlag sets a single system flag (ex: FlagDeleted) or custom keyword on the message.
+// formValues may optionally contain Delimiter
+func (cioLite CioLite) SetUserEmailAccountsFolderMessageFlag(userID string, label string, folder string, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	return cioLite.ModifyUserEmailAccountsFolderMessageFlags(userID, label, folder, messageID, flag.modifyParams(true, formValues.Delimiter))
+}
+
+// ClearUserEmailAccountsFolderMessageFlag clears a single system flag (ex: FlagDeleted) or custom keyword from the message.
+// formValues may optionally contain Delimiter
+func (cioLite CioLite) ClearUserEmailAccountsFolderMessageFlag(userID string, label string, folder string, messageID string, flag MessageFlag, formValues EmailAccountFolderDelimiterParam) (ModifyUserEmailAccountsFolderMessageFlagsResponse, error) {
+	return cioLite.ModifyUserEmailAccountsFolderMessageFlags(userID, label, folder, messageID, flag.modifyParams(false, formValues.Delimiter))
+}
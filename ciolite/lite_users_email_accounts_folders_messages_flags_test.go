@@ -0,0 +1,58 @@
+package ciolite
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestSimulatedSetAndClearUserEmailAccountsFolderMessageFlag tests SetUserEmailAccountsFolderMessageFlag
+// and ClearUserEmailAccountsFolderMessageFlag with a simulated server
+func TestSimulatedSetAndClearUserEmailAccountsFolderMessageFlag(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var received []url.Values
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/<1@example.com>/flags", func(w http.ResponseWriter, r *http.Request) {
+		Must(r.ParseForm())
+		received = append(received, r.PostForm)
+		_, err := io.WriteString(w, `{"success": true, "flags": {"read": true, "deleted": true, "keywords": ["$Important"]}}`)
+		Must(err)
+	})
+
+	response, err := cioLite.SetUserEmailAccountsFolderMessageFlag("u1", "0", "INBOX", "<1@example.com>", FlagDeleted, EmailAccountFolderDelimiterParam{})
+	expectedFlags := MessageFlags{Seen: true, Deleted: true, Keywords: []string{"$Important"}}
+	if err != nil || !response.Success || !reflect.DeepEqual(response.Flags.MessageFlags(), expectedFlags) {
+		t.Error("Expected flags: ", expectedFlags, "; Got: ", response, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+
+	_, err = cioLite.ClearUserEmailAccountsFolderMessageFlag("u1", "0", "INBOX", "<1@example.com>", MessageFlag("$Important"), EmailAccountFolderDelimiterParam{})
+	expectedForms := []url.Values{
+		{"deleted": []string{"1"}},
+		{"remove_keywords": []string{"$Important"}},
+	}
+	if err != nil || !reflect.DeepEqual(received, expectedForms) {
+		t.Error("Expected forms: ", expectedForms, "; Got: ", received, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+}
+
+// TestMessageFlags tests converting to and comparing MessageFlags
+func TestMessageFlags(t *testing.T) {
+	t.Parallel()
+
+	fromFolder := UserEmailAccountsFolderMessageFlags{Read: true, Flagged: true}.MessageFlags()
+	fromWebhook := WebhookMessageDataFlags{Seen: true, Flagged: true}.MessageFlags()
+	if !fromFolder.Equal(fromWebhook) || !fromFolder.Has(FlagSeen) || fromFolder.Has(FlagDraft) {
+		t.Error("Expected equal flags with Seen and Flagged; Got: ", fromFolder, fromWebhook)
+	}
+
+	flags := MessageFlags{Answered: true, Keywords: []string{"b", "A"}}
+	expected := []MessageFlag{FlagAnswered, "A", "b"}
+	if list := flags.List(); !reflect.DeepEqual(list, expected) || !flags.Has("a") || !MessageFlag("a").IsKeyword() || FlagDeleted.IsKeyword() {
+		t.Error("Expected: ", expected, "; Got: ", list)
+	}
+}
@@ -3,6 +3,7 @@ package ciolite
 // Api functions that support: users/email_accounts/folders/messages/raw
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 )
@@ -10,12 +11,21 @@ import (
 // GetUserEmailAccountsFolderMessageRawResponse data struct
 type GetUserEmailAccountsFolderMessageRawResponse string
 
+// unmarshalRaw sets the raw message from a successful response (see rawResponse),
+// which is either a json string, or the RFC-822 text itself
+func (raw *GetUserEmailAccountsFolderMessageRawResponse) unmarshalRaw(body []byte) error {
+	if err := json.Unmarshal(body, (*string)(raw)); err != nil {
+		*raw = GetUserEmailAccountsFolderMessageRawResponse(body)
+	}
+	return nil
+}
+
 // GetUserEmailAccountsFolderMessageRaw fetches the raw RFC-822 message text of a given email.
 // queryValues may optionally contain Delimiter
 func (cioLite CioLite) GetUserEmailAccountsFolderMessageRaw(userID string, label string, folder string, messageID string, queryValues EmailAccountFolderDelimiterParam) (GetUserEmailAccountsFolderMessageRawResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/folders/%s/messages/%s/raw", userID, label, url.QueryEscape(folder), url.QueryEscape(messageID)),
 		QueryValues:  queryValues,
@@ -0,0 +1,54 @@
+package ciolite
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestSimulatedGetUserEmailAccountsFolderMessageRaw tests GetUserEmailAccountsFolderMessageRaw with a simulated server
+func TestSimulatedGetUserEmailAccountsFolderMessageRaw(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	rfc822 := "From: a@example.com\r\nSubject: Hello\r\n\r\nBody\r\n"
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/text/raw", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, rfc822)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/json/raw", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `"From: a@example.com\r\nSubject: Hello\r\n\r\nBody\r\n"`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/missing/raw", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := io.WriteString(w, `{"type": "error", "value": "Message not found"}`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/down/raw", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, err := io.WriteString(w, "Bad Gateway")
+		Must(err)
+	})
+
+	// The RFC-822 text itself, or as a json string
+	for _, messageID := range []string{"text", "json"} {
+		raw, err := cioLite.GetUserEmailAccountsFolderMessageRaw("u1", "0", "INBOX", messageID, EmailAccountFolderDelimiterParam{})
+		if err != nil || string(raw) != rfc822 {
+			t.Error("Expected the raw message for ", messageID, "; Got: ", raw, "; With Error: ", err, "; With Log: ", logger.String())
+		}
+	}
+
+	// Error responses are not returned as the raw message
+	raw, err := cioLite.GetUserEmailAccountsFolderMessageRaw("u1", "0", "INBOX", "missing", EmailAccountFolderDelimiterParam{})
+	if ErrorStatusCode(err) != 404 || !strings.Contains(ErrorPayload(err), "Message not found") || len(raw) > 0 {
+		t.Error("Expected a 404 without a raw message; Got: ", raw, "; With Error: ", err)
+	}
+	raw, err = cioLite.GetUserEmailAccountsFolderMessageRaw("u1", "0", "INBOX", "down", EmailAccountFolderDelimiterParam{})
+	if ErrorStatusCode(err) != 502 || len(raw) > 0 {
+		t.Error("Expected a 502 without a raw message; Got: ", raw, "; With Error: ", err)
+	}
+}
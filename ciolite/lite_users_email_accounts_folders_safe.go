@@ -0,0 +1,231 @@
+package ciolite
+
+// Recursive and idempotent helpers for: users/email_accounts/folders
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FolderOperationResult is the outcome of a folder operation on a single folder path
+type FolderOperationResult struct {
+	Path string
+
+	// Changed is false if nothing had to be done, because the folder was already in the desired state
+	Changed bool
+
+	Err error
+}
+
+// SafeCreateAllUserEmailAccountFolders creates a folder along with any missing parents, similar to os.MkdirAll.
+// Folders that already exist are left alone, so calling this more than once is safe.
+// The folder is split on formValues.Delimiter if set, otherwise on the email account's own delimiter.
+// It returns a result for every folder in the path, stopping at the first folder that could not be created.
+func (cioLite CioLite) SafeCreateAllUserEmailAccountFolders(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	return safeCreateAllUserEmailAccountFolders(cioLite, userID, label, folder, formValues)
+}
+
+// safeCreateAllUserEmailAccountFolders implements SafeCreateAllUserEmailAccountFolders with any client
+func safeCreateAllUserEmailAccountFolders(client Interface, userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+
+	folders, err := client.GetUserEmailAccountsFolders(userID, label, GetUserEmailAccountsFoldersParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	return createMissingFolders(client, userID, label, splitFolderPath(folder, folders, formValues), folders, formValues)
+}
+
+// SafeRenameUserEmailAccountFolder renames a folder, first creating any missing parents of the new folder name.
+// If the folder no longer exists but the new folder does, it is treated as already renamed.
+// It returns a result for every parent folder of the new name, followed by the result of the rename itself.
+func (cioLite CioLite) SafeRenameUserEmailAccountFolder(userID string, label string, folder string, newFolder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	return safeRenameUserEmailAccountFolder(cioLite, userID, label, folder, newFolder, formValues)
+}
+
+// safeRenameUserEmailAccountFolder implements SafeRenameUserEmailAccountFolder with any client
+func safeRenameUserEmailAccountFolder(client Interface, userID string, label string, folder string, newFolder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+
+	folders, err := client.GetUserEmailAccountsFolders(userID, label, GetUserEmailAccountsFoldersParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	oldPath := splitFolderPath(folder, folders, formValues)
+	newPath := splitFolderPath(newFolder, folders, formValues)
+	tree := NewFolderTree(folders)
+
+	if node := tree.Find(oldPath.accountName(len(oldPath.segments))); node == nil || !node.Exists() {
+		result := FolderOperationResult{Path: folder}
+		if newNode := tree.Find(newPath.accountName(len(newPath.segments))); newNode == nil || !newNode.Exists() {
+			result.Err = errors.Errorf("Unable to rename folder %s. Neither it nor %s exist", folder, newFolder)
+		}
+		return []FolderOperationResult{result}, result.Err
+	}
+
+	// Make sure the new parent folder exists
+	var results []FolderOperationResult
+	if len(newPath.segments) > 1 {
+		newPath.segments = newPath.segments[:len(newPath.segments)-1]
+		results, err = createMissingFolders(client, userID, label, newPath, folders, formValues)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	result := FolderOperationResult{Path: folder, Changed: true}
+	renameResponse, err := client.RenameUserEmailAccountFolder(userID, label, folder, RenameUserEmailAccountFolderParams{
+		NewFolderID: newFolder,
+		Delimiter:   formValues.Delimiter,
+	})
+	if err == nil && !renameResponse.Success {
+		err = errors.New("Unable to rename folder. CIO returned 200 but with Success=false")
+	}
+	result.Err = err
+
+	return append(results, result), err
+}
+
+// SafeDeleteAllUserEmailAccountFolders deletes a folder along with all of its sub-folders, similar to os.RemoveAll.
+// Sub-folders are deleted before their parents, and a folder that does not exist is treated as already deleted.
+// It returns a result for every folder deleted, stopping at the first folder that could not be deleted.
+func (cioLite CioLite) SafeDeleteAllUserEmailAccountFolders(userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+	return safeDeleteAllUserEmailAccountFolders(cioLite, userID, label, folder, formValues)
+}
+
+// safeDeleteAllUserEmailAccountFolders implements SafeDeleteAllUserEmailAccountFolders with any client
+func safeDeleteAllUserEmailAccountFolders(client Interface, userID string, label string, folder string, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+
+	folders, err := client.GetUserEmailAccountsFolders(userID, label, GetUserEmailAccountsFoldersParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	path := splitFolderPath(folder, folders, formValues)
+	root := NewFolderTree(folders).Find(path.accountName(len(path.segments)))
+	if root == nil {
+		return []FolderOperationResult{{Path: folder}}, nil
+	}
+
+	// Collect the folders parents first, then delete them in reverse
+	var nodes []*FolderNode
+	_ = root.walk(func(node *FolderNode, depth int) error {
+		if node.Exists() {
+			nodes = append(nodes, node)
+		}
+		return nil
+	}, 0)
+
+	results := make([]FolderOperationResult, 0, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		result := FolderOperationResult{Path: nodes[i].Path, Changed: true}
+		// The path is in the account's delimiter, which CIO has to be told about as it otherwise assumes "/"
+		delimiter := nodes[i].Delimiter
+		if len(delimiter) == 0 {
+			delimiter = path.accountDelimiter
+		}
+		deleteResponse, err := client.DeleteUserEmailAccountFolder(userID, label, nodes[i].Path, EmailAccountFolderDelimiterParam{Delimiter: delimiter})
+		if err == nil && !deleteResponse.Success {
+			err = errors.New("Unable to delete folder. CIO returned 200 but with Success=false")
+		}
+		result.Err = err
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// folderPath is a folder name split into its segments
+type folderPath struct {
+	segments []string
+
+	// delimiter is the delimiter the caller used, and accountDelimiter is the one CIO returns folder names with
+	delimiter        string
+	accountDelimiter string
+}
+
+// name returns the folder name of the first n segments, as the caller would name it
+func (path folderPath) name(n int) string {
+	return strings.Join(path.segments[:n], path.delimiter)
+}
+
+// accountName returns the folder name of the first n segments, as CIO returns it in the folder list
+func (path folderPath) accountName(n int) string {
+	return strings.Join(path.segments[:n], path.accountDelimiter)
+}
+
+// splitFolderPath splits the folder name on formValues.Delimiter if set, or else the delimiter of the email account
+func splitFolderPath(folder string, folders []GetUsersEmailAccountFoldersResponse, formValues EmailAccountFolderDelimiterParam) folderPath {
+
+	path := folderPath{accountDelimiter: "/"}
+	for _, f := range folders {
+		if len(f.Delimiter) > 0 {
+			path.accountDelimiter = f.Delimiter
+			if strings.EqualFold(f.Name, "INBOX") {
+				break
+			}
+		}
+	}
+
+	path.delimiter = formValues.Delimiter
+	if len(path.delimiter) == 0 {
+		path.delimiter = path.accountDelimiter
+	}
+
+	for _, segment := range strings.Split(folder, path.delimiter) {
+		if len(segment) > 0 {
+			path.segments = append(path.segments, segment)
+		}
+	}
+	return path
+}
+
+// createMissingFolders creates every folder along the path that is not already in the folder list
+func createMissingFolders(client Interface, userID string, label string, path folderPath, folders []GetUsersEmailAccountFoldersResponse, formValues EmailAccountFolderDelimiterParam) ([]FolderOperationResult, error) {
+
+	exists := make(map[string]bool, len(folders))
+	for _, f := range folders {
+		exists[f.Name] = true
+	}
+
+	results := make([]FolderOperationResult, 0, len(path.segments))
+	for i := 1; i <= len(path.segments); i++ {
+		result := FolderOperationResult{Path: path.name(i)}
+		if !exists[path.accountName(i)] {
+			result.Changed = true
+			createResponse, err := client.CreateUserEmailAccountFolder(userID, label, result.Path, formValues)
+			if err == nil && !createResponse.Success {
+				err = errors.New("Unable to create folder. CIO returned 200 but with Success=false")
+			}
+			// CIO gives an error if the folder already exists, ex: if it was just created concurrently, so check again
+			if err != nil && folderExists(client, userID, label, path.accountName(i)) {
+				result.Changed, err = false, nil
+			}
+			result.Err = err
+		}
+		results = append(results, result)
+		if result.Err != nil {
+			return results, result.Err
+		}
+	}
+
+	return results, nil
+}
+
+// folderExists returns true if the folder is in the folder list of the email account
+func folderExists(client Interface, userID string, label string, accountName string) bool {
+	folders, err := client.GetUserEmailAccountsFolders(userID, label, GetUserEmailAccountsFoldersParams{IncludeNamesOnly: true})
+	if err != nil {
+		return false
+	}
+	for _, f := range folders {
+		if f.Name == accountName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package ciolite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestSimulatedSafeCreateAndDeleteAllUserEmailAccountFolders tests SafeCreateAllUserEmailAccountFolders
+// and SafeDeleteAllUserEmailAccountFolders with a simulated server
+func TestSimulatedSafeCreateAndDeleteAllUserEmailAccountFolders(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var (
+		mu         sync.Mutex
+		delimiters []string
+	)
+	folders := map[string]bool{"INBOX": true, "Work": true}
+
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var response []GetUsersEmailAccountFoldersResponse
+		for name := range folders {
+			response = append(response, GetUsersEmailAccountFoldersResponse{Name: name, Delimiter: "."})
+		}
+		Must(json.NewEncoder(w).Encode(response))
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.TrimPrefix(r.URL.Path, "/lite/users/u1/email_accounts/0/folders/")
+		switch r.Method {
+		case "POST":
+			folders[name] = true
+			// Created concurrently by someone else
+			if name == "Work.Race" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte(`{"type": "error", "value": "Folder already exists"}`))
+				Must(err)
+				return
+			}
+		case "DELETE":
+			body, err := ioutil.ReadAll(r.Body)
+			Must(err)
+			values, err := url.ParseQuery(string(body))
+			Must(err)
+			delimiters = append(delimiters, values.Get("delimiter"))
+			delete(folders, name)
+		}
+		Must(json.NewEncoder(w).Encode(map[string]bool{"success": true}))
+	})
+
+	// Create using the account delimiter
+	results, err := cioLite.SafeCreateAllUserEmailAccountFolders("u1", "0", "Work.Projects.Alpha", EmailAccountFolderDelimiterParam{})
+	expected := []FolderOperationResult{
+		{Path: "Work"},
+		{Path: "Work.Projects", Changed: true},
+		{Path: "Work.Projects.Alpha", Changed: true},
+	}
+	if err != nil || !reflect.DeepEqual(results, expected) {
+		t.Error("Expected: ", expected, "; Got: ", results, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+
+	// Creating again changes nothing
+	results, err = cioLite.SafeCreateAllUserEmailAccountFolders("u1", "0", "Work.Projects.Alpha", EmailAccountFolderDelimiterParam{})
+	if err != nil || len(results) != 3 || results[1].Changed || results[2].Changed {
+		t.Error("Expected no changes; Got: ", results, "; With Error: ", err)
+	}
+
+	// A folder that already exists when creating it is not a failure
+	results, err = cioLite.SafeCreateAllUserEmailAccountFolders("u1", "0", "Work.Race", EmailAccountFolderDelimiterParam{})
+	if err != nil || !reflect.DeepEqual(results, []FolderOperationResult{{Path: "Work"}, {Path: "Work.Race"}}) {
+		t.Error("Expected the concurrently created folder to be left alone; Got: ", results, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+
+	// Delete the whole Work tree, children first, telling CIO the account's delimiter
+	results, err = cioLite.SafeDeleteAllUserEmailAccountFolders("u1", "0", "Work", EmailAccountFolderDelimiterParam{})
+	expected = []FolderOperationResult{
+		{Path: "Work.Race", Changed: true},
+		{Path: "Work.Projects.Alpha", Changed: true},
+		{Path: "Work.Projects", Changed: true},
+		{Path: "Work", Changed: true},
+	}
+	var remaining []string
+	for name := range folders {
+		remaining = append(remaining, name)
+	}
+	sort.Strings(remaining)
+	if err != nil || !reflect.DeepEqual(results, expected) || !reflect.DeepEqual(remaining, []string{"INBOX"}) {
+		t.Error("Expected: ", expected, "; Got: ", results, "; With Remaining: ", remaining, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+	if !reflect.DeepEqual(delimiters, []string{".", ".", ".", "."}) {
+		t.Error("Expected every delete to send the account delimiter; Got: ", delimiters)
+	}
+
+	// Deleting again changes nothing
+	results, err = cioLite.SafeDeleteAllUserEmailAccountFolders("u1", "0", "Work", EmailAccountFolderDelimiterParam{})
+	if err != nil || !reflect.DeepEqual(results, []FolderOperationResult{{Path: "Work"}}) {
+		t.Error("Expected no changes; Got: ", results, "; With Error: ", err)
+	}
+}
+
+// TestSimulatedSafeRenameUserEmailAccountFolder tests SafeRenameUserEmailAccountFolder with a simulated server
+func TestSimulatedSafeRenameUserEmailAccountFolder(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var (
+		mu      sync.Mutex
+		renames []string
+	)
+	folders := map[string]bool{"INBOX": true, "Work": true}
+
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var response []GetUsersEmailAccountFoldersResponse
+		for name := range folders {
+			response = append(response, GetUsersEmailAccountFoldersResponse{Name: name, Delimiter: "/"})
+		}
+		Must(json.NewEncoder(w).Encode(response))
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.TrimPrefix(r.URL.Path, "/lite/users/u1/email_accounts/0/folders/")
+		switch r.Method {
+		case "POST":
+			folders[name] = true
+		case "PUT":
+			newName := r.URL.Query().Get("new_folder_id")
+			renames = append(renames, name+" -> "+newName)
+			delete(folders, name)
+			folders[newName] = true
+		}
+		Must(json.NewEncoder(w).Encode(map[string]bool{"success": true}))
+	})
+
+	// The missing parents of the new name are created first, then the folder is renamed
+	results, err := cioLite.SafeRenameUserEmailAccountFolder("u1", "0", "Work", "Archive/2020/Work", EmailAccountFolderDelimiterParam{})
+	expected := []FolderOperationResult{
+		{Path: "Archive", Changed: true},
+		{Path: "Archive/2020", Changed: true},
+		{Path: "Work", Changed: true},
+	}
+	if err != nil || !reflect.DeepEqual(results, expected) {
+		t.Error("Expected: ", expected, "; Got: ", results, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+	mu.Lock()
+	if !reflect.DeepEqual(renames, []string{"Work -> Archive/2020/Work"}) || folders["Work"] || !folders["Archive/2020/Work"] {
+		t.Error("Expected a single PUT with new_folder_id; Got: ", renames, "; With Folders: ", folders)
+	}
+	mu.Unlock()
+
+	// Renaming again is treated as already renamed
+	results, err = cioLite.SafeRenameUserEmailAccountFolder("u1", "0", "Work", "Archive/2020/Work", EmailAccountFolderDelimiterParam{})
+	if err != nil || !reflect.DeepEqual(results, []FolderOperationResult{{Path: "Work"}}) {
+		t.Error("Expected no changes; Got: ", results, "; With Error: ", err)
+	}
+	mu.Lock()
+	if len(renames) != 1 {
+		t.Error("Expected no other PUT; Got: ", renames)
+	}
+	mu.Unlock()
+
+	// Neither folder exists
+	if _, err = cioLite.SafeRenameUserEmailAccountFolder("u1", "0", "Missing", "Other", EmailAccountFolderDelimiterParam{}); err == nil {
+		t.Error("Expected an error when neither folder exists")
+	}
+}
@@ -15,12 +15,12 @@ import (
 type GetUserEmailAccountsMessageParams struct {
 	// Optional:
 	Delimiter    string `json:"delimiter,omitempty"`
-	BodyType     string `json:"body_type,omitempty"`
+	BodyType     string `json:"body_type,omitempty" valid:"in(text/plain|text/html)"`
 	IncludeBody  bool   `json:"include_body,omitempty"`
 	IncludeFlags bool   `json:"include_flags,omitempty"`
 
 	// IncludeHeaders can be "0", "1", or "raw"
-	IncludeHeaders string `json:"include_headers,omitempty"`
+	IncludeHeaders string `json:"include_headers,omitempty" valid:"in(0|1|raw)"`
 
 	// Optional for GetUserEmailAccountsMessages (not used by GetUserEmailAccountMessage):
 	Limit  int `json:"limit,omitempty"`
@@ -44,12 +44,15 @@ type GetUsersEmailAccountMessagesResponse struct {
 
 	PersonInfo PersonInfo `json:"person_info,omitempty"`
 
+	// Flags is only present if IncludeFlags was set
+	Flags UserEmailAccountsFolderMessageFlags `json:"flags,omitempty"`
+
 	Attachments []UsersEmailAccountMessageAttachment `json:"attachments,omitempty"`
 
 	Bodies []UsersEmailAccountMessageBody `json:"bodies,omitempty"`
 
-	SentAt     int `json:"sent_at,omitempty"`
-	ReceivedAt int `json:"received_at,omitempty"`
+	SentAt     UnixTime `json:"sent_at,omitempty"`
+	ReceivedAt UnixTime `json:"received_at,omitempty"`
 }
 
 // UsersEmailAccountMessageAttachment embedded data struct within GetUsersEmailAccountMessagesResponse
@@ -109,7 +112,7 @@ func (m *GetUsersEmailAccountMessageAddresses) UnmarshalJSON(b []byte) error {
 func (cioLite CioLite) GetUserEmailAccountsMessages(userID string, label string, queryValues GetUserEmailAccountsMessageParams) ([]GetUsersEmailAccountMessagesResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/messages", userID, label),
 		QueryValues:  queryValues,
@@ -126,12 +129,36 @@ func (cioLite CioLite) GetUserEmailAccountsMessages(userID string, label string,
 	return response, err
 }
 
+// StreamUserEmailAccountsMessages is GetUserEmailAccountsMessages, but decodes the messages one at a time as they are received,
+// calling fn with each, so that only one message is held in memory instead of the whole listing.
+// An error returned by fn stops the stream, and is returned as is. Requests are not retried once fn has been called.
+func (cioLite CioLite) StreamUserEmailAccountsMessages(userID string, label string, queryValues GetUserEmailAccountsMessageParams, fn func(GetUsersEmailAccountMessagesResponse) error) error {
+
+	// Make request
+	request := ClientRequest{
+		Method:       "GET",
+		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/messages", userID, label),
+		QueryValues:  queryValues,
+		UserID:       userID,
+		AccountLabel: label,
+	}
+
+	// Make response
+	stream := &streamResult{next: func() (interface{}, func() error) {
+		var message GetUsersEmailAccountMessagesResponse
+		return &message, func() error { return fn(message) }
+	}}
+
+	// Request
+	return cioLite.doFormRequest(request, stream)
+}
+
 // GetUserEmailAccountMessage gets file, contact and other information about a given email message.
 // queryValues may optionally contain Delimiter, IncludeBody, BodyType, IncludeHeaders, IncludeFlags
 func (cioLite CioLite) GetUserEmailAccountMessage(userID string, label string, messageID string, queryValues GetUserEmailAccountsMessageParams) (GetUsersEmailAccountMessagesResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:       "GET",
 		Path:         fmt.Sprintf("/lite/users/%s/email_accounts/%s/messages/%s", userID, label, url.QueryEscape(messageID)),
 		QueryValues:  queryValues,
@@ -42,7 +42,7 @@ type GetUsersWebhooksResponse struct {
 // FilterFromDomain, IncludeBody, BodyType
 type CreateUserWebhookParams struct {
 	// Requires:
-	CallbackURL string `json:"callback_url"`
+	CallbackURL string `json:"callback_url" valid:"required,url"`
 
 	// Optional:
 	FilterTo           string `json:"filter_to,omitempty"`
@@ -55,7 +55,7 @@ type CreateUserWebhookParams struct {
 	FilterFolderAdded  string `json:"filter_folder_added,omitempty"`
 	FilterToDomain     string `json:"filter_to_domain,omitempty"`
 	FilterFromDomain   string `json:"filter_from_domain,omitempty"`
-	BodyType           string `json:"body_type,omitempty"`
+	BodyType           string `json:"body_type,omitempty" valid:"in(text/plain|text/html)"`
 	IncludeBody        bool   `json:"include_body,omitempty"`
 	IncludeHeader      bool   `json:"include_header,omitempty"`
 	ReceiveDrafts      bool   `json:"receive_drafts,omitempty"`
@@ -113,8 +113,8 @@ type WebhookMessageData struct {
 	References []string `json:"references,omitempty"`
 	Folders    []string `json:"folders,omitempty"`
 
-	Date         int `json:"date,omitempty"`
-	DateReceived int `json:"date_received,omitempty"`
+	Date         UnixTime `json:"date,omitempty"`
+	DateReceived UnixTime `json:"date_received,omitempty"`
 
 	Addresses WebhookMessageDataAddresses `json:"addresses,omitempty"`
 
@@ -209,7 +209,7 @@ func (m *WebhookMessageDataAddresses) UnmarshalJSON(b []byte) error {
 func (cioLite CioLite) GetUserWebhooks(userID string) ([]GetUsersWebhooksResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/users/%s/webhooks", userID),
 		UserID: userID,
@@ -228,7 +228,7 @@ func (cioLite CioLite) GetUserWebhooks(userID string) ([]GetUsersWebhooksRespons
 func (cioLite CioLite) GetUserWebhook(userID string, webhookID string) (GetUsersWebhooksResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/users/%s/webhooks/%s", userID, webhookID),
 		UserID: userID,
@@ -251,7 +251,7 @@ func (cioLite CioLite) GetUserWebhook(userID string, webhookID string) (GetUsers
 func (cioLite CioLite) CreateUserWebhook(userID string, formValues CreateUserWebhookParams) (CreateUserWebhookResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       fmt.Sprintf("/lite/users/%s/webhooks", userID),
 		FormValues: formValues,
@@ -272,7 +272,7 @@ func (cioLite CioLite) CreateUserWebhook(userID string, formValues CreateUserWeb
 func (cioLite CioLite) ModifyUserWebhook(userID string, webhookID string, formValues ModifyUserWebhookParams) (ModifyWebhookResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       fmt.Sprintf("/lite/users/%s/webhooks/%s", userID, webhookID),
 		FormValues: formValues,
@@ -292,7 +292,7 @@ func (cioLite CioLite) ModifyUserWebhook(userID string, webhookID string, formVa
 func (cioLite CioLite) DeleteUserWebhookAccount(userID string, webhookID string) (DeleteWebhookResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/lite/users/%s/webhooks/%s", userID, webhookID),
 		UserID: userID,
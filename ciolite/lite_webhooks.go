@@ -10,7 +10,7 @@ import (
 func (cioLite CioLite) GetWebhooks() ([]GetUsersWebhooksResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   "/lite/webhooks",
 	}
@@ -28,7 +28,7 @@ func (cioLite CioLite) GetWebhooks() ([]GetUsersWebhooksResponse, error) {
 func (cioLite CioLite) GetWebhook(webhookID string) (GetUsersWebhooksResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/lite/webhooks/%s", webhookID),
 	}
@@ -50,7 +50,7 @@ func (cioLite CioLite) GetWebhook(webhookID string) (GetUsersWebhooksResponse, e
 func (cioLite CioLite) CreateWebhook(formValues CreateUserWebhookParams) (CreateUserWebhookResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       "/lite/webhooks",
 		FormValues: formValues,
@@ -70,7 +70,7 @@ func (cioLite CioLite) CreateWebhook(formValues CreateUserWebhookParams) (Create
 func (cioLite CioLite) ModifyWebhook(webhookID string, formValues ModifyUserWebhookParams) (ModifyWebhookResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method:     "POST",
 		Path:       fmt.Sprintf("/lite/webhooks/%s", webhookID),
 		FormValues: formValues,
@@ -89,7 +89,7 @@ func (cioLite CioLite) ModifyWebhook(webhookID string, formValues ModifyUserWebh
 func (cioLite CioLite) DeleteWebhookAccount(webhookID string) (DeleteWebhookResponse, error) {
 
 	// Make request
-	request := clientRequest{
+	request := ClientRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/lite/webhooks/%s", webhookID),
 	}
@@ -0,0 +1,499 @@
+// Package mailsync mirrors the messages of an email account, emitting events for added, removed,
+// and re-flagged messages, and checkpointing its progress so that it can resume after a crash.
+//
+// Once a folder has been fully listed, later syncs only list the messages received since its checkpoint
+// (newest first). Every message is only listed again if the folder's message count no longer matches
+// (ex: messages were removed, or moved in from another folder), or once FullPassInterval has passed.
+// A message missing from a full listing is only reported removed once CIO returns a 404 for it,
+// as offsets shift when messages are removed during a listing.
+//
+// Events are delivered at least once: a page is only added to the checkpoint (as a Delta) after the Handler
+// has accepted every event for it, so a crash (or a Handler error) can repeat the events of the last page.
+package mailsync
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/contextio/contextio-go/ciolite"
+	"github.com/pkg/errors"
+)
+
+// EventType is the kind of change an Event describes
+type EventType string
+
+// EventType values
+const (
+	EventAdded        EventType = "added"
+	EventRemoved      EventType = "removed"
+	EventFlagsChanged EventType = "flags_changed"
+)
+
+// EventSource is where the change was noticed
+type EventSource string
+
+// EventSource values
+const (
+	SourceSync    EventSource = "sync"
+	SourceWebhook EventSource = "webhook"
+)
+
+// Event is a single change to a folder
+type Event struct {
+	Type   EventType
+	Source EventSource
+
+	UserID    string
+	Label     string
+	Folder    string
+	MessageID string
+
+	// Message is nil for EventRemoved
+	Message *ciolite.Message
+
+	// Flags are the current flags, and PreviousFlags the flags before this change
+	// (PreviousFlags is empty for EventAdded, and Flags is empty for EventRemoved)
+	Flags         ciolite.MessageFlags
+	PreviousFlags ciolite.MessageFlags
+}
+
+// Handler receives every Event. Returning an error stops the sync, without saving the current page.
+type Handler func(event Event) error
+
+// Options configure a Syncer. The zero value keeps checkpoints in memory, and discards events.
+type Options struct {
+	// Store keeps the checkpoints, defaults to a new MemoryStore
+	Store Store
+
+	// Handler receives every Event
+	Handler Handler
+
+	// PageSize is how many messages are requested at a time, defaults to 100
+	PageSize int
+
+	// Folders, if set, limits syncing (and webhook merging) to these folders
+	Folders []string
+
+	// Delimiter is used by SyncFolder and MergeWebhook; Sync uses the delimiter of each folder
+	Delimiter string
+
+	// FullPassInterval, if set, lists every message of a folder again once this long has passed since its last full pass.
+	// Otherwise only new messages are listed while the folder's count matches, so flag changes of older messages
+	// are only noticed through webhooks.
+	FullPassInterval time.Duration
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// FolderResult is the outcome of syncing a single folder
+type FolderResult struct {
+	Folder string
+
+	Added        int
+	Removed      int
+	FlagsChanged int
+
+	// Incremental is true if only the messages received since the last checkpoint were listed
+	Incremental bool
+
+	// Resumed is true if an interrupted pass was continued
+	Resumed bool
+}
+
+// Syncer syncs the folders of a single email account.
+// It is safe for concurrent use: Sync, SyncFolder, and MergeWebhook are serialized.
+type Syncer struct {
+	client  ciolite.Interface
+	userID  string
+	label   string
+	options Options
+
+	mu sync.Mutex
+}
+
+// NewSyncer returns a Syncer for the email account
+func NewSyncer(client ciolite.Interface, userID string, label string, options Options) *Syncer {
+	if options.Store == nil {
+		options.Store = NewMemoryStore()
+	}
+	if options.PageSize <= 0 {
+		options.PageSize = 100
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	return &Syncer{client: client, userID: userID, label: label, options: options}
+}
+
+// Sync syncs every folder of the email account (or only Options.Folders), in order.
+// It stops at the first error, returning the results of the folders synced so far.
+func (syncer *Syncer) Sync() ([]FolderResult, error) {
+
+	folders, err := syncer.client.GetUserEmailAccountsFolders(syncer.userID, syncer.label, ciolite.GetUserEmailAccountsFoldersParams{})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list folders")
+	}
+
+	var results []FolderResult
+	for _, folder := range folders {
+		if !syncer.includes(folder.Name) {
+			continue
+		}
+		result, err := syncer.syncFolder(folder.Name, folder.Delimiter, folder.NbMessages)
+		results = append(results, result)
+		if err != nil {
+			return results, errors.Wrapf(err, "Unable to sync folder %s", folder.Name)
+		}
+	}
+	return results, nil
+}
+
+// SyncFolder syncs a single folder
+func (syncer *Syncer) SyncFolder(folder string) (FolderResult, error) {
+
+	response, err := syncer.client.GetUserEmailAccountFolder(syncer.userID, syncer.label, folder,
+		ciolite.EmailAccountFolderDelimiterParam{Delimiter: syncer.options.Delimiter})
+	if err != nil {
+		return FolderResult{Folder: folder}, errors.Wrapf(err, "Unable to get folder %s", folder)
+	}
+
+	return syncer.syncFolder(folder, syncer.options.Delimiter, response.NbMessages)
+}
+
+// syncFolder lists the new messages of the folder, or every message if the folder's count no longer matches,
+// resuming an interrupted full pass if the folder's count has not changed
+func (syncer *Syncer) syncFolder(folder string, delimiter string, count int) (FolderResult, error) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	result := FolderResult{Folder: folder}
+
+	checkpoint, err := syncer.load(folder)
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case checkpoint.Pass != nil && checkpoint.Pass.MessageCount == count:
+		result.Resumed = true
+	case checkpoint.Pass == nil && checkpoint.CompletedAt != 0 && !syncer.fullPassDue(checkpoint):
+		if err = syncer.listNewMessages(checkpoint, folder, delimiter, &result); err != nil {
+			return result, err
+		}
+		if len(checkpoint.Messages) == count {
+			result.Incremental = true
+			return result, nil
+		}
+		// Messages were removed, or moved in with an older ReceivedAt, so every message has to be listed
+		fallthrough
+	default:
+		// Offsets are not stable once the count has changed, so an interrupted pass starts over.
+		// Messages already applied are in the checkpoint, so their events are not repeated.
+		checkpoint.Pass = &Pass{MessageCount: count, Seen: map[string]bool{}}
+		if err = syncer.options.Store.Save(*checkpoint); err != nil {
+			return result, errors.Wrap(err, "Unable to save checkpoint")
+		}
+	}
+
+	if err = syncer.listAllMessages(checkpoint, folder, delimiter, &result); err != nil {
+		return result, err
+	}
+
+	// Messages that were not listed may only have been shifted past a page by a concurrent removal,
+	// so each is only removed once CIO confirms that it is gone
+	var missing []string
+	for id := range checkpoint.Messages {
+		if !checkpoint.Pass.Seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	for _, id := range missing {
+		response, err := syncer.client.GetUserEmailAccountFolderMessage(syncer.userID, syncer.label, folder, id,
+			ciolite.GetUserEmailAccountsFolderMessageParams{Delimiter: delimiter, IncludeFlags: true})
+		if err == nil {
+			if err = syncer.apply(checkpoint, response.Message(), SourceSync, &result); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if ciolite.ErrorStatusCode(err) != 404 {
+			return result, errors.Wrapf(err, "Unable to get message %s", id)
+		}
+
+		if err = syncer.emit(Event{
+			Type:          EventRemoved,
+			Source:        SourceSync,
+			Folder:        folder,
+			MessageID:     id,
+			PreviousFlags: checkpoint.Messages[id].Flags,
+		}); err != nil {
+			return result, err
+		}
+		delete(checkpoint.Messages, id)
+		result.Removed++
+	}
+
+	checkpoint.Pass = nil
+	checkpoint.CompletedAt = syncer.options.Now().Unix()
+	if err = syncer.options.Store.Save(*checkpoint); err != nil {
+		return result, errors.Wrap(err, "Unable to save checkpoint")
+	}
+
+	return result, nil
+}
+
+// fullPassDue returns true if FullPassInterval has passed since the folder's last full pass
+func (syncer *Syncer) fullPassDue(checkpoint *Checkpoint) bool {
+	return syncer.options.FullPassInterval > 0 &&
+		syncer.options.Now().Sub(time.Unix(checkpoint.CompletedAt, 0)) >= syncer.options.FullPassInterval
+}
+
+// listNewMessages lists the folder newest first, until reaching the messages received before the checkpoint's LastReceivedAt
+func (syncer *Syncer) listNewMessages(checkpoint *Checkpoint, folder string, delimiter string, result *FolderResult) error {
+	lastReceivedAt := checkpoint.LastReceivedAt
+
+	for offset := 0; ; {
+		page, err := syncer.listMessages(folder, delimiter, offset)
+		if err != nil {
+			return err
+		}
+
+		older := false
+		delta := Delta{Messages: map[string]MessageState{}}
+		for _, response := range page {
+			message := response.Message()
+			if len(message.MessageID) == 0 {
+				continue
+			}
+			if message.ReceivedAt != 0 && message.ReceivedAt < lastReceivedAt {
+				older = true
+			}
+			if err = syncer.apply(checkpoint, message, SourceSync, result); err != nil {
+				return err
+			}
+			delta.Messages[message.MessageID] = checkpoint.Messages[message.MessageID]
+		}
+
+		if err = syncer.append(checkpoint, delta); err != nil {
+			return err
+		}
+
+		offset += len(page)
+		if older || len(page) < syncer.options.PageSize {
+			return nil
+		}
+	}
+}
+
+// listAllMessages lists every message in the folder, continuing the checkpoint's pass
+func (syncer *Syncer) listAllMessages(checkpoint *Checkpoint, folder string, delimiter string, result *FolderResult) error {
+	pass := checkpoint.Pass
+
+	for {
+		page, err := syncer.listMessages(folder, delimiter, pass.Offset)
+		if err != nil {
+			return err
+		}
+
+		delta := Delta{Messages: map[string]MessageState{}}
+		for _, response := range page {
+			message := response.Message()
+			if len(message.MessageID) == 0 {
+				continue
+			}
+			pass.Seen[message.MessageID] = true
+			if err = syncer.apply(checkpoint, message, SourceSync, result); err != nil {
+				return err
+			}
+			delta.Messages[message.MessageID] = checkpoint.Messages[message.MessageID]
+			delta.Seen = append(delta.Seen, message.MessageID)
+		}
+
+		pass.Offset += len(page)
+		delta.Offset = pass.Offset
+		if err = syncer.append(checkpoint, delta); err != nil {
+			return err
+		}
+
+		if len(page) < syncer.options.PageSize {
+			return nil
+		}
+	}
+}
+
+// listMessages lists a page of messages of the folder, with their flags
+func (syncer *Syncer) listMessages(folder string, delimiter string, offset int) ([]ciolite.GetUsersEmailAccountFolderMessagesResponse, error) {
+	page, err := syncer.client.GetUserEmailAccountsFolderMessages(syncer.userID, syncer.label, folder,
+		ciolite.GetUserEmailAccountsFolderMessageParams{
+			Delimiter:    delimiter,
+			IncludeFlags: true,
+			Limit:        syncer.options.PageSize,
+			Offset:       offset,
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to list messages")
+	}
+	return page, nil
+}
+
+// MergeWebhook merges a webhook callback into the same event stream as Sync,
+// for every folder of this email account that the message is in.
+// Webhooks do not carry the Deleted flag or keywords, so those are left as they were last synced.
+func (syncer *Syncer) MergeWebhook(callback ciolite.WebhookCallback) error {
+
+	message := callback.MessageData.Message()
+	if len(message.MessageID) == 0 {
+		return errors.New("Webhook message has no message_id")
+	}
+
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	for _, folder := range syncer.webhookFolders(callback.MessageData) {
+		checkpoint, err := syncer.load(folder)
+		if err != nil {
+			return err
+		}
+
+		// Make sure an interrupted pass does not treat this message as removed
+		delta := Delta{Seen: []string{message.MessageID}}
+		if checkpoint.Pass != nil {
+			checkpoint.Pass.Seen[message.MessageID] = true
+		}
+
+		result := FolderResult{Folder: folder}
+		if err = syncer.apply(checkpoint, message, SourceWebhook, &result); err != nil {
+			return err
+		}
+		delta.Messages = map[string]MessageState{message.MessageID: checkpoint.Messages[message.MessageID]}
+		if err = syncer.append(checkpoint, delta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// webhookFolders returns the folders of this email account that the webhook message is in
+func (syncer *Syncer) webhookFolders(data ciolite.WebhookMessageData) []string {
+	var folders []string
+	seen := map[string]bool{}
+	add := func(folder string) {
+		if len(folder) > 0 && !seen[folder] && syncer.includes(folder) {
+			seen[folder] = true
+			folders = append(folders, folder)
+		}
+	}
+
+	for _, accounts := range [][]ciolite.WebhookMessageDataAccount{data.Sources, data.EmailAccounts} {
+		for _, account := range accounts {
+			if account.Label == syncer.label {
+				add(account.Folder)
+			}
+		}
+	}
+
+	// Older callbacks only list the folders
+	if len(data.Sources) == 0 && len(data.EmailAccounts) == 0 {
+		for _, folder := range data.Folders {
+			add(folder)
+		}
+	}
+
+	return folders
+}
+
+// apply compares the message against the checkpoint, emits any event, and then records the message
+func (syncer *Syncer) apply(checkpoint *Checkpoint, message ciolite.Message, source EventSource, result *FolderResult) error {
+
+	known, ok := checkpoint.Messages[message.MessageID]
+	if ok && source == SourceWebhook {
+		message.Flags.Deleted = known.Flags.Deleted
+		message.Flags.Keywords = known.Flags.Keywords
+	}
+
+	event := Event{
+		Source:        source,
+		Folder:        checkpoint.Folder,
+		MessageID:     message.MessageID,
+		Message:       &message,
+		Flags:         message.Flags,
+		PreviousFlags: known.Flags,
+	}
+	switch {
+	case !ok:
+		event.Type = EventAdded
+		result.Added++
+	case !known.Flags.Equal(message.Flags):
+		event.Type = EventFlagsChanged
+		result.FlagsChanged++
+	}
+
+	if len(event.Type) > 0 {
+		if err := syncer.emit(event); err != nil {
+			return err
+		}
+	}
+
+	receivedAt := message.ReceivedAt
+	if receivedAt == 0 {
+		receivedAt = known.ReceivedAt
+	}
+	checkpoint.Messages[message.MessageID] = MessageState{ReceivedAt: receivedAt, Flags: message.Flags}
+	if receivedAt > checkpoint.LastReceivedAt {
+		checkpoint.LastReceivedAt = receivedAt
+	}
+	return nil
+}
+
+// append appends the delta to the checkpoint in the Store, unless it is empty
+func (syncer *Syncer) append(checkpoint *Checkpoint, delta Delta) error {
+	if len(delta.Messages) == 0 && len(delta.Seen) == 0 && delta.Offset == 0 {
+		return nil
+	}
+	if err := syncer.options.Store.Append(checkpoint.UserID, checkpoint.Label, checkpoint.Folder, delta); err != nil {
+		return errors.Wrap(err, "Unable to save checkpoint")
+	}
+	return nil
+}
+
+// emit passes the event to the Handler
+func (syncer *Syncer) emit(event Event) error {
+	if syncer.options.Handler == nil {
+		return nil
+	}
+	event.UserID = syncer.userID
+	event.Label = syncer.label
+	if err := syncer.options.Handler(event); err != nil {
+		return errors.Wrapf(err, "Handler failed on %s event for message %s", event.Type, event.MessageID)
+	}
+	return nil
+}
+
+// load returns the checkpoint for the folder, or a new empty one
+func (syncer *Syncer) load(folder string) (*Checkpoint, error) {
+	checkpoint, err := syncer.options.Store.Load(syncer.userID, syncer.label, folder)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to load checkpoint")
+	}
+	if checkpoint == nil {
+		checkpoint = newCheckpoint(syncer.userID, syncer.label, folder)
+	}
+	return checkpoint, nil
+}
+
+// includes returns true if the folder should be synced
+func (syncer *Syncer) includes(folder string) bool {
+	if len(syncer.options.Folders) == 0 {
+		return true
+	}
+	for _, f := range syncer.options.Folders {
+		if f == folder {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package mailsync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/contextio/contextio-go/ciolite"
+	"github.com/pkg/errors"
+)
+
+// testMailbox is a simulated single folder mailbox, listing its messages in order (newest first)
+type testMailbox struct {
+	mu       sync.Mutex
+	messages []ciolite.GetUsersEmailAccountFolderMessagesResponse
+
+	// onList, if set, is called (with the lock held) after each page is listed
+	onList func(offset int)
+}
+
+// set replaces the messages in the mailbox
+func (mailbox *testMailbox) set(messages ...ciolite.GetUsersEmailAccountFolderMessagesResponse) {
+	mailbox.mu.Lock()
+	mailbox.messages = messages
+	mailbox.mu.Unlock()
+}
+
+// ServeHTTP serves the folder list, the paged message list, and single messages
+func (mailbox *testMailbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mailbox.mu.Lock()
+	defer mailbox.mu.Unlock()
+
+	var response interface{}
+	switch {
+	case r.URL.Path == "/lite/users/u1/email_accounts/0/folders":
+		response = []ciolite.GetUsersEmailAccountFoldersResponse{{Name: "INBOX", Delimiter: "/", NbMessages: len(mailbox.messages)}}
+	case r.URL.Path == "/lite/users/u1/email_accounts/0/folders/INBOX/messages":
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		page := []ciolite.GetUsersEmailAccountFolderMessagesResponse{}
+		for i := offset; i < len(mailbox.messages) && i < offset+limit; i++ {
+			page = append(page, mailbox.messages[i])
+		}
+		if mailbox.onList != nil {
+			mailbox.onList(offset)
+		}
+		response = page
+	case strings.HasPrefix(r.URL.Path, "/lite/users/u1/email_accounts/0/folders/INBOX/messages/"):
+		id := strings.TrimPrefix(r.URL.Path, "/lite/users/u1/email_accounts/0/folders/INBOX/messages/")
+		for _, message := range mailbox.messages {
+			if message.MessageID == id {
+				response = message
+			}
+		}
+		if response == nil {
+			http.NotFound(w, r)
+			return
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		panic(err)
+	}
+}
+
+// remove removes the message from the mailbox, without locking
+func (mailbox *testMailbox) remove(id string) {
+	for i, message := range mailbox.messages {
+		if message.MessageID == id {
+			mailbox.messages = append(mailbox.messages[:i], mailbox.messages[i+1:]...)
+			return
+		}
+	}
+}
+
+// testMessage returns a folder message with the id, read flag, and received time
+func testMessage(id string, read bool, receivedAt int) ciolite.GetUsersEmailAccountFolderMessagesResponse {
+	return ciolite.GetUsersEmailAccountFolderMessagesResponse{
+		MessageID:  id,
+		Folders:    []string{"INBOX"},
+		Flags:      ciolite.UserEmailAccountsFolderMessageFlags{Read: read},
+		ReceivedAt: ciolite.UnixTime(receivedAt),
+	}
+}
+
+// testEvents records the type and message id of every event
+type testEvents struct {
+	events []string
+	failOn string
+}
+
+// handle records the event, or fails if it is for failOn
+func (e *testEvents) handle(event Event) error {
+	if event.MessageID == e.failOn {
+		return errors.New("handler failure")
+	}
+	e.events = append(e.events, string(event.Type)+" "+event.MessageID)
+	return nil
+}
+
+// take returns and clears the recorded events
+func (e *testEvents) take() []string {
+	events := e.events
+	e.events = nil
+	return events
+}
+
+// countingStore counts the checkpoints saved
+type countingStore struct {
+	Store
+	saves int
+}
+
+// Save counts the checkpoint, and saves it
+func (store *countingStore) Save(checkpoint Checkpoint) error {
+	store.saves++
+	return store.Store.Save(checkpoint)
+}
+
+// TestSimulatedSync tests syncing, resuming after a failure, and merging webhooks, with a simulated server
+func TestSimulatedSync(t *testing.T) {
+	t.Parallel()
+
+	mailbox := &testMailbox{}
+	cioLite, testServer := ciolite.NewTestCioLiteServer(mailbox)
+	defer testServer.Close()
+
+	dir, err := ioutil.TempDir("", "mailsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileStore, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &countingStore{Store: fileStore}
+
+	events := &testEvents{}
+	syncer := NewSyncer(cioLite, "u1", "0", Options{Store: store, Handler: events.handle, PageSize: 2})
+
+	// Initial sync lists every message
+	mailbox.set(testMessage("<c>", false, 3), testMessage("<b>", true, 2), testMessage("<a>", false, 1))
+	results, err := syncer.Sync()
+	expected := []string{"added <c>", "added <b>", "added <a>"}
+	if got := events.take(); err != nil || !reflect.DeepEqual(got, expected) || len(results) != 1 || results[0].Added != 3 || results[0].Incremental {
+		t.Error("Expected: ", expected, "; Got: ", got, results, "; With Error: ", err)
+	}
+
+	// Nothing changed, and only the first page is listed, without rewriting the whole checkpoint
+	saves := store.saves
+	if results, err = syncer.Sync(); err != nil || len(events.events) != 0 || !results[0].Incremental || store.saves != saves {
+		t.Error("Expected an incremental sync without events; Got: ", events.take(), results, store.saves-saves, " saves; With Error: ", err)
+	}
+
+	// <d> is added, <a> is read, and <b> is removed, which the count shows so every message is listed
+	mailbox.set(testMessage("<d>", false, 4), testMessage("<c>", false, 3), testMessage("<a>", true, 1))
+	results, err = syncer.Sync()
+	expected = []string{"added <d>", "flags_changed <a>", "removed <b>"}
+	if got := events.take(); err != nil || !reflect.DeepEqual(got, expected) || results[0].Incremental {
+		t.Error("Expected: ", expected, "; Got: ", got, results, "; With Error: ", err)
+	}
+
+	// The handler fails on a removal, and then a new syncer resumes the full pass from the checkpoint
+	mailbox.set(testMessage("<f>", false, 6), testMessage("<e>", false, 5), testMessage("<d>", false, 4), testMessage("<a>", true, 1))
+	events.failOn = "<c>"
+	if _, err = syncer.Sync(); err == nil {
+		t.Error("Expected the handler error")
+	}
+	expected = []string{"added <f>", "added <e>"}
+	if got := events.take(); !reflect.DeepEqual(got, expected) {
+		t.Error("Expected: ", expected, "; Got: ", got)
+	}
+	events.failOn = ""
+	results, err = NewSyncer(cioLite, "u1", "0", Options{Store: store, Handler: events.handle, PageSize: 2}).Sync()
+	expected = []string{"removed <c>"}
+	if got := events.take(); err != nil || !reflect.DeepEqual(got, expected) || !results[0].Resumed {
+		t.Error("Expected: ", expected, "; Got: ", got, results, "; With Error: ", err)
+	}
+
+	// A webhook adds <g> and flags <a>, and the next sync has nothing left to notice
+	var callback ciolite.WebhookCallback
+	callback.MessageData.MessageID = "<g>"
+	callback.MessageData.Sources = []ciolite.WebhookMessageDataAccount{{Label: "0", Folder: "INBOX"}}
+	if err = syncer.MergeWebhook(callback); err != nil {
+		t.Error("Unable to merge webhook: ", err)
+	}
+	callback.MessageData.MessageID = "<a>"
+	callback.MessageData.Flags.Seen = true
+	callback.MessageData.Flags.Flagged = true
+	if err = syncer.MergeWebhook(callback); err != nil {
+		t.Error("Unable to merge webhook: ", err)
+	}
+	expected = []string{"added <g>", "flags_changed <a>"}
+	if got := events.take(); !reflect.DeepEqual(got, expected) {
+		t.Error("Expected: ", expected, "; Got: ", got)
+	}
+
+	flagged := testMessage("<a>", true, 1)
+	flagged.Flags.Flagged = true
+	mailbox.set(testMessage("<g>", false, 7), testMessage("<f>", false, 6), testMessage("<e>", false, 5), testMessage("<d>", false, 4), flagged)
+	if results, err = syncer.Sync(); err != nil || len(events.events) != 0 || !results[0].Incremental {
+		t.Error("Expected no events; Got: ", events.take(), results, "; With Error: ", err)
+	}
+
+	checkpoint, err := store.Load("u1", "0", "INBOX")
+	if err != nil || checkpoint == nil || len(checkpoint.Messages) != 5 || checkpoint.Pass != nil || checkpoint.LastReceivedAt != 7 {
+		t.Error("Expected a completed checkpoint with 5 messages; Got: ", checkpoint, "; With Error: ", err)
+	}
+}
+
+// TestSimulatedSyncFullPass tests that messages shifted by a removal during a full pass are not reported removed,
+// and that FullPassInterval notices flag changes of older messages, with a simulated server
+func TestSimulatedSyncFullPass(t *testing.T) {
+	t.Parallel()
+
+	mailbox := &testMailbox{}
+	cioLite, testServer := ciolite.NewTestCioLiteServer(mailbox)
+	defer testServer.Close()
+
+	now := time.Unix(1500000000, 0)
+	events := &testEvents{}
+	syncer := NewSyncer(cioLite, "u1", "0", Options{Handler: events.handle, PageSize: 2, FullPassInterval: time.Hour, Now: func() time.Time { return now }})
+
+	mailbox.set(testMessage("<e>", false, 5), testMessage("<d>", false, 4), testMessage("<c>", false, 3), testMessage("<b>", false, 2), testMessage("<a>", false, 1))
+	if _, err := syncer.Sync(); err != nil || len(events.take()) != 5 {
+		t.Error("Unable to sync: ", err)
+	}
+
+	// <a> is removed, and <e> is removed right after the first page of the full pass, shifting <c> out of the listing.
+	// <c> is not reported removed, and <e> is only reported by the next sync as it had already been listed.
+	lists := 0
+	mailbox.set(testMessage("<e>", false, 5), testMessage("<d>", false, 4), testMessage("<c>", false, 3), testMessage("<b>", false, 2))
+	mailbox.onList = func(offset int) {
+		if offset == 0 {
+			if lists++; lists == 2 {
+				mailbox.remove("<e>")
+			}
+		}
+	}
+	results, err := syncer.Sync()
+	expected := []string{"removed <a>"}
+	if got := events.take(); err != nil || !reflect.DeepEqual(got, expected) || results[0].Removed != 1 {
+		t.Error("Expected: ", expected, "; Got: ", got, results, "; With Error: ", err)
+	}
+	mailbox.mu.Lock()
+	mailbox.onList = nil
+	mailbox.mu.Unlock()
+	_, err = syncer.Sync()
+	expected = []string{"removed <e>"}
+	if got := events.take(); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Error("Expected: ", expected, "; Got: ", got, "; With Error: ", err)
+	}
+
+	// Flag changes of older messages are only noticed once FullPassInterval has passed
+	mailbox.set(testMessage("<d>", false, 4), testMessage("<c>", false, 3), testMessage("<b>", true, 2))
+	if results, err = syncer.Sync(); err != nil || len(events.events) != 0 || !results[0].Incremental {
+		t.Error("Expected an incremental sync without events; Got: ", events.take(), results, "; With Error: ", err)
+	}
+	now = now.Add(time.Hour)
+	results, err = syncer.Sync()
+	expected = []string{"flags_changed <b>"}
+	if got := events.take(); err != nil || !reflect.DeepEqual(got, expected) || results[0].Incremental {
+		t.Error("Expected: ", expected, "; Got: ", got, results, "; With Error: ", err)
+	}
+}
+
+// TestFileStoreDeltas tests that deltas are applied on load, survive a partially written line, and are replaced by a save
+func TestFileStoreDeltas(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "mailsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint := newCheckpoint("u1", "0", "INBOX")
+	checkpoint.Messages["<a>"] = MessageState{ReceivedAt: 1}
+	checkpoint.Pass = &Pass{MessageCount: 3, Seen: map[string]bool{}}
+	if err = store.Save(*checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{"<b>", "<c>"} {
+		delta := Delta{Messages: map[string]MessageState{id: {ReceivedAt: ciolite.UnixTime(i + 2)}}, Seen: []string{id}, Offset: i + 1}
+		if err = store.Append("u1", "0", "INBOX", delta); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A crash in the middle of appending
+	file, err := os.OpenFile(store.path("u1", "0", "INBOX")+".deltas", os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = file.Write([]byte(`{"messages": {"<d>"`)); err != nil {
+		t.Fatal(err)
+	}
+	if err = file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load("u1", "0", "INBOX")
+	if err != nil || len(loaded.Messages) != 3 || loaded.LastReceivedAt != 3 || loaded.Pass.Offset != 2 || !reflect.DeepEqual(loaded.Pass.Seen, map[string]bool{"<b>": true, "<c>": true}) {
+		t.Error("Expected the deltas to be applied; Got: ", loaded, "; With Error: ", err)
+	}
+
+	loaded.Pass = nil
+	if err = store.Save(*loaded); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(store.path("u1", "0", "INBOX") + ".deltas"); !os.IsNotExist(err) {
+		t.Error("Expected the deltas to be removed; Got: ", err)
+	}
+	if loaded, err = store.Load("u1", "0", "INBOX"); err != nil || len(loaded.Messages) != 3 || loaded.Pass != nil {
+		t.Error("Expected the saved checkpoint; Got: ", loaded, "; With Error: ", err)
+	}
+}
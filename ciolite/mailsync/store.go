@@ -0,0 +1,288 @@
+package mailsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/contextio/contextio-go/ciolite"
+	"github.com/pkg/errors"
+)
+
+// Checkpoint is what the sync engine knows about a single folder
+type Checkpoint struct {
+	UserID string `json:"user_id"`
+	Label  string `json:"label"`
+	Folder string `json:"folder"`
+
+	// LastReceivedAt is the latest ReceivedAt of any message seen in this folder, where incremental syncs stop listing
+	LastReceivedAt ciolite.UnixTime `json:"last_received_at,omitempty"`
+
+	// Messages are the known messages, by MessageID
+	Messages map[string]MessageState `json:"messages"`
+
+	// Pass is the listing pass in progress, or nil if the last pass completed
+	Pass *Pass `json:"pass,omitempty"`
+
+	// CompletedAt is when the last full pass completed (unix seconds)
+	CompletedAt int64 `json:"completed_at,omitempty"`
+}
+
+// MessageState is what the sync engine remembers about a single message
+type MessageState struct {
+	ReceivedAt ciolite.UnixTime     `json:"received_at,omitempty"`
+	Flags      ciolite.MessageFlags `json:"flags"`
+}
+
+// Pass is a full listing pass in progress, saved after every page so that it can be resumed after a crash
+type Pass struct {
+	// MessageCount is the folder's message count when this pass started
+	MessageCount int `json:"message_count"`
+
+	// Offset is where the next page starts
+	Offset int `json:"offset"`
+
+	// Seen are the MessageIDs listed so far in this pass
+	Seen map[string]bool `json:"seen"`
+}
+
+// Delta is a change to a checkpoint, appended to the Store instead of saving the whole checkpoint again,
+// so that listing a page or merging a webhook costs as much as the messages it changed
+type Delta struct {
+	// Messages are the messages added or updated
+	Messages map[string]MessageState `json:"messages,omitempty"`
+
+	// Seen are MessageIDs listed by the pass in progress
+	Seen []string `json:"seen,omitempty"`
+
+	// Offset, if not 0, is where the next page of the pass in progress starts
+	Offset int `json:"offset,omitempty"`
+}
+
+// apply applies the delta to the checkpoint
+func (delta Delta) apply(checkpoint *Checkpoint) {
+	for id, state := range delta.Messages {
+		checkpoint.Messages[id] = state
+		if state.ReceivedAt > checkpoint.LastReceivedAt {
+			checkpoint.LastReceivedAt = state.ReceivedAt
+		}
+	}
+	if checkpoint.Pass != nil {
+		for _, id := range delta.Seen {
+			checkpoint.Pass.Seen[id] = true
+		}
+		if delta.Offset > 0 {
+			checkpoint.Pass.Offset = delta.Offset
+		}
+	}
+}
+
+// newCheckpoint returns an empty Checkpoint for the folder
+func newCheckpoint(userID string, label string, folder string) *Checkpoint {
+	return &Checkpoint{UserID: userID, Label: label, Folder: folder, Messages: map[string]MessageState{}}
+}
+
+// Store persists Checkpoints. Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the checkpoint for the folder with every Delta appended since it was saved applied,
+	// or nil if there is none
+	Load(userID string, label string, folder string) (*Checkpoint, error)
+
+	// Save stores the checkpoint, replacing any earlier checkpoint and Deltas for the same folder
+	Save(checkpoint Checkpoint) error
+
+	// Append stores a change to the folder's checkpoint
+	Append(userID string, label string, folder string, delta Delta) error
+}
+
+// MemoryStore is a Store that keeps checkpoints in memory, which is useful for tests
+// and for processes that do not need to resume after restarting
+type MemoryStore struct {
+	mu          sync.Mutex
+	checkpoints map[string][]byte
+	deltas      map[string][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: map[string][]byte{}, deltas: map[string][]byte{}}
+}
+
+// Load returns a copy of the checkpoint for the folder, or nil if there is none
+func (store *MemoryStore) Load(userID string, label string, folder string) (*Checkpoint, error) {
+	key := checkpointKey(userID, label, folder)
+	store.mu.Lock()
+	data, deltas := store.checkpoints[key], store.deltas[key]
+	store.mu.Unlock()
+	return loadCheckpoint(userID, label, folder, data, deltas)
+}
+
+// Save stores a copy of the checkpoint
+func (store *MemoryStore) Save(checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return errors.Wrap(err, "Unable to marshal checkpoint")
+	}
+	key := checkpointKey(checkpoint.UserID, checkpoint.Label, checkpoint.Folder)
+	store.mu.Lock()
+	store.checkpoints[key] = data
+	delete(store.deltas, key)
+	store.mu.Unlock()
+	return nil
+}
+
+// Append stores a copy of the delta
+func (store *MemoryStore) Append(userID string, label string, folder string, delta Delta) error {
+	data, err := marshalDelta(delta)
+	if err != nil {
+		return err
+	}
+	key := checkpointKey(userID, label, folder)
+	store.mu.Lock()
+	store.deltas[key] = append(store.deltas[key], data...)
+	store.mu.Unlock()
+	return nil
+}
+
+// FileStore is a Store that keeps each checkpoint in its own json file within Dir,
+// along with a file of the Deltas appended since, one json object per line
+type FileStore struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewFileStore returns a FileStore within dir, creating it if necessary
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "Unable to create checkpoint directory")
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+// Load reads the checkpoint for the folder, or returns nil if there is none
+func (store *FileStore) Load(userID string, label string, folder string) (*Checkpoint, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	path := store.path(userID, label, folder)
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "Unable to read checkpoint")
+	}
+	deltas, err := ioutil.ReadFile(path + ".deltas")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "Unable to read checkpoint deltas")
+	}
+	return loadCheckpoint(userID, label, folder, data, deltas)
+}
+
+// Save removes the deltas, then writes the checkpoint to a temporary file and renames it into place,
+// so that a crash never leaves a partially written checkpoint behind
+// (a crash in between loses the deltas, whose events are then delivered again)
+func (store *FileStore) Save(checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return errors.Wrap(err, "Unable to marshal checkpoint")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	path := store.path(checkpoint.UserID, checkpoint.Label, checkpoint.Folder)
+	if err = os.Remove(path + ".deltas"); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Unable to remove checkpoint deltas")
+	}
+	temp := path + ".tmp"
+	if err = ioutil.WriteFile(temp, data, 0600); err != nil {
+		return errors.Wrap(err, "Unable to write checkpoint")
+	}
+	if err = os.Rename(temp, path); err != nil {
+		return errors.Wrap(err, "Unable to replace checkpoint")
+	}
+	return nil
+}
+
+// Append appends the delta to the folder's deltas file.
+// A partially written last line (ex: after a crash) is ignored by Load.
+func (store *FileStore) Append(userID string, label string, folder string, delta Delta) error {
+	data, err := marshalDelta(delta)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	file, err := os.OpenFile(store.path(userID, label, folder)+".deltas", os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Unable to open checkpoint deltas")
+	}
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "Unable to write checkpoint deltas")
+	}
+	return errors.Wrap(file.Close(), "Unable to write checkpoint deltas")
+}
+
+// path returns the file name for the folder's checkpoint
+func (store *FileStore) path(userID string, label string, folder string) string {
+	return filepath.Join(store.Dir, url.QueryEscape(checkpointKey(userID, label, folder))+".json")
+}
+
+// checkpointKey returns the key identifying a folder
+func checkpointKey(userID string, label string, folder string) string {
+	return url.QueryEscape(userID) + "/" + url.QueryEscape(label) + "/" + url.QueryEscape(folder)
+}
+
+// loadCheckpoint unmarshals a stored checkpoint (or starts a new one, if there are only deltas),
+// and applies the deltas to it. Returns nil if there is neither.
+func loadCheckpoint(userID string, label string, folder string, data []byte, deltas []byte) (*Checkpoint, error) {
+	if len(data) == 0 && len(deltas) == 0 {
+		return nil, nil
+	}
+
+	checkpoint := newCheckpoint(userID, label, folder)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, checkpoint); err != nil {
+			return nil, errors.Wrap(err, "Unable to unmarshal checkpoint")
+		}
+		if checkpoint.Messages == nil {
+			checkpoint.Messages = map[string]MessageState{}
+		}
+		if checkpoint.Pass != nil && checkpoint.Pass.Seen == nil {
+			checkpoint.Pass.Seen = map[string]bool{}
+		}
+	}
+
+	lines := bytes.Split(deltas, []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		var delta Delta
+		if err := json.Unmarshal(line, &delta); err != nil {
+			if i == len(lines)-1 {
+				// The last line was only partially written
+				break
+			}
+			return nil, errors.Wrap(err, "Unable to unmarshal checkpoint delta")
+		}
+		delta.apply(checkpoint)
+	}
+
+	return checkpoint, nil
+}
+
+// marshalDelta marshals the delta as a single line
+func marshalDelta(delta Delta) ([]byte, error) {
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to marshal checkpoint delta")
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,266 @@
+package ciolite
+
+// Unified message model, with converters from every message response shape
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Message is the canonical form of an email message, whether it came from
+// GetUserEmailAccountsFolderMessages, GetUserEmailAccountsMessages, or a WebhookCallback.
+// Fields that a response shape does not have are left empty.
+type Message struct {
+	MessageID   string `json:"message_id,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	InReplyTo   string `json:"in_reply_to,omitempty"`
+	ResourceURL string `json:"resource_url,omitempty"`
+
+	Folders         []string `json:"folders,omitempty"`
+	References      []string `json:"references,omitempty"`
+	ReceivedHeaders []string `json:"received_headers,omitempty"`
+
+	ListHeaders ListHeaders `json:"list_headers,omitempty"`
+
+	Headers mail.Header `json:"headers,omitempty"`
+
+	From       []Address `json:"from,omitempty"`
+	To         []Address `json:"to,omitempty"`
+	Cc         []Address `json:"cc,omitempty"`
+	Bcc        []Address `json:"bcc,omitempty"`
+	Sender     []Address `json:"sender,omitempty"`
+	ReplyTo    []Address `json:"reply_to,omitempty"`
+	ReturnPath []Address `json:"return_path,omitempty"`
+
+	PersonInfo PersonInfo `json:"person_info,omitempty"`
+
+	Flags MessageFlags `json:"flags,omitempty"`
+
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+
+	Bodies []MessageBody `json:"bodies,omitempty"`
+
+	// Sources and EmailAccounts are only present on webhook messages
+	Sources       []WebhookMessageDataAccount `json:"sources,omitempty"`
+	EmailAccounts []WebhookMessageDataAccount `json:"email_accounts,omitempty"`
+
+	// SentAt is the Date header, and ReceivedAt is when the server received the message (unix seconds)
+	SentAt     UnixTime `json:"sent_at,omitempty"`
+	ReceivedAt UnixTime `json:"received_at,omitempty"`
+}
+
+// MessageAttachment is the canonical form of a message attachment (or webhook file)
+type MessageAttachment struct {
+	Type               string `json:"type,omitempty"`
+	FileName           string `json:"file_name,omitempty"`
+	MainFileName       string `json:"main_file_name,omitempty"`
+	BodySection        string `json:"body_section,omitempty"`
+	ContentDisposition string `json:"content_disposition,omitempty"`
+	ContentID          string `json:"content_id,omitempty"`
+	MessageID          string `json:"message_id,omitempty"`
+	XAttachmentID      string `json:"x_attachment_id,omitempty"`
+
+	FileNameStructure [][]string `json:"file_name_structure,omitempty"`
+
+	Size         int `json:"size,omitempty"`
+	AttachmentID int `json:"attachment_id,omitempty"`
+
+	IsEmbedded bool `json:"is_embedded,omitempty"`
+}
+
+// MessageBody is the canonical form of a message body part
+type MessageBody struct {
+	BodySection string `json:"body_section,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Charset     string `json:"charset,omitempty"`
+	Content     string `json:"content,omitempty"`
+
+	Size int `json:"size,omitempty"`
+}
+
+// Message returns the canonical Message
+func (m GetUsersEmailAccountFolderMessagesResponse) Message() Message {
+
+	message := Message{
+		MessageID:       m.MessageID,
+		Subject:         m.Subject,
+		InReplyTo:       m.InReplyTo,
+		ResourceURL:     m.ResourceURL,
+		Folders:         m.Folders,
+		References:      m.References,
+		ReceivedHeaders: m.ReceivedHeaders,
+		ListHeaders:     m.ListHeaders,
+		From:            m.Addresses.From,
+		To:              m.Addresses.To,
+		Cc:              m.Addresses.Cc,
+		Bcc:             m.Addresses.Bcc,
+		Sender:          m.Addresses.Sender,
+		ReplyTo:         m.Addresses.ReplyTo,
+		PersonInfo:      m.PersonInfo,
+		Flags:           m.Flags.MessageFlags(),
+		SentAt:          m.SentAt,
+		ReceivedAt:      m.ReceivedAt,
+	}
+
+	for _, attachment := range m.Attachments {
+		message.Attachments = append(message.Attachments, MessageAttachment{
+			Type:               attachment.Type,
+			FileName:           attachment.FileName,
+			BodySection:        attachment.BodySection,
+			ContentDisposition: attachment.ContentDisposition,
+			MessageID:          attachment.MessageID,
+			XAttachmentID:      attachment.XAttachmentID,
+			Size:               attachment.Size,
+			AttachmentID:       attachment.AttachmentID,
+		})
+	}
+
+	for _, body := range m.Bodies {
+		message.Bodies = append(message.Bodies, MessageBody{
+			BodySection: body.BodySection,
+			Type:        body.Type,
+			Encoding:    body.Encoding,
+			Content:     body.Content,
+			Size:        body.Size,
+		})
+	}
+
+	return message
+}
+
+// Message returns the canonical Message
+func (m GetUsersEmailAccountMessagesResponse) Message() Message {
+
+	message := Message{
+		MessageID:       m.MessageID,
+		Subject:         m.Subject,
+		InReplyTo:       m.InReplyTo,
+		ResourceURL:     m.ResourceURL,
+		Folders:         m.Folders,
+		References:      m.References,
+		ReceivedHeaders: m.ReceivedHeaders,
+		ListHeaders:     m.ListHeaders,
+		From:            m.Addresses.From,
+		To:              m.Addresses.To,
+		Cc:              m.Addresses.Cc,
+		Bcc:             m.Addresses.Bcc,
+		Sender:          m.Addresses.Sender,
+		ReplyTo:         m.Addresses.ReplyTo,
+		PersonInfo:      m.PersonInfo,
+		Flags:           m.Flags.MessageFlags(),
+		SentAt:          m.SentAt,
+		ReceivedAt:      m.ReceivedAt,
+	}
+
+	for _, attachment := range m.Attachments {
+		message.Attachments = append(message.Attachments, MessageAttachment{
+			Type:               attachment.Type,
+			FileName:           attachment.FileName,
+			BodySection:        attachment.BodySection,
+			ContentDisposition: attachment.ContentDisposition,
+			MessageID:          attachment.MessageID,
+			XAttachmentID:      attachment.XAttachmentID,
+			Size:               attachment.Size,
+			AttachmentID:       attachment.AttachmentID,
+		})
+	}
+
+	for _, body := range m.Bodies {
+		message.Bodies = append(message.Bodies, MessageBody{
+			BodySection: body.BodySection,
+			Type:        body.Type,
+			Encoding:    body.Encoding,
+			Content:     body.Content,
+			Size:        body.Size,
+		})
+	}
+
+	return message
+}
+
+// Message returns the canonical Message.
+// The single From address of a webhook becomes a one element From slice,
+// Date becomes SentAt, and DateReceived becomes ReceivedAt.
+func (m WebhookMessageData) Message() Message {
+
+	message := Message{
+		MessageID:     m.MessageID,
+		Subject:       m.Subject,
+		Folders:       m.Folders,
+		References:    m.References,
+		Headers:       m.Headers,
+		To:            m.Addresses.To,
+		Cc:            m.Addresses.Cc,
+		Bcc:           m.Addresses.Bcc,
+		Sender:        m.Addresses.Sender,
+		ReplyTo:       m.Addresses.ReplyTo,
+		ReturnPath:    m.Addresses.ReturnPath,
+		PersonInfo:    m.PersonInfo,
+		Flags:         m.Flags.MessageFlags(),
+		Sources:       m.Sources,
+		EmailAccounts: m.EmailAccounts,
+		SentAt:        m.Date,
+		ReceivedAt:    m.DateReceived,
+	}
+
+	if m.Addresses.From != (Address{}) {
+		message.From = []Address{m.Addresses.From}
+	}
+
+	// Webhooks only carry In-Reply-To within the headers, if they were included
+	message.InReplyTo = headerValue(m.Headers, "In-Reply-To")
+
+	for _, file := range m.Files {
+		message.Attachments = append(message.Attachments, MessageAttachment{
+			Type:               file.Type,
+			FileName:           file.FileName,
+			MainFileName:       file.MainFileName,
+			BodySection:        file.BodySection,
+			ContentDisposition: file.ContentDisposition,
+			ContentID:          file.ContentID,
+			MessageID:          m.MessageID,
+			XAttachmentID:      xAttachmentIDString(file.XAttachmentID),
+			FileNameStructure:  file.FileNameStructure,
+			Size:               file.Size,
+			AttachmentID:       file.AttachmentID,
+			IsEmbedded:         file.IsEmbedded,
+		})
+	}
+
+	for _, body := range m.Bodies {
+		message.Bodies = append(message.Bodies, MessageBody{
+			BodySection: body.BodySection,
+			Type:        body.Type,
+			Charset:     body.Charset,
+			Content:     body.Content,
+		})
+	}
+
+	return message
+}
+
+// xAttachmentIDString returns the webhook x_attachment_id as a string.
+// Webhooks send false (or nothing) when there is no x_attachment_id.
+func xAttachmentIDString(xAttachmentID interface{}) string {
+	switch v := xAttachmentID.(type) {
+	case nil, bool:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// headerValue returns the first value of the header, matching the key case-insensitively,
+// since the header keys within json payloads are not always in canonical form
+func headerValue(headers mail.Header, key string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, key) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+package ciolite
+
+// Batch executor for operations on many users/email_accounts/folders/messages at once
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MessageOperationType is the kind of operation to do on a message
+type MessageOperationType string
+
+// MessageOperationType values
+const (
+	MessageOperationMove       MessageOperationType = "move"
+	MessageOperationMarkRead   MessageOperationType = "read"
+	MessageOperationMarkUnRead MessageOperationType = "unread"
+	MessageOperationSetFlag    MessageOperationType = "set_flag"
+	MessageOperationClearFlag  MessageOperationType = "clear_flag"
+)
+
+// MessageOperation is a single operation on a single message within a batch.
+// NewFolder is required for MessageOperationMove,
+// and Flag is required for MessageOperationSetFlag and MessageOperationClearFlag.
+type MessageOperation struct {
+	Type      MessageOperationType
+	Folder    string
+	MessageID string
+	NewFolder string
+	Flag      MessageFlag
+
+	// Optional:
+	Delimiter string
+}
+
+// MessageBatchOptions configure RunMessageBatch
+type MessageBatchOptions struct {
+	// Concurrency is the maximum number of requests in flight at once, defaults to 1
+	Concurrency int
+
+	// StopOnError stops starting new operations once any operation fails.
+	// Operations that were not started are reported as Skipped.
+	StopOnError bool
+}
+
+// MessageOperationResult is the outcome of a single MessageOperation
+type MessageOperationResult struct {
+	Operation MessageOperation
+
+	// Skipped is true if the operation was never attempted, because of StopOnError
+	Skipped bool
+
+	// StatusCode is the HTTP status code received (200 on success, 0 if no response)
+	StatusCode int
+
+	Err error
+}
+
+// MessageBatchStats are the overall statistics of a batch
+type MessageBatchStats struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Skipped   int
+
+	Duration time.Duration
+}
+
+// MessageBatchResult holds the result of every operation, in the same order as the operations passed in
+type MessageBatchResult struct {
+	Results []MessageOperationResult
+	Stats   MessageBatchStats
+}
+
+// RunMessageBatch runs the operations against the messages of a user email account,
+// with at most options.Concurrency operations in flight at once.
+func RunMessageBatch(client Interface, userID string, label string, operations []MessageOperation, options MessageBatchOptions) MessageBatchResult {
+
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+
+	start := time.Now()
+	batch := MessageBatchResult{
+		Results: make([]MessageOperationResult, len(operations)),
+	}
+
+	var (
+		mu      sync.Mutex
+		stopped bool
+		wg      sync.WaitGroup
+		indexes = make(chan int)
+	)
+
+	for w := 0; w < options.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				mu.Lock()
+				stop := stopped
+				mu.Unlock()
+				if stop {
+					batch.Results[i] = MessageOperationResult{Operation: operations[i], Skipped: true}
+					continue
+				}
+				result := runMessageOperation(client, userID, label, operations[i])
+				batch.Results[i] = result
+				if result.Err != nil && options.StopOnError {
+					mu.Lock()
+					stopped = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range operations {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	batch.Stats.Total = len(operations)
+	for _, result := range batch.Results {
+		switch {
+		case result.Skipped:
+			batch.Stats.Skipped++
+		case result.Err != nil:
+			batch.Stats.Failed++
+		default:
+			batch.Stats.Succeeded++
+		}
+	}
+	batch.Stats.Duration = time.Since(start)
+
+	return batch
+}
+
+// runMessageOperation runs a single operation
+func runMessageOperation(client Interface, userID string, label string, operation MessageOperation) MessageOperationResult {
+
+	result := MessageOperationResult{Operation: operation}
+
+	result.Err = doMessageOperation(client, userID, label, operation)
+
+	if result.Err == nil {
+		result.StatusCode = http.StatusOK
+	} else if code := ErrorStatusCode(result.Err); code > 0 {
+		result.StatusCode = code
+	}
+
+	return result
+}
+
+// doMessageOperation makes the call of a single operation
+func doMessageOperation(client Interface, userID string, label string, operation MessageOperation) error {
+	var (
+		success bool
+		err     error
+	)
+	switch operation.Type {
+	case MessageOperationMove:
+		var response MoveUserEmailAccountFolderMessageResponse
+		response, err = client.MoveUserEmailAccountFolderMessage(userID, label, operation.Folder, operation.MessageID,
+			MoveUserEmailAccountFolderMessageParams{NewFolderID: operation.NewFolder, Delimiter: operation.Delimiter})
+		success = response.Success
+	case MessageOperationMarkRead:
+		var response UserEmailAccountsFolderMessageReadResponse
+		response, err = client.MarkUserEmailAccountsFolderMessageRead(userID, label, operation.Folder, operation.MessageID,
+			EmailAccountFolderDelimiterParam{Delimiter: operation.Delimiter})
+		success = response.Success
+	case MessageOperationMarkUnRead:
+		var response UserEmailAccountsFolderMessageReadResponse
+		response, err = client.MarkUserEmailAccountsFolderMessageUnRead(userID, label, operation.Folder, operation.MessageID,
+			EmailAccountFolderDelimiterParam{Delimiter: operation.Delimiter})
+		success = response.Success
+	case MessageOperationSetFlag:
+		var response ModifyUserEmailAccountsFolderMessageFlagsResponse
+		response, err = client.SetUserEmailAccountsFolderMessageFlag(userID, label, operation.Folder, operation.MessageID, operation.Flag,
+			EmailAccountFolderDelimiterParam{Delimiter: operation.Delimiter})
+		success = response.Success
+	case MessageOperationClearFlag:
+		var response ModifyUserEmailAccountsFolderMessageFlagsResponse
+		response, err = client.ClearUserEmailAccountsFolderMessageFlag(userID, label, operation.Folder, operation.MessageID, operation.Flag,
+			EmailAccountFolderDelimiterParam{Delimiter: operation.Delimiter})
+		success = response.Success
+	default:
+		return errors.Errorf("Unknown message operation type: %s", operation.Type)
+	}
+	if err == nil && !success {
+		err = errors.Errorf("Unable to %s message. CIO returned 200 but with Success=false", operation.Type)
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package ciolite
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSimulatedRunMessageBatch tests RunMessageBatch with a simulated server
+func TestSimulatedRunMessageBatch(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "bad") {
+			w.WriteHeader(http.StatusNotFound)
+			_, err := io.WriteString(w, `{"type": "error", "value": "message not found"}`)
+			Must(err)
+			return
+		}
+		if r.Method == "PUT" && r.URL.Query().Get("new_folder_id") != "Archive" {
+			t.Error("Expected new_folder_id=Archive; Got: ", r.URL.RawQuery)
+		}
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+
+	operations := []MessageOperation{
+		{Type: MessageOperationMarkRead, Folder: "INBOX", MessageID: "<1@example.com>"},
+		{Type: MessageOperationMarkUnRead, Folder: "INBOX", MessageID: "<2@example.com>"},
+		{Type: MessageOperationMove, Folder: "INBOX", MessageID: "<bad@example.com>", NewFolder: "Archive"},
+		{Type: MessageOperationMove, Folder: "INBOX", MessageID: "<3@example.com>", NewFolder: "Archive"},
+	}
+
+	// Run everything
+	batch := RunMessageBatch(cioLite, "u1", "0", operations, MessageBatchOptions{Concurrency: 3})
+	if batch.Stats.Total != 4 || batch.Stats.Succeeded != 3 || batch.Stats.Failed != 1 || batch.Stats.Skipped != 0 {
+		t.Error("Expected 3 succeeded and 1 failed; Got: ", batch.Stats, "; With Log: ", logger.String())
+	}
+	if result := batch.Results[2]; result.Err == nil || result.StatusCode != 404 || result.Operation != operations[2] {
+		t.Error("Expected a 404 error for the third operation; Got: ", result)
+	}
+	if result := batch.Results[3]; result.Err != nil || result.StatusCode != 200 {
+		t.Error("Expected the fourth operation to succeed; Got: ", result)
+	}
+
+	// Stop on the first error
+	batch = RunMessageBatch(cioLite, "u1", "0", operations, MessageBatchOptions{StopOnError: true})
+	if batch.Stats.Succeeded != 2 || batch.Stats.Failed != 1 || batch.Stats.Skipped != 1 || !batch.Results[3].Skipped {
+		t.Error("Expected the fourth operation to be skipped; Got: ", batch.Stats, batch.Results)
+	}
+}
+
+// TestSimulatedRunMessageBatchClientLimiter tests that a batch is paced by the client's Limiter, and retried by WithRetry
+func TestSimulatedRunMessageBatchClientLimiter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/m1/read", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		first := attempts == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+
+	limiter := &countingLimiter{}
+	cioLite, testServer := NewTestCioLiteServer(mux, WithLimiter(limiter), WithRetry(1, time.Millisecond))
+	defer testServer.Close()
+
+	batch := RunMessageBatch(cioLite, "u1", "0", []MessageOperation{{Type: MessageOperationMarkRead, Folder: "INBOX", MessageID: "m1"}}, MessageBatchOptions{})
+	if batch.Stats.Succeeded != 1 || attempts != 2 || limiter.waits != 2 {
+		t.Error("Expected the rate limited operation to be retried, waiting on the limiter before each attempt; Got: ", batch, "; With Attempts: ", attempts, "; With Waits: ", limiter.waits)
+	}
+}
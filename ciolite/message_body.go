@@ -0,0 +1,255 @@
+package ciolite
+
+// Decoding of message bodies into UTF-8, choosing the best body, and rendering HTML as text
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+)
+
+// MessageBody returns the canonical MessageBody
+func (b GetUserEmailAccountsFolderMessageBodyResponse) MessageBody() MessageBody {
+	return MessageBody{
+		BodySection: b.BodySection,
+		Type:        b.Type,
+		Charset:     b.Charset,
+		Content:     b.Content,
+	}
+}
+
+// MediaType returns the lowercased media type (ex: text/plain), without any parameters
+func (b MessageBody) MediaType() string {
+	mediaType, _, err := mime.ParseMediaType(b.Type)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(b.Type, ";")[0]))
+	}
+	return mediaType
+}
+
+// Decode returns the content as UTF-8, after undoing its transfer encoding (base64 or quoted-printable)
+// and converting it from its charset. The charset is taken from Charset, or else from a charset parameter
+// within Type; if there is neither, the content is assumed to be UTF-8 (or windows-1252 if it is not valid UTF-8).
+func (b MessageBody) Decode() (string, error) {
+
+	content, decoded, err := decodeTransfer(b.Encoding, b.Content)
+	if err != nil {
+		return "", err
+	}
+
+	charset := strings.ToLower(strings.TrimSpace(b.Charset))
+	if len(charset) == 0 {
+		if _, params, err := mime.ParseMediaType(b.Type); err == nil {
+			charset = strings.ToLower(params["charset"])
+		}
+	}
+	if len(charset) == 0 {
+		if utf8.Valid(content) {
+			return string(content), nil
+		}
+		charset = "windows-1252"
+	}
+
+	// Content that arrived as json text (rather than transfer encoded) is already unicode:
+	// either it was converted already, or each byte of the original charset became a character
+	if !decoded && !isUTF8Charset(charset) && utf8.Valid(content) {
+		raw, ok := latin1Bytes(b.Content)
+		if !ok {
+			return b.Content, nil
+		}
+		content = raw
+	}
+
+	return decodeCharset(charset, content)
+}
+
+// isUTF8Charset returns true for charsets that are (a subset of) UTF-8
+func isUTF8Charset(charset string) bool {
+	switch charset {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return true
+	}
+	return false
+}
+
+// latin1Bytes returns each character as a byte, or false if any character does not fit in a byte
+func latin1Bytes(text string) ([]byte, bool) {
+	raw := make([]byte, 0, len(text))
+	for _, r := range text {
+		if r > 0xFF {
+			return nil, false
+		}
+		raw = append(raw, byte(r))
+	}
+	return raw, true
+}
+
+// decodeTransfer undoes the content transfer encoding, returning true if there was one
+func decodeTransfer(encoding string, content string) ([]byte, bool, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		cleaned := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+				return -1
+			}
+			return r
+		}, content)
+		decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(cleaned, "="))
+		if err != nil {
+			return nil, false, errors.Wrap(err, "Unable to decode base64 body")
+		}
+		return decoded, true, nil
+	case "quoted-printable":
+		decoded, err := ioutil.ReadAll(quotedprintable.NewReader(strings.NewReader(content)))
+		if err != nil {
+			return nil, false, errors.Wrap(err, "Unable to decode quoted-printable body")
+		}
+		return decoded, true, nil
+	default:
+		// 7bit, 8bit, binary, or already decoded
+		return []byte(content), false, nil
+	}
+}
+
+// decodeCharset converts the content from the charset (with a lowercased label) into UTF-8
+func decodeCharset(label string, content []byte) (string, error) {
+	if isUTF8Charset(label) {
+		return validUTF8(content), nil
+	}
+	switch label {
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		return decodeSingleByte(content, nil), nil
+	case "windows-1252", "cp1252":
+		return decodeSingleByte(content, windows1252), nil
+	case "utf-16", "utf-16be":
+		return decodeUTF16(content, true), nil
+	case "utf-16le":
+		return decodeUTF16(content, false), nil
+	}
+
+	// Any other charset (ex: iso-2022-jp, gb2312, koi8-r, shift_jis, iso-8859-2)
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(content))
+	if err != nil {
+		return "", errors.Wrapf(err, "Unsupported charset: %s", label)
+	}
+	decoded, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", errors.Wrapf(err, "Unable to decode charset: %s", label)
+	}
+	return string(decoded), nil
+}
+
+// validUTF8 returns the content as a string, replacing any invalid UTF-8 with the replacement character
+func validUTF8(content []byte) string {
+	if utf8.Valid(content) {
+		return string(content)
+	}
+	var buf bytes.Buffer
+	for len(content) > 0 {
+		r, size := utf8.DecodeRune(content)
+		buf.WriteRune(r)
+		content = content[size:]
+	}
+	return buf.String()
+}
+
+// windows1252 are the characters for bytes 0x80 to 0x9F, which differ from iso-8859-1
+var windows1252 = []rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// decodeSingleByte decodes iso-8859-1, replacing bytes 0x80 to 0x9F with high if it is set
+func decodeSingleByte(content []byte, high []rune) string {
+	runes := make([]rune, len(content))
+	for i, c := range content {
+		if high != nil && c >= 0x80 && c <= 0x9F {
+			runes[i] = high[c-0x80]
+		} else {
+			runes[i] = rune(c)
+		}
+	}
+	return string(runes)
+}
+
+// decodeUTF16 decodes utf-16, using the byte order mark if there is one
+func decodeUTF16(content []byte, bigEndian bool) string {
+	if len(content) >= 2 {
+		switch {
+		case content[0] == 0xFE && content[1] == 0xFF:
+			bigEndian, content = true, content[2:]
+		case content[0] == 0xFF && content[1] == 0xFE:
+			bigEndian, content = false, content[2:]
+		}
+	}
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(content[2*i])<<8 | uint16(content[2*i+1])
+		} else {
+			units[i] = uint16(content[2*i+1])<<8 | uint16(content[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// BestBody returns the first body of the preferred media type that has content, trying each type in order.
+// With no preferred types, text/plain is preferred over text/html.
+func BestBody(bodies []MessageBody, preferred ...string) (MessageBody, bool) {
+	if len(preferred) == 0 {
+		preferred = []string{"text/plain", "text/html"}
+	}
+	for _, mediaType := range preferred {
+		for _, body := range bodies {
+			if body.MediaType() == mediaType && len(strings.TrimSpace(body.Content)) > 0 {
+				return body, true
+			}
+		}
+	}
+	return MessageBody{}, false
+}
+
+// Text returns the message text: the plain text body if there is one, or else the html body rendered as text
+func (m Message) Text() (string, error) {
+	body, ok := BestBody(m.Bodies)
+	if !ok {
+		return "", nil
+	}
+	text, err := body.Decode()
+	if err != nil {
+		return "", err
+	}
+	if body.MediaType() == "text/html" {
+		return HTMLToText(text), nil
+	}
+	return normalizeNewlines(text), nil
+}
+
+// Preview returns a single line preview of the message text, without quoted replies or the signature,
+// and shortened to at most maxLength characters (with an ellipsis) if maxLength is greater than zero
+func (m Message) Preview(maxLength int) (string, error) {
+	text, err := m.Text()
+	if err != nil {
+		return "", err
+	}
+	text, _ = SplitSignature(StripQuotedReply(text))
+	preview := strings.Join(strings.Fields(text), " ")
+
+	if runes := []rune(preview); maxLength > 0 && len(runes) > maxLength {
+		preview = strings.TrimSpace(string(runes[:maxLength-1])) + "…"
+	}
+	return preview, nil
+}
+
+// normalizeNewlines converts CRLF and CR line endings to LF
+func normalizeNewlines(text string) string {
+	return strings.Replace(strings.Replace(text, "\r\n", "\n", -1), "\r", "\n", -1)
+}
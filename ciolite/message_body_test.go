@@ -0,0 +1,120 @@
+package ciolite
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMessageBodyDecode tests decoding transfer encodings and charsets
+func TestMessageBodyDecode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		body     MessageBody
+		expected string
+	}{
+		{MessageBody{Type: "text/plain", Encoding: "quoted-printable", Content: "Looks good =E2=80=94 thanks!"}, "Looks good — thanks!"},
+		{MessageBody{Type: "text/plain; charset=iso-8859-1", Encoding: "base64", Content: "Y2Fm6Q==\r\n"}, "café"},
+		{MessageBody{Type: "text/plain", Charset: "windows-1252", Encoding: "quoted-printable", Content: "=93quoted=94 =80"}, "“quoted” €"},
+		{MessageBody{Type: "text/plain", Charset: "UTF-16", Encoding: "base64", Content: "//5oAGkA"}, "hi"},
+		{MessageBody{Type: "text/plain", Encoding: "8bit", Content: "caf\xe9"}, "café"},
+
+		// Other charsets
+		{MessageBody{Type: "text/plain", Charset: "koi8-r", Encoding: "8bit", Content: "\xf0\xd2\xc9\xd7\xc5\xd4"}, "Привет"},
+		{MessageBody{Type: "text/plain; charset=ISO-2022-JP", Encoding: "7bit", Content: "\x1b$B$3$s$K$A$O\x1b(B"}, "こんにちは"},
+		{MessageBody{Type: "text/plain", Charset: "shift_jis", Encoding: "base64", Content: "grGC8YLJgr+CzQ=="}, "こんにちは"},
+		{MessageBody{Type: "text/plain", Charset: "gb2312", Encoding: "quoted-printable", Content: "=C4=E3=BA=C3"}, "你好"},
+		{MessageBody{Type: "text/plain", Charset: "iso-8859-2", Encoding: "quoted-printable", Content: "=B3=F3d=BC"}, "łódź"},
+
+		// Json content is already unicode, whatever the original charset was
+		{MessageBody{Type: "text/plain", Charset: "ISO-8859-1", Content: "café"}, "café"},
+		{MessageBody{Type: "text/plain", Charset: "windows-1252", Content: "\u0093hi\u0094"}, "“hi”"},
+		{MessageBody{Type: "text/plain", Charset: "koi8-r", Content: "Привет"}, "Привет"},
+	}
+	for _, test := range tests {
+		if decoded, err := test.body.Decode(); err != nil || decoded != test.expected {
+			t.Error("Expected: ", test.expected, "; Got: ", decoded, "; With Error: ", err, "; For: ", test.body)
+		}
+	}
+
+	// Unknown charsets
+	body := MessageBody{Type: "text/plain", Charset: "x-unknown", Encoding: "base64", Content: "aGk="}
+	if _, err := body.Decode(); err == nil {
+		t.Error("Expected an unsupported charset error")
+	}
+}
+
+// TestMessageText tests choosing the best body, rendering html, and previews
+func TestMessageText(t *testing.T) {
+	t.Parallel()
+
+	html := `<html><head><title>x</title><style>p {color: red}</style></head><body>
+<p>Hi&nbsp;Jane,</p><div>See <a href="https://example.com/q3">the report</a> and
+<a href="https://example.com">https://example.com</a>.<br>Thanks</div>
+<ul><li>One</li><li>Two</li></ul>
+<blockquote><p>Earlier</p><p>message</p></blockquote>
+<pre>a  b
+c</pre><script>alert(1)</script></body></html>`
+
+	expected := strings.Join([]string{
+		"Hi Jane,",
+		"",
+		"See the report (https://example.com/q3) and https://example.com.",
+		"Thanks",
+		"",
+		"- One",
+		"- Two",
+		"",
+		"> Earlier",
+		">",
+		"> message",
+		"",
+		"a  b",
+		"c",
+	}, "\n")
+	if text := HTMLToText(html); text != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, text)
+	}
+
+	message := Message{Bodies: []MessageBody{
+		{Type: "text/html", Content: "<p>Html</p>"},
+		{Type: "text/plain", Content: ""},
+	}}
+	if text, err := message.Text(); err != nil || text != "Html" {
+		t.Error("Expected the html body, since the plain body is empty; Got: ", text, "; With Error: ", err)
+	}
+
+	message.Bodies = append(message.Bodies, MessageBody{Type: "text/plain; charset=utf-8", Content: "Sounds great,\r\nsee you then.\r\n\r\n" +
+		"-- \r\nJane Doe\r\nACME\r\n\r\nOn Mon, Jan 1, 2018 at 9:00 AM, John Smith <john@example.org>\r\nwrote:\r\n> Lunch on Friday?\r\n"})
+	if preview, err := message.Preview(0); err != nil || preview != "Sounds great, see you then." {
+		t.Error("Expected: Sounds great, see you then.; Got: ", preview, "; With Error: ", err)
+	}
+	if preview, err := message.Preview(10); err != nil || preview != "Sounds gr…" {
+		t.Error("Expected: Sounds gr…; Got: ", preview, "; With Error: ", err)
+	}
+}
+
+// TestStripQuotedReplyAndSplitSignature tests removing quoted replies and finding signatures
+func TestStripQuotedReplyAndSplitSignature(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text      string
+		body      string
+		signature string
+	}{
+		{"Yes.\n\n> Can you?\n> Thanks", "Yes.", ""},
+		{"Done\n\n-----Original Message-----\nFrom: John\nDid you?", "Done", ""},
+		{"Agreed\n\nFrom: John Smith\nSent: Monday, January 1, 2018\nSubject: Plan", "Agreed", ""},
+		{"Le lun. 1 janv. 2018, Jean a écrit :\n> Bonjour", "", ""},
+		{"Ok!\n\nSent from my iPhone\n", "Ok!", "Sent from my iPhone"},
+		{"Thanks\n--\nJane\nSent from my iPhone", "Thanks", "Jane\nSent from my iPhone"},
+		{"From the desk of Jane: hello", "From the desk of Jane: hello", ""},
+	}
+	for _, test := range tests {
+		body, signature := SplitSignature(StripQuotedReply(test.text))
+		if body != test.body || signature != test.signature {
+			t.Errorf("Expected: %q, %q; Got: %q, %q; For: %q", test.body, test.signature, body, signature, test.text)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ciolite
+
+// Unified message flags, shared by the folder message and webhook responses
+
+import (
+	"sort"
+	"strings"
+)
+
+// MessageFlag is an IMAP system flag (ex: \Seen), or a custom IMAP keyword (ex: $Important)
+type MessageFlag string
+
+// MessageFlag system flag values
+const (
+	FlagSeen     MessageFlag = `\Seen`
+	FlagAnswered MessageFlag = `\Answered`
+	FlagFlagged  MessageFlag = `\Flagged`
+	FlagDeleted  MessageFlag = `\Deleted`
+	FlagDraft    MessageFlag = `\Draft`
+)
+
+// IsKeyword returns true if this is a custom keyword, rather than one of the system flags
+func (flag MessageFlag) IsKeyword() bool {
+	switch flag {
+	case FlagSeen, FlagAnswered, FlagFlagged, FlagDeleted, FlagDraft:
+		return false
+	}
+	return true
+}
+
+// modifyParams returns the params that will set (or clear) only this flag
+func (flag MessageFlag) modifyParams(set bool, delimiter string) ModifyUserEmailAccountsFolderMessageFlagsParams {
+	value := "0"
+	if set {
+		value = "1"
+	}
+
+	params := ModifyUserEmailAccountsFolderMessageFlagsParams{Delimiter: delimiter}
+	switch flag {
+	case FlagSeen:
+		params.Seen = value
+	case FlagAnswered:
+		params.Answered = value
+	case FlagFlagged:
+		params.Flagged = value
+	case FlagDeleted:
+		params.Deleted = value
+	case FlagDraft:
+		params.Draft = value
+	default:
+		if set {
+			params.AddKeywords = string(flag)
+		} else {
+			params.RemoveKeywords = string(flag)
+		}
+	}
+	return params
+}
+
+// MessageFlags is the unified set of flags on a message,
+// whether it came from the flags endpoint, a message listing, or a webhook.
+type MessageFlags struct {
+	Seen     bool `json:"seen,omitempty"`
+	Answered bool `json:"answered,omitempty"`
+	Flagged  bool `json:"flagged,omitempty"`
+	Deleted  bool `json:"deleted,omitempty"`
+	Draft    bool `json:"draft,omitempty"`
+
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// MessageFlags returns the unified flags (Read is called Seen)
+func (flags UserEmailAccountsFolderMessageFlags) MessageFlags() MessageFlags {
+	return MessageFlags{
+		Seen:     flags.Read,
+		Answered: flags.Answered,
+		Flagged:  flags.Flagged,
+		Deleted:  flags.Deleted,
+		Draft:    flags.Draft,
+		Keywords: flags.Keywords,
+	}
+}
+
+// MessageFlags returns the unified flags
+func (flags WebhookMessageDataFlags) MessageFlags() MessageFlags {
+	return MessageFlags{
+		Seen:     flags.Seen,
+		Answered: flags.Answered,
+		Flagged:  flags.Flagged,
+		Draft:    flags.Draft,
+	}
+}
+
+// Has returns true if the system flag or keyword is set
+func (flags MessageFlags) Has(flag MessageFlag) bool {
+	switch flag {
+	case FlagSeen:
+		return flags.Seen
+	case FlagAnswered:
+		return flags.Answered
+	case FlagFlagged:
+		return flags.Flagged
+	case FlagDeleted:
+		return flags.Deleted
+	case FlagDraft:
+		return flags.Draft
+	}
+	for _, keyword := range flags.Keywords {
+		if strings.EqualFold(keyword, string(flag)) {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns every flag and keyword that is set, system flags first and then keywords in sorted order
+func (flags MessageFlags) List() []MessageFlag {
+	var list []MessageFlag
+	for _, flag := range []MessageFlag{FlagSeen, FlagAnswered, FlagFlagged, FlagDeleted, FlagDraft} {
+		if flags.Has(flag) {
+			list = append(list, flag)
+		}
+	}
+	keywords := append([]string(nil), flags.Keywords...)
+	sort.Slice(keywords, func(i, j int) bool {
+		return strings.ToLower(keywords[i]) < strings.ToLower(keywords[j])
+	})
+	for _, keyword := range keywords {
+		list = append(list, MessageFlag(keyword))
+	}
+	return list
+}
+
+// Equal returns true if both have the same flags and keywords set (keywords are compared case-insensitively)
+func (flags MessageFlags) Equal(other MessageFlags) bool {
+	a, b := flags.List(), other.List()
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(string(a[i]), string(b[i])) {
+			return false
+		}
+	}
+	return true
+}
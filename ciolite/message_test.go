@@ -0,0 +1,113 @@
+package ciolite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadFixture unmarshals the named json file from testdata into v
+func loadFixture(t *testing.T, name string, v interface{}) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal("Unable to read fixture: ", name, "; With Error: ", err)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		t.Fatal("Unable to unmarshal fixture: ", name, "; With Error: ", err)
+	}
+}
+
+// expectedFixtureMessage is the Message all fixtures should convert to,
+// before the fields that only some response shapes have are added
+func expectedFixtureMessage() Message {
+	return Message{
+		MessageID:  "<CAFx8kPz1Q7@mail.gmail.com>",
+		Subject:    "Re: Quarterly report",
+		InReplyTo:  "<CAFx8kPa0A1@mail.gmail.com>",
+		Folders:    []string{"INBOX", `\Important`},
+		References: []string{"<CAFx8kPa0A1@mail.gmail.com>"},
+		From:       []Address{{Email: "jane@example.com", Name: "Jane Doe"}},
+		To:         []Address{{Email: "john@example.org", Name: "John Smith"}},
+		Cc:         []Address{{Email: "finance@example.org"}},
+		ReplyTo:    []Address{{Email: "jane@example.com", Name: "Jane Doe"}},
+		PersonInfo: PersonInfo{"jane@example.com": {"thumbnail": "https://secure.gravatar.com/avatar/abc.jpg"}},
+		Flags:      MessageFlags{Seen: true, Flagged: true},
+		Attachments: []MessageAttachment{{
+			Type:               "application/pdf",
+			FileName:           "q3-report.pdf",
+			BodySection:        "2",
+			ContentDisposition: "attachment",
+			MessageID:          "<CAFx8kPz1Q7@mail.gmail.com>",
+			XAttachmentID:      "f_jk2m3n4o0",
+			Size:               48213,
+			AttachmentID:       1,
+		}},
+		SentAt:     1538489123,
+		ReceivedAt: 1538489125,
+	}
+}
+
+// TestMessageFromFolderAndAccountMessages tests converting folder and account messages to a Message
+func TestMessageFromFolderAndAccountMessages(t *testing.T) {
+	t.Parallel()
+
+	expected := expectedFixtureMessage()
+	expected.ReceivedHeaders = []string{"from mail-wr1-f41.google.com (mail-wr1-f41.google.com [209.85.221.41]) by mx.example.com"}
+	expected.ListHeaders = ListHeaders{}
+	expected.Bodies = []MessageBody{
+		{BodySection: "1.1", Type: "text/plain", Encoding: "quoted-printable", Content: "Looks good =E2=80=94 thanks!", Size: 27},
+		{BodySection: "1.2", Type: "text/html", Encoding: "quoted-printable", Content: "<p>Looks good =E2=80=94 thanks!</p>", Size: 34},
+	}
+
+	var folderMessage GetUsersEmailAccountFolderMessagesResponse
+	loadFixture(t, "folder_message.json", &folderMessage)
+	expected.ResourceURL = "https://api.context.io/lite/users/5a1f3c0e8d/email_accounts/0/folders/INBOX/messages/%3CCAFx8kPz1Q7%40mail.gmail.com%3E"
+	if message := folderMessage.Message(); !reflect.DeepEqual(message, expected) {
+		t.Errorf("Expected: %+v; Got: %+v", expected, message)
+	}
+
+	var accountMessage GetUsersEmailAccountMessagesResponse
+	loadFixture(t, "account_message.json", &accountMessage)
+	expected.ResourceURL = "https://api.context.io/lite/users/5a1f3c0e8d/email_accounts/0/messages/%3CCAFx8kPz1Q7%40mail.gmail.com%3E"
+	if message := accountMessage.Message(); !reflect.DeepEqual(message, expected) {
+		t.Errorf("Expected: %+v; Got: %+v", expected, message)
+	}
+}
+
+// TestMessageFromWebhook tests converting webhook message data to a Message
+func TestMessageFromWebhook(t *testing.T) {
+	t.Parallel()
+
+	source := WebhookMessageDataAccount{
+		Label:       "0",
+		Folder:      "INBOX",
+		UID:         4521,
+		ResourceURL: "https://api.context.io/lite/users/5a1f3c0e8d/email_accounts/0",
+	}
+
+	expected := expectedFixtureMessage()
+	expected.ReturnPath = []Address{{Email: "jane@example.com"}}
+	expected.Headers = map[string][]string{"In-Reply-To": {"<CAFx8kPa0A1@mail.gmail.com>"}}
+	expected.Sources = []WebhookMessageDataAccount{source}
+	expected.EmailAccounts = []WebhookMessageDataAccount{source}
+	expected.Attachments[0].MainFileName = "q3-report"
+	expected.Attachments[0].FileNameStructure = [][]string{{"q3-report", "main"}, {".pdf", "ext"}}
+	expected.Bodies = []MessageBody{
+		{BodySection: "1.1", Type: "text/plain", Charset: "UTF-8", Content: "Looks good — thanks!"},
+	}
+
+	var callback WebhookCallback
+	loadFixture(t, "webhook_message.json", &callback)
+	if message := callback.MessageData.Message(); !reflect.DeepEqual(message, expected) {
+		t.Errorf("Expected: %+v; Got: %+v", expected, message)
+	}
+
+	// A webhook without a from address, and with a false x_attachment_id
+	var data WebhookMessageData
+	Must(json.Unmarshal([]byte(`{"addresses": [], "files": [{"x_attachment_id": false}]}`), &data))
+	if message := data.Message(); message.From != nil || message.Attachments[0].XAttachmentID != "" {
+		t.Error("Expected no From and an empty XAttachmentID; Got: ", message)
+	}
+}
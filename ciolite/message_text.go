@@ -0,0 +1,300 @@
+package ciolite
+
+// Rendering html bodies as text, and stripping quoted replies and signatures from message text
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLToText renders an html body as readable plain text.
+// Scripts and styles are dropped, block elements become paragraphs, list items get a "- " prefix,
+// blockquotes are quoted with "> ", and links are followed by their url (if it differs from the link text).
+func HTMLToText(source string) string {
+
+	tokenizer := html.NewTokenizer(strings.NewReader(source))
+	w := &textWriter{}
+
+	var (
+		skip  int
+		links []string
+		texts []*bytes.Buffer
+	)
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return w.String()
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			w.text(token.Data)
+			for _, text := range texts {
+				text.WriteString(token.Data)
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch token.Data {
+			case "script", "style", "head", "title":
+				if tokenType == html.StartTagToken {
+					skip++
+				}
+			case "br":
+				w.newline()
+			case "li":
+				if w.line.Len() > 0 {
+					w.newline()
+				}
+				w.text("- ")
+			case "blockquote":
+				w.block()
+				w.quote++
+			case "pre":
+				w.block()
+				w.pre++
+			case "td", "th":
+				w.text(" ")
+			case "img":
+				if alt := attribute(token, "alt"); len(alt) > 0 {
+					w.text(alt)
+				}
+			case "a":
+				if tokenType == html.StartTagToken {
+					links = append(links, attribute(token, "href"))
+					texts = append(texts, &bytes.Buffer{})
+				}
+			default:
+				if blockElements[token.Data] {
+					w.block()
+				}
+			}
+
+		case html.EndTagToken:
+			switch token.Data {
+			case "script", "style", "head", "title":
+				if skip > 0 {
+					skip--
+				}
+			case "blockquote":
+				w.endQuote()
+			case "pre":
+				w.block()
+				if w.pre > 0 {
+					w.pre--
+				}
+			case "a":
+				if len(links) == 0 {
+					continue
+				}
+				href, text := links[len(links)-1], strings.TrimSpace(texts[len(texts)-1].String())
+				links, texts = links[:len(links)-1], texts[:len(texts)-1]
+				if len(href) > 0 && href != text && !strings.HasPrefix(href, "#") && !strings.HasPrefix(href, "mailto:") {
+					w.text(" (" + href + ")")
+				}
+			default:
+				if blockElements[token.Data] {
+					w.block()
+				}
+			}
+		}
+	}
+}
+
+// blockElements are rendered as separate paragraphs
+var blockElements = map[string]bool{
+	"p": true, "div": true, "table": true, "tr": true, "ul": true, "ol": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "header": true, "footer": true, "address": true,
+}
+
+// attribute returns the value of the token's attribute, or an empty string
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+// textWriter collects rendered lines, collapsing whitespace outside of pre elements
+type textWriter struct {
+	lines []string
+	line  bytes.Buffer
+
+	// quote is the current blockquote depth, and lineQuote the depth of the current line
+	quote     int
+	lineQuote int
+
+	pre   int
+	space bool
+}
+
+// text writes text to the current line
+func (w *textWriter) text(s string) {
+	if w.pre > 0 {
+		for i, part := range strings.Split(normalizeNewlines(s), "\n") {
+			if i > 0 {
+				w.newline()
+			}
+			w.write(part)
+		}
+		return
+	}
+
+	if len(s) > 0 && isSpace(s[0]) {
+		w.space = true
+	}
+	for _, word := range strings.Fields(s) {
+		if w.space && w.line.Len() > 0 {
+			w.line.WriteByte(' ')
+		}
+		w.write(word)
+		w.space = true
+	}
+	w.space = len(s) > 0 && isSpace(s[len(s)-1])
+}
+
+// write writes s to the current line, recording its quote depth if it is the start of the line
+func (w *textWriter) write(s string) {
+	if w.line.Len() == 0 {
+		w.lineQuote = w.quote
+	}
+	w.line.WriteString(s)
+}
+
+// newline ends the current line
+func (w *textWriter) newline() {
+	line := strings.TrimRight(w.line.String(), " ")
+	if w.line.Len() == 0 {
+		w.lineQuote = w.quote
+	}
+	w.lines = append(w.lines, strings.TrimRight(strings.Repeat("> ", w.lineQuote)+line, " "))
+	w.line.Reset()
+	w.space = false
+}
+
+// block ends the current line, and separates what follows with a single blank line
+func (w *textWriter) block() {
+	if w.line.Len() > 0 {
+		w.newline()
+	}
+	if n := len(w.lines); n > 0 && strings.Trim(w.lines[n-1], "> ") != "" {
+		w.line.Reset()
+		w.newline()
+	}
+}
+
+// endQuote ends the current blockquote, dropping any blank quoted lines at its end
+func (w *textWriter) endQuote() {
+	if w.line.Len() > 0 {
+		w.newline()
+	}
+	for n := len(w.lines); n > 0 && strings.Trim(w.lines[n-1], "> ") == ""; n-- {
+		w.lines = w.lines[:n-1]
+	}
+	if w.quote > 0 {
+		w.quote--
+	}
+	w.block()
+}
+
+// String returns the rendered text, without leading or trailing blank lines
+func (w *textWriter) String() string {
+	if w.line.Len() > 0 {
+		w.newline()
+	}
+	return strings.Trim(strings.Join(w.lines, "\n"), "\n")
+}
+
+// isSpace returns true for html whitespace
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+var (
+	// attributionLine matches reply attributions, such as "On Mon, Jan 1, 2018, Jane wrote:"
+	attributionLine = regexp.MustCompile(`(?i)^\s*(on|le|am|el|il)\b.*\b(wrote|a écrit|schrieb|escribió|ha scritto)\s*:\s*$`)
+
+	// originalMessageLine matches the separators clients put above the original message
+	originalMessageLine = regexp.MustCompile(`(?i)^\s*(-{2,}\s*original message\s*-{2,}|_{10,})\s*$`)
+
+	// headerBlockLine matches the start of an Outlook style quoted header block
+	headerBlockLine = regexp.MustCompile(`(?i)^\s*\*?from:\*?\s`)
+
+	// headerBlockNextLine matches the lines following headerBlockLine
+	headerBlockNextLine = regexp.MustCompile(`(?i)^\s*\*?(sent|date|to|subject):\*?\s`)
+
+	// mobileSignatureLine matches the signatures mobile and web clients add
+	mobileSignatureLine = regexp.MustCompile(`(?i)^\s*(sent from my .+|sent from (mail|outlook|yahoo mail|gmail)\b.*|get outlook for .+)$`)
+)
+
+// StripQuotedReply returns the text without the quoted message it replies to:
+// everything from the reply attribution (or original message separator, or quoted header block) on,
+// and any other lines quoted with ">".
+func StripQuotedReply(text string) string {
+
+	lines := strings.Split(normalizeNewlines(text), "\n")
+
+	for i, line := range lines {
+		cut := attributionLine.MatchString(line) || originalMessageLine.MatchString(line)
+
+		// Attributions are often wrapped onto a second line
+		if !cut && i+1 < len(lines) && len(strings.TrimSpace(line)) > 0 {
+			cut = attributionLine.MatchString(line + " " + lines[i+1])
+		}
+
+		// Outlook quotes the original headers instead of an attribution
+		if !cut && headerBlockLine.MatchString(line) && i+1 < len(lines) && headerBlockNextLine.MatchString(lines[i+1]) {
+			cut = true
+		}
+
+		if cut {
+			lines = lines[:i]
+			break
+		}
+	}
+
+	var kept []string
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), ">") {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.TrimRight(strings.Join(kept, "\n"), " \t\n")
+}
+
+// SplitSignature splits the text into the body and the signature. The signature starts at the last
+// "-- " delimiter line, or else is a trailing line added by a mobile client (ex: "Sent from my iPhone").
+// If there is no signature, the whole text is returned as the body.
+func SplitSignature(text string) (string, string) {
+
+	lines := strings.Split(normalizeNewlines(text), "\n")
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimRight(lines[i], " ") == "--" {
+			return strings.TrimRight(strings.Join(lines[:i], "\n"), " \t\n"), strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		}
+	}
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		if len(strings.TrimSpace(lines[i])) == 0 {
+			continue
+		}
+		if mobileSignatureLine.MatchString(lines[i]) {
+			return strings.TrimRight(strings.Join(lines[:i], "\n"), " \t\n"), strings.TrimSpace(lines[i])
+		}
+		break
+	}
+
+	return strings.TrimRight(text, " \t\n"), ""
+}
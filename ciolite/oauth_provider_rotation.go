@@ -0,0 +1,382 @@
+package ciolite
+
+// Resumable rotation of an OAuth provider: create the new provider, migrate every account to it, then delete the old one
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// OAuthProviderRotation is the checkpoint of a rotation, saved after every step so that it can be resumed
+type OAuthProviderRotation struct {
+	OldKey string `json:"old_key"`
+	NewKey string `json:"new_key"`
+
+	// Created is true once the new provider exists
+	Created bool `json:"created"`
+
+	// Migrated holds the accounts ("userID/label") moved to the new provider
+	Migrated map[string]bool `json:"migrated"`
+
+	// Failed holds the error of each account that could not be moved (retried on resume)
+	Failed map[string]string `json:"failed,omitempty"`
+
+	// Deleted is true once the old provider has been deleted
+	Deleted bool `json:"deleted"`
+
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+}
+
+// OAuthProviderRotationStore persists rotation checkpoints, keyed by the old provider's consumer key
+type OAuthProviderRotationStore interface {
+	// Load returns the checkpoint of the rotation away from oldKey, or nil if there is none
+	Load(oldKey string) (*OAuthProviderRotation, error)
+
+	// Save stores the checkpoint
+	Save(rotation OAuthProviderRotation) error
+}
+
+// MemoryOAuthProviderRotationStore is an OAuthProviderRotationStore kept in memory
+type MemoryOAuthProviderRotationStore struct {
+	mu        sync.Mutex
+	rotations map[string]OAuthProviderRotation
+}
+
+// NewMemoryOAuthProviderRotationStore returns an empty MemoryOAuthProviderRotationStore
+func NewMemoryOAuthProviderRotationStore() *MemoryOAuthProviderRotationStore {
+	return &MemoryOAuthProviderRotationStore{rotations: map[string]OAuthProviderRotation{}}
+}
+
+// Load returns the checkpoint of the rotation away from oldKey, or nil if there is none
+func (store *MemoryOAuthProviderRotationStore) Load(oldKey string) (*OAuthProviderRotation, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	rotation, ok := store.rotations[oldKey]
+	if !ok {
+		return nil, nil
+	}
+	rotation.Migrated = copyBoolMap(rotation.Migrated)
+	rotation.Failed = copyStringMap(rotation.Failed)
+	return &rotation, nil
+}
+
+// Save stores the checkpoint
+func (store *MemoryOAuthProviderRotationStore) Save(rotation OAuthProviderRotation) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	rotation.Migrated = copyBoolMap(rotation.Migrated)
+	rotation.Failed = copyStringMap(rotation.Failed)
+	store.rotations[rotation.OldKey] = rotation
+	return nil
+}
+
+// copyBoolMap returns a copy of the map
+func copyBoolMap(m map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// copyStringMap returns a copy of the map
+func copyStringMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// OAuthProviderRotationProgress is reported after every account is migrated (or fails to)
+type OAuthProviderRotationProgress struct {
+	UserID string
+	Label  string
+	Err    error
+
+	// Done is how many accounts have been migrated so far (including earlier runs), out of Total
+	Done  int
+	Total int
+}
+
+// OAuthProviderRotationOptions configure RotateOAuthProvider
+type OAuthProviderRotationOptions struct {
+	// Store keeps the checkpoint, defaults to a new MemoryOAuthProviderRotationStore (which cannot resume after a restart)
+	Store OAuthProviderRotationStore
+
+	// ShouldMigrate returns true for the accounts that use the old provider.
+	// CIO does not report which provider an account uses, so this defaults to the OAuth accounts of the mail service
+	// of the old provider's type (ex: Gmail and Google Apps accounts for GMAIL_OAUTH2, Outlook and Office 365 accounts
+	// for MSLIVECONNECT). It is required when the old provider's type is not recognized.
+	ShouldMigrate func(user GetUsersResponse, account GetUsersEmailAccountsResponse) bool
+
+	// RefreshToken, if set, returns the refresh token to send along with the new consumer key
+	// (needed when the new provider is a different OAuth client, which issues its own tokens)
+	RefreshToken func(userID string, label string) (string, error)
+
+	// KeepOld keeps the old provider after migrating every account, instead of deleting it
+	KeepOld bool
+
+	// OnProgress is called after every account
+	OnProgress func(progress OAuthProviderRotationProgress)
+
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// OAuthProviderInUseError is returned instead of deleting a provider that accounts still reference
+type OAuthProviderInUseError struct {
+	Key string
+
+	// Accounts are the accounts ("userID/label") still referencing the provider
+	Accounts []string
+}
+
+// Error returns a description of the provider still in use
+func (e OAuthProviderInUseError) Error() string {
+	return "CIO: OAuth provider " + e.Key + " is still referenced by " + strings.Join(e.Accounts, ", ")
+}
+
+// RotateOAuthProvider moves every account from the provider with oldKey to a new provider, and deletes the old one.
+// The new provider is created first, then each account is modified to use the new consumer key, and finally the old
+// provider is deleted (unless KeepOld is set), but only once no account still references it.
+// Progress is saved to the Store after every step, and calling RotateOAuthProvider again with the same oldKey resumes
+// the rotation, retrying any accounts that failed.
+func RotateOAuthProvider(client Interface, oldKey string, newProvider CreateOAuthProviderParams, options OAuthProviderRotationOptions) (OAuthProviderRotation, error) {
+
+	if options.Store == nil {
+		options.Store = NewMemoryOAuthProviderRotationStore()
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	if oldKey == newProvider.ProviderConsumerKey {
+		return OAuthProviderRotation{}, errors.New("CIO: The new OAuth provider must have a different consumer key than the old one")
+	}
+
+	stored, err := options.Store.Load(oldKey)
+	if err != nil {
+		return OAuthProviderRotation{}, errors.Wrap(err, "Unable to load OAuth provider rotation")
+	}
+	rotation := OAuthProviderRotation{OldKey: oldKey, NewKey: newProvider.ProviderConsumerKey, StartedAt: options.Now()}
+	if stored != nil {
+		rotation = *stored
+		if rotation.NewKey != newProvider.ProviderConsumerKey {
+			return rotation, errors.Errorf("CIO: A rotation from OAuth provider %s to %s is already in progress", oldKey, rotation.NewKey)
+		}
+	}
+	if rotation.Migrated == nil {
+		rotation.Migrated = map[string]bool{}
+	}
+	if rotation.Failed == nil {
+		rotation.Failed = map[string]string{}
+	}
+	if rotation.Deleted {
+		return rotation, nil
+	}
+
+	// Only the accounts of the old provider's mail service are migrated, not those of other providers
+	if options.ShouldMigrate == nil {
+		if options.ShouldMigrate, err = oauthProviderShouldMigrate(client, oldKey, options); err != nil {
+			return rotation, err
+		}
+	}
+
+	// Create the new provider
+	if !rotation.Created {
+		if err = createOAuthProvider(client, newProvider, options); err != nil {
+			return rotation, err
+		}
+		rotation.Created = true
+		if err = options.Store.Save(rotation); err != nil {
+			return rotation, errors.Wrap(err, "Unable to save OAuth provider rotation")
+		}
+	}
+
+	// Migrate the accounts
+	accounts, err := oauthProviderAccounts(client, options)
+	if err != nil {
+		return rotation, err
+	}
+	for _, account := range accounts {
+		key := account[0] + "/" + account[1]
+		if rotation.Migrated[key] {
+			continue
+		}
+
+		err = migrateOAuthProviderAccount(client, account[0], account[1], rotation.NewKey, options)
+		if err != nil {
+			rotation.Failed[key] = err.Error()
+		} else {
+			delete(rotation.Failed, key)
+			rotation.Migrated[key] = true
+		}
+		if saveErr := options.Store.Save(rotation); saveErr != nil {
+			return rotation, errors.Wrap(saveErr, "Unable to save OAuth provider rotation")
+		}
+
+		if options.OnProgress != nil {
+			options.OnProgress(OAuthProviderRotationProgress{UserID: account[0], Label: account[1], Err: err, Done: len(rotation.Migrated), Total: len(rotation.Migrated) + len(rotation.Failed) + remaining(accounts, rotation)})
+		}
+	}
+
+	if len(rotation.Failed) > 0 {
+		return rotation, errors.Errorf("CIO: Unable to migrate %d accounts to OAuth provider %s", len(rotation.Failed), rotation.NewKey)
+	}
+	if options.KeepOld {
+		rotation.CompletedAt = options.Now()
+		return rotation, options.Store.Save(rotation)
+	}
+
+	// Check nothing references the old provider (accounts may have been added meanwhile), then delete it
+	accounts, err = oauthProviderAccounts(client, options)
+	if err != nil {
+		return rotation, err
+	}
+	var inUse []string
+	for _, account := range accounts {
+		if key := account[0] + "/" + account[1]; !rotation.Migrated[key] {
+			inUse = append(inUse, key)
+		}
+	}
+	if len(inUse) > 0 {
+		return rotation, OAuthProviderInUseError{Key: oldKey, Accounts: inUse}
+	}
+
+	var response DeleteOAuthProviderResponse
+	response, err = client.DeleteOAuthProvider(oldKey)
+	if err == nil && !response.Success {
+		err = errors.New("Unable to delete OAuth provider. CIO returned 200 but with Success=false")
+	}
+	if err != nil && ErrorStatusCode(err) != 404 {
+		return rotation, err
+	}
+
+	rotation.Deleted = true
+	rotation.CompletedAt = options.Now()
+	return rotation, options.Store.Save(rotation)
+}
+
+// remaining returns how many of the accounts have neither been migrated nor failed
+func remaining(accounts [][2]string, rotation OAuthProviderRotation) int {
+	n := 0
+	for _, account := range accounts {
+		key := account[0] + "/" + account[1]
+		if _, failed := rotation.Failed[key]; !rotation.Migrated[key] && !failed {
+			n++
+		}
+	}
+	return n
+}
+
+// createOAuthProvider creates the new provider, treating one that already exists (from an earlier, interrupted run) as created
+func createOAuthProvider(client Interface, provider CreateOAuthProviderParams, options OAuthProviderRotationOptions) error {
+	response, err := client.CreateOAuthProvider(provider)
+	if err == nil && !response.Success {
+		err = errors.New("Unable to create OAuth provider. CIO returned 200 but with Success=false")
+	}
+	if err == nil {
+		return nil
+	}
+
+	if _, existsErr := client.GetOAuthProvider(provider.ProviderConsumerKey); existsErr == nil {
+		return nil
+	}
+	return err
+}
+
+// oauthProviderServices maps words of OAuth provider types to the accounts they authenticate:
+// accounts of one of the types, or on a server containing one of the words
+var oauthProviderServices = []struct {
+	providerTypes []string
+	accountTypes  []AccountType
+	servers       []string
+}{
+	{[]string{"gmail", "google"}, []AccountType{AccountTypeGmail, AccountTypeGoogleApps}, []string{"gmail", "google"}},
+	{[]string{"mslive", "msoffice", "microsoft", "outlook", "office365", "hotmail"}, []AccountType{AccountTypeOffice365}, []string{"outlook", "office365", "hotmail", "live.com"}},
+}
+
+// oauthProviderShouldMigrate returns the default ShouldMigrate for the old provider:
+// the OAuth accounts of the mail service of its type
+func oauthProviderShouldMigrate(client Interface, oldKey string, options OAuthProviderRotationOptions) (func(user GetUsersResponse, account GetUsersEmailAccountsResponse) bool, error) {
+	provider, err := client.GetOAuthProvider(oldKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to get the old OAuth provider")
+	}
+
+	providerType := strings.ToLower(provider.Type)
+	for _, service := range oauthProviderServices {
+		if !containsAnyWord(providerType, service.providerTypes) {
+			continue
+		}
+		accountTypes, servers := service.accountTypes, service.servers
+		return func(user GetUsersResponse, account GetUsersEmailAccountsResponse) bool {
+			if !account.AuthenticationType.IsOAuth() {
+				return false
+			}
+			for _, accountType := range accountTypes {
+				if account.Type == accountType {
+					return true
+				}
+			}
+			return containsAnyWord(strings.ToLower(account.Server), servers)
+		}, nil
+	}
+	return nil, errors.Errorf("CIO: Unrecognized OAuth provider type %q, ShouldMigrate is required", provider.Type)
+}
+
+// containsAnyWord returns true if text contains any of the words
+func containsAnyWord(text string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// oauthProviderAccounts returns the user id and label of every account that ShouldMigrate, sorted
+func oauthProviderAccounts(client Interface, options OAuthProviderRotationOptions) ([][2]string, error) {
+	users, err := listAllUsers(client)
+	if err != nil {
+		return nil, err
+	}
+	var accounts [][2]string
+	for _, user := range users {
+		for _, account := range user.EmailAccounts {
+			if options.ShouldMigrate(user, account) {
+				accounts = append(accounts, [2]string{user.ID, account.Label})
+			}
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i][0] != accounts[j][0] {
+			return accounts[i][0] < accounts[j][0]
+		}
+		return accounts[i][1] < accounts[j][1]
+	})
+	return accounts, nil
+}
+
+// migrateOAuthProviderAccount moves a single account to the new consumer key
+func migrateOAuthProviderAccount(client Interface, userID string, label string, newKey string, options OAuthProviderRotationOptions) error {
+	params := ModifyUserEmailAccountParams{ProviderConsumerKey: newKey}
+	if options.RefreshToken != nil {
+		token, err := options.RefreshToken(userID, label)
+		if err != nil {
+			return errors.Wrap(err, "Unable to get refresh token")
+		}
+		params.ProviderRefreshToken = token
+	}
+
+	response, err := client.ModifyUserEmailAccount(userID, label, params)
+	if err == nil && !response.Success {
+		err = errors.Errorf("Unable to modify email account. CIO returned 200 but with Success=false (feedback code: %s)", response.FeedbackCode)
+	}
+	return err
+}
@@ -0,0 +1,217 @@
+package ciolite
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestSimulatedRotateOAuthProvider tests RotateOAuthProvider with a simulated server, across interrupted runs
+func TestSimulatedRotateOAuthProvider(t *testing.T) {
+	t.Parallel()
+
+	cioLite, _, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var (
+		mu       sync.Mutex
+		created  int
+		deleted  []string
+		modified []string
+		failU2   = true
+		addU3    bool
+	)
+
+	mux.HandleFunc("/lite/oauth_providers", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.FormValue("provider_consumer_key") != "new" || r.FormValue("provider_consumer_secret") != "secret" {
+			t.Errorf("Unexpected provider: %s", r.Form.Encode())
+		}
+		created++
+		_, err := io.WriteString(w, `{"success": true, "provider_consumer_key": "new"}`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/oauth_providers/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == "GET" {
+			_, err := io.WriteString(w, `{"type": "GMAIL_OAUTH2", "provider_consumer_key": "old"}`)
+			Must(err)
+			return
+		}
+		deleted = append(deleted, r.URL.Path)
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		// u1/2 is on the Microsoft provider, so it is not migrated to the new Google provider
+		users := `{"id": "u1", "email_accounts": [{"label": "0", "type": "gmail", "authentication_type": "oauth2"}, {"label": "1", "type": "gmail", "authentication_type": "password"},
+				{"label": "2", "type": "imap", "server": "outlook.office365.com", "authentication_type": "oauth2"}]},
+			{"id": "u2", "email_accounts": [{"label": "0", "type": "gmail", "authentication_type": "oauth2"}]}`
+		if addU3 {
+			users += `, {"id": "u3", "email_accounts": [{"label": "0", "type": "googleapps", "authentication_type": "oauth2"}]}`
+		}
+		_, err := io.WriteString(w, "["+users+"]")
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.FormValue("provider_consumer_key") != "new" || r.FormValue("provider_refresh_token") != "token-"+r.URL.Path[12:14] {
+			t.Errorf("Unexpected migration: %s %s", r.URL.Path, r.Form.Encode())
+		}
+		modified = append(modified, r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/lite/users/u2/") {
+			if failU2 {
+				w.WriteHeader(500)
+				_, err := io.WriteString(w, `{"type": "error", "value": "boom"}`)
+				Must(err)
+				return
+			}
+			addU3 = true
+		}
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+
+	var progress []string
+	store := NewMemoryOAuthProviderRotationStore()
+	options := OAuthProviderRotationOptions{
+		Store:        store,
+		RefreshToken: func(userID string, label string) (string, error) { return "token-" + userID, nil },
+		OnProgress: func(p OAuthProviderRotationProgress) {
+			progress = append(progress, fmt.Sprintf("%s/%s %d/%d %v", p.UserID, p.Label, p.Done, p.Total, p.Err != nil))
+		},
+	}
+	provider := CreateOAuthProviderParams{Type: "GMAIL_OAUTH2", ProviderConsumerKey: "new", ProviderConsumerSecret: "secret"}
+
+	// First run: u2 fails, so the old provider is kept
+	rotation, err := RotateOAuthProvider(cioLite, "old", provider, options)
+	if err == nil || !rotation.Created || rotation.Deleted || len(rotation.Failed) != 1 || !rotation.Migrated["u1/0"] {
+		t.Errorf("Unexpected first run: %+v %v", rotation, err)
+	}
+	if expected := []string{"u1/0 1/2 false", "u2/0 1/2 true"}; !reflect.DeepEqual(progress, expected) {
+		t.Errorf("Expected progress %v, got %v", expected, progress)
+	}
+
+	// Second run: u2 is retried, but u3 appears meanwhile, so the old provider is still in use
+	mu.Lock()
+	failU2 = false
+	mu.Unlock()
+	rotation, err = RotateOAuthProvider(cioLite, "old", provider, options)
+	inUse, ok := err.(OAuthProviderInUseError)
+	if !ok || !reflect.DeepEqual(inUse.Accounts, []string{"u3/0"}) || rotation.Deleted || len(rotation.Failed) != 0 {
+		t.Errorf("Unexpected second run: %+v %v", rotation, err)
+	}
+
+	// Third run: u3 is migrated, and the old provider deleted
+	rotation, err = RotateOAuthProvider(cioLite, "old", provider, options)
+	if err != nil || !rotation.Deleted || rotation.CompletedAt.IsZero() || len(rotation.Migrated) != 3 {
+		t.Errorf("Unexpected third run: %+v %v", rotation, err)
+	}
+
+	// A completed rotation does nothing
+	if _, err = RotateOAuthProvider(cioLite, "old", provider, options); err != nil {
+		t.Error(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if created != 1 {
+		t.Errorf("Expected the provider to be created once, got %d", created)
+	}
+	if expected := []string{"/lite/oauth_providers/old"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("Expected deletes %v, got %v", expected, deleted)
+	}
+	expected := []string{"/lite/users/u1/email_accounts/0", "/lite/users/u2/email_accounts/0", "/lite/users/u2/email_accounts/0", "/lite/users/u3/email_accounts/0"}
+	if !reflect.DeepEqual(modified, expected) {
+		t.Errorf("Expected modifications %v, got %v", expected, modified)
+	}
+
+	// The same consumer key cannot be rotated to
+	if _, err = RotateOAuthProvider(cioLite, "new", provider, options); err == nil {
+		t.Error("Expected an error rotating to the same consumer key")
+	}
+}
+
+// TestSimulatedRotateOAuthProviderTwoProviders tests that rotating one provider leaves the accounts of another provider alone
+func TestSimulatedRotateOAuthProviderTwoProviders(t *testing.T) {
+	t.Parallel()
+
+	cioLite, _, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var (
+		mu       sync.Mutex
+		modified []string
+	)
+	providers := map[string]string{"google": "GMAIL_OAUTH2", "microsoft": "MSLIVECONNECT", "other": "SOMETHING_NEW"}
+
+	mux.HandleFunc("/lite/oauth_providers", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/oauth_providers/", func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/lite/oauth_providers/")
+		if r.Method == "GET" {
+			_, err := io.WriteString(w, `{"type": "`+providers[key]+`", "provider_consumer_key": "`+key+`"}`)
+			Must(err)
+			return
+		}
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users", func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.WriteString(w, `[{"id": "u1", "email_accounts": [
+				{"label": "gmail", "type": "gmail", "server": "imap.gmail.com", "authentication_type": "oauth2"},
+				{"label": "office", "type": "imap", "server": "outlook.office365.com", "authentication_type": "oauth2"},
+				{"label": "hotmail", "type": "imap", "server": "imap-mail.outlook.com", "authentication_type": "oauth2"},
+				{"label": "password", "type": "imap", "server": "outlook.office365.com", "authentication_type": "password"}]}]`)
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		modified = append(modified, r.URL.Path+" "+r.FormValue("provider_consumer_key"))
+		_, err := io.WriteString(w, `{"success": true}`)
+		Must(err)
+	})
+
+	// Rotating the Microsoft provider only migrates the Microsoft accounts
+	newMicrosoft := CreateOAuthProviderParams{Type: "MSLIVECONNECT", ProviderConsumerKey: "microsoft2", ProviderConsumerSecret: "secret"}
+	rotation, err := RotateOAuthProvider(cioLite, "microsoft", newMicrosoft, OAuthProviderRotationOptions{})
+	if err != nil || !rotation.Deleted || !reflect.DeepEqual(rotation.Migrated, map[string]bool{"u1/office": true, "u1/hotmail": true}) {
+		t.Errorf("Unexpected Microsoft rotation: %+v %v", rotation, err)
+	}
+
+	// Rotating the Google provider only migrates the Google account
+	newGoogle := CreateOAuthProviderParams{Type: "GMAIL_OAUTH2", ProviderConsumerKey: "google2", ProviderConsumerSecret: "secret"}
+	rotation, err = RotateOAuthProvider(cioLite, "google", newGoogle, OAuthProviderRotationOptions{})
+	if err != nil || !rotation.Deleted || !reflect.DeepEqual(rotation.Migrated, map[string]bool{"u1/gmail": true}) {
+		t.Errorf("Unexpected Google rotation: %+v %v", rotation, err)
+	}
+
+	mu.Lock()
+	expected := []string{
+		"/lite/users/u1/email_accounts/hotmail microsoft2",
+		"/lite/users/u1/email_accounts/office microsoft2",
+		"/lite/users/u1/email_accounts/gmail google2",
+	}
+	if !reflect.DeepEqual(modified, expected) {
+		t.Errorf("Expected modifications %v, got %v", expected, modified)
+	}
+	mu.Unlock()
+
+	// An unrecognized provider type requires ShouldMigrate
+	newOther := CreateOAuthProviderParams{Type: "SOMETHING_NEW", ProviderConsumerKey: "other2", ProviderConsumerSecret: "secret"}
+	if _, err = RotateOAuthProvider(cioLite, "other", newOther, OAuthProviderRotationOptions{}); err == nil || !strings.Contains(err.Error(), "ShouldMigrate") {
+		t.Errorf("Expected ShouldMigrate to be required, got %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package ciolite
+
+// Functional options for New
+
+import (
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Option configures a CioLite created with New (or NewTestCioLiteServer)
+type Option func(cio *CioLite) error
+
+// Logger is anything that can log, ex: *log.Logger or logrus.StandardLogger()
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// New returns a CIO Lite struct for accessing the CIO Lite API, with the options applied in order
+// on top of the defaults of NewCioLite. The key and secret are required.
+func New(key string, secret string, opts ...Option) (CioLite, error) {
+	if len(key) == 0 || len(secret) == 0 {
+		return CioLite{}, errors.New("CIO: Missing api key or secret")
+	}
+	cio := NewCioLite(key, secret)
+	if err := cio.apply(opts); err != nil {
+		return CioLite{}, err
+	}
+	return cio, nil
+}
+
+// apply applies the options in order
+func (cio *CioLite) apply(opts []Option) error {
+	for _, opt := range opts {
+		if err := opt(cio); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userAgent returns the UserAgent, or DefaultUserAgent
+func (cio CioLite) userAgent() string {
+	if len(cio.UserAgent) > 0 {
+		return cio.UserAgent
+	}
+	return DefaultUserAgent
+}
+
+// WithHost sets the host of the api, ex: https://api.context.io
+func WithHost(host string) Option {
+	return func(cio *CioLite) error {
+		u, err := url.Parse(host)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return errors.Errorf("CIO: Invalid host: %s", host)
+		}
+		cio.Host = strings.TrimSuffix(host, "/")
+		return nil
+	}
+}
+
+// WithHTTPClient sets the *http.Client, which may be shared with other clients
+func WithHTTPClient(client *http.Client) Option {
+	return func(cio *CioLite) error {
+		if client == nil {
+			return errors.New("CIO: Missing http client")
+		}
+		cio.HTTPClient = client
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each request attempt.
+// The *http.Client is copied first, so that a shared client is not changed.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cio *CioLite) error {
+		if timeout < 0 {
+			return errors.Errorf("CIO: Invalid timeout: %s", timeout)
+		}
+		client := http.Client{}
+		if cio.HTTPClient != nil {
+			client = *cio.HTTPClient
+		}
+		client.Timeout = timeout
+		cio.HTTPClient = &client
+		return nil
+	}
+}
+
+// WithRetry retries requests up to retries times, with a doubling backoff (starting at backoff, up to MaxRetryBackoff)
+// that is randomly shortened by up to half, so that clients failing together do not all retry together.
+// A 429 with a Retry-After header waits that long instead, or is not retried if that is longer than MaxRetryBackoff.
+// GET requests are retried when they fail without a response, with 429 Too Many Requests, or with a 5xx status code.
+// Other requests (POST, PUT, DELETE) are only retried on 429, because after a timeout or a 5xx CIO may already
+// have applied them (ex: a retried CreateUser could create a duplicate user); see WithRetryAllMethods.
+// Any PostRequestShouldRetryHook already set is still called, and can also ask for a retry.
+func WithRetry(retries int, backoff time.Duration) Option {
+	return withRetry(retries, backoff, false)
+}
+
+// WithRetryAllMethods is WithRetry, but also retries POST, PUT and DELETE requests that fail without a response
+// or with a 5xx status code. Only use it if repeating any of the requests made is harmless.
+func WithRetryAllMethods(retries int, backoff time.Duration) Option {
+	return withRetry(retries, backoff, true)
+}
+
+// withRetry returns the retry option, retrying requests other than GET's on errors other than 429 only if allMethods
+func withRetry(retries int, backoff time.Duration, allMethods bool) Option {
+	return func(cio *CioLite) error {
+		if retries < 0 || backoff < 0 {
+			return errors.Errorf("CIO: Invalid retry: %d times with backoff %s", retries, backoff)
+		}
+		previous := cio.PostRequestShouldRetryHook
+		cio.PostRequestShouldRetryHook = func(attempt int, userID string, label string, method string, url string, statusCode int, responseBody string, beforeAttempt time.Time, beforeAll time.Time, err error) bool {
+			if previous != nil && previous(attempt, userID, label, method, url, statusCode, responseBody, beforeAttempt, beforeAll, err) {
+				return true
+			}
+			if err == nil || attempt > retries {
+				return false
+			}
+			retriable := statusCode == 429
+			if allMethods || method == "GET" {
+				retriable = retriable || statusCode == 0 || statusCode >= 500
+			}
+			if !retriable {
+				return false
+			}
+			wait := retryBackoff(backoff, attempt)
+			if after, ok := retryAfter(err); ok && statusCode == 429 {
+				if after > MaxRetryBackoff {
+					return false
+				}
+				wait = after
+			}
+			time.Sleep(wait)
+			return true
+		}
+		return nil
+	}
+}
+
+// retryBackoff returns the backoff before retrying attempt: backoff doubled for each previous attempt,
+// up to MaxRetryBackoff, then shortened by a random amount of up to half
+func retryBackoff(backoff time.Duration, attempt int) time.Duration {
+	wait := MaxRetryBackoff
+	if attempt < 32 && backoff < MaxRetryBackoff>>uint(attempt-1) {
+		wait = backoff << uint(attempt-1)
+	}
+	if wait < 2 {
+		return wait
+	}
+	return wait - time.Duration(rand.Int63n(int64(wait/2)))
+}
+
+// WithLimiter sets the Limiter, which is waited on before every attempt of every request
+func WithLimiter(limiter Limiter) Option {
+	return func(cio *CioLite) error {
+		cio.Limiter = limiter
+		return nil
+	}
+}
+
+// WithCircuitBreaker sets the CircuitBreaker, which fails requests fast while their endpoint or account keeps failing
+func WithCircuitBreaker(circuitBreaker *CircuitBreaker) Option {
+	return func(cio *CioLite) error {
+		cio.CircuitBreaker = circuitBreaker
+		return nil
+	}
+}
+
+// WithLogger logs every request, response (up to its first 2000 characters), and error closing a response body.
+// Any hooks already set are still called.
+func WithLogger(logger Logger) Option {
+	return func(cio *CioLite) error {
+		if logger == nil {
+			return errors.New("CIO: Missing logger")
+		}
+
+		previousPre := cio.PreRequestHook
+		cio.PreRequestHook = func(userID string, label string, method string, url string, redactedBodyValues url.Values) {
+			logger.Printf("Creating new %s request to: %s with payload: %s\n", method, url, redactedBodyValues.Encode())
+			if previousPre != nil {
+				previousPre(userID, label, method, url, redactedBodyValues)
+			}
+		}
+
+		previousPost := cio.PostRequestShouldRetryHook
+		cio.PostRequestShouldRetryHook = func(attempt int, userID string, label string, method string, url string, statusCode int, responseBody string, beforeAttempt time.Time, beforeAll time.Time, err error) bool {
+			snippet := responseBody
+			if len(snippet) > 2000 {
+				snippet = snippet[:2000]
+			}
+			if err != nil {
+				logger.Printf("Received response from %s to: %s with status code: %d and error: %s and payload snippet: %s\n", method, url, statusCode, err, snippet)
+			} else {
+				logger.Printf("Received response from %s to: %s with status code: %d and payload snippet: %s\n", method, url, statusCode, snippet)
+			}
+			return previousPost != nil && previousPost(attempt, userID, label, method, url, statusCode, responseBody, beforeAttempt, beforeAll, err)
+		}
+
+		previousClose := cio.ResponseBodyCloseErrorHook
+		cio.ResponseBodyCloseErrorHook = func(err error) {
+			logger.Printf("Unable to close response body from CIO, with error: %s\n", err.Error())
+			if previousClose != nil {
+				previousClose(err)
+			}
+		}
+		return nil
+	}
+}
+
+// WithUserAgent sends "appName/appVersion Golang CIO Library" as the User-Agent header
+// (the version may be empty)
+func WithUserAgent(appName string, appVersion string) Option {
+	return func(cio *CioLite) error {
+		if len(appName) == 0 {
+			cio.UserAgent = ""
+			return nil
+		}
+		if len(appVersion) > 0 {
+			appName += "/" + appVersion
+		}
+		cio.UserAgent = appName + " " + DefaultUserAgent
+		return nil
+	}
+}
+
+// WithCompression asks for gzip or deflate compressed responses
+func WithCompression() Option {
+	return func(cio *CioLite) error {
+		cio.Compression = true
+		return nil
+	}
+}
+
+// WithMaxResponseBytes fails requests whose (decompressed) response is larger than maxBytes (zero for no limit)
+func WithMaxResponseBytes(maxBytes int64) Option {
+	return func(cio *CioLite) error {
+		if maxBytes < 0 {
+			return errors.Errorf("CIO: Invalid max response bytes: %d", maxBytes)
+		}
+		cio.MaxResponseBytes = maxBytes
+		return nil
+	}
+}
+
+// WithHighFanoutTransport uses NewHighFanoutTransport(maxIdleConnsPerHost).
+// The *http.Client is copied first (keeping its timeout), so that a shared client is not changed.
+func WithHighFanoutTransport(maxIdleConnsPerHost int) Option {
+	return func(cio *CioLite) error {
+		client := http.Client{Timeout: DefaultRequestTimeout}
+		if cio.HTTPClient != nil {
+			client = *cio.HTTPClient
+		}
+		client.Transport = NewHighFanoutTransport(maxIdleConnsPerHost)
+		cio.HTTPClient = &client
+		return nil
+	}
+}
+
+// WithPreRequestHook sets the PreRequestHook
+func WithPreRequestHook(hook func(string, string, string, string, url.Values)) Option {
+	return func(cio *CioLite) error {
+		cio.PreRequestHook = hook
+		return nil
+	}
+}
+
+// WithPostRequestShouldRetryHook sets the PostRequestShouldRetryHook
+func WithPostRequestShouldRetryHook(hook func(int, string, string, string, string, int, string, time.Time, time.Time, error) bool) Option {
+	return func(cio *CioLite) error {
+		cio.PostRequestShouldRetryHook = hook
+		return nil
+	}
+}
+
+// WithResponseBodyCloseErrorHook sets the ResponseBodyCloseErrorHook
+func WithResponseBodyCloseErrorHook(hook func(error)) Option {
+	return func(cio *CioLite) error {
+		cio.ResponseBodyCloseErrorHook = hook
+		return nil
+	}
+}
@@ -0,0 +1,220 @@
+package ciolite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TestNew tests the validation of New and its options
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New("", "secret"); err == nil {
+		t.Error("Expected an error for a missing key")
+	}
+	if _, err := New("key", "secret", WithHost("not a host")); err == nil {
+		t.Error("Expected an error for an invalid host")
+	}
+
+	shared := &http.Client{Timeout: time.Minute}
+	cioLite, err := New("key", "secret", WithHost("https://example.com/"), WithHTTPClient(shared), WithTimeout(time.Second), WithUserAgent("myapp", "1.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cioLite.Host != "https://example.com" || cioLite.HTTPClient.Timeout != time.Second || shared.Timeout != time.Minute {
+		t.Error("Expected the host and timeout to be set, without changing the shared client; Got: ", cioLite.Host, cioLite.HTTPClient.Timeout, shared.Timeout)
+	}
+	if cioLite.UserAgent != "myapp/1.2 Golang CIO Library" {
+		t.Error("Expected the user agent to be set; Got: ", cioLite.UserAgent)
+	}
+}
+
+// TestSimulatedNewOptions tests the retry, logger, and user agent options against a simulated server
+func TestSimulatedNewOptions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		attempts  = map[string]int{}
+		userAgent string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		userID := strings.TrimPrefix(r.URL.Path, "/lite/users/")
+		attempts[userID]++
+		userAgent = r.Header.Get("User-Agent")
+		switch {
+		case userID != "u1":
+			w.WriteHeader(http.StatusNotFound)
+		case attempts[userID] < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			Must(json.NewEncoder(w).Encode(GetUsersResponse{ID: userID}))
+		}
+	})
+	getAttempts := func(userID string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts[userID]
+	}
+
+	logger := &TestLogger{Buffer: &bytes.Buffer{}}
+	cioLite, testServer := NewTestCioLiteServer(mux, WithLogger(logger), WithRetry(2, time.Millisecond), WithUserAgent("myapp", ""))
+	defer testServer.Close()
+
+	// Server errors are retried
+	user, err := cioLite.GetUser("u1")
+	if err != nil || user.ID != "u1" || getAttempts("u1") != 3 {
+		t.Error("Expected success after 3 attempts; Got: ", user, " after ", getAttempts("u1"), " attempts; With Error: ", err, "; With Log: ", logger.String())
+	}
+	if n := strings.Count(logger.String(), "Received response"); n != 3 {
+		t.Error("Expected 3 logged responses; Got: ", n, "; With Log: ", logger.String())
+	}
+
+	// Client errors are not retried
+	if _, err = cioLite.GetUser("u2"); ErrorStatusCode(err) != 404 || getAttempts("u2") != 1 {
+		t.Error("Expected a single 404 attempt; Got: ", getAttempts("u2"), "; With Error: ", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if userAgent != "myapp Golang CIO Library" {
+		t.Error("Expected the user agent to be sent; Got: ", userAgent)
+	}
+}
+
+// TestSimulatedRetryMethods tests that only GET requests are retried after a 5xx, unless WithRetryAllMethods
+func TestSimulatedRetryMethods(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		status, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/lite/users/"))
+		Must(err)
+		attempts[r.Method+" "+r.URL.Path]++
+		w.WriteHeader(status)
+	})
+	getAttempts := func(method string, status string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts[method+" /lite/users/"+status]
+	}
+
+	cioLite, testServer := NewTestCioLiteServer(mux, WithRetry(2, time.Millisecond))
+	defer testServer.Close()
+
+	// GET is retried after a 5xx, and 429's are retried for every method
+	if _, err := cioLite.GetUser("503"); ErrorStatusCode(err) != 503 || getAttempts("GET", "503") != 3 {
+		t.Error("Expected a GET to be retried after a 503; Got: ", getAttempts("GET", "503"), "; With Error: ", err)
+	}
+	if _, err := cioLite.DeleteUser("429"); ErrorStatusCode(err) != 429 || getAttempts("DELETE", "429") != 3 {
+		t.Error("Expected a DELETE to be retried after a 429; Got: ", getAttempts("DELETE", "429"), "; With Error: ", err)
+	}
+
+	// Other methods may already have been applied after a 5xx, so are not retried
+	if _, err := cioLite.DeleteUser("503"); ErrorStatusCode(err) != 503 || getAttempts("DELETE", "503") != 1 {
+		t.Error("Expected a DELETE not to be retried after a 503; Got: ", getAttempts("DELETE", "503"), "; With Error: ", err)
+	}
+
+	// Unless opted into
+	allMethods, testServerAllMethods := NewTestCioLiteServer(mux, WithRetryAllMethods(2, time.Millisecond))
+	defer testServerAllMethods.Close()
+	if _, err := allMethods.DeleteUser("502"); ErrorStatusCode(err) != 502 || getAttempts("DELETE", "502") != 3 {
+		t.Error("Expected a DELETE to be retried after a 502 with WithRetryAllMethods; Got: ", getAttempts("DELETE", "502"), "; With Error: ", err)
+	}
+}
+
+// TestSimulatedRetryAfter tests that a 429's Retry-After is waited, unless it is longer than MaxRetryBackoff
+func TestSimulatedRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		after := strings.TrimPrefix(r.URL.Path, "/lite/users/")
+		attempts[after]++
+		if attempts[after] > 1 {
+			_, _ = w.Write([]byte(`{"id": "` + after + `"}`))
+			return
+		}
+		w.Header().Set("Retry-After", after)
+		w.WriteHeader(429)
+	})
+	getAttempts := func(after string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts[after]
+	}
+
+	cioLite, testServer := NewTestCioLiteServer(mux, WithRetry(2, time.Millisecond))
+	defer testServer.Close()
+
+	before := time.Now()
+	if user, err := cioLite.GetUser("1"); err != nil || user.ID != "1" || getAttempts("1") != 2 || time.Since(before) < time.Second {
+		t.Error("Expected a retry after the Retry-After; Got: ", user, getAttempts("1"), time.Since(before), "; With Error: ", err)
+	}
+
+	if _, err := cioLite.GetUser("3600"); ErrorStatusCode(err) != 429 || getAttempts("3600") != 1 {
+		t.Error("Expected no retry after a Retry-After longer than MaxRetryBackoff; Got: ", getAttempts("3600"), "; With Error: ", err)
+	}
+	if err := (RequestError{retryAfterError{errors.New("CIO: Status Code >= 400"), time.Hour}, ErrorMetaData{}}); !strings.HasPrefix(err.Error(), "CIO: Status Code >= 400; ") || err.Cause().Error() != "CIO: Status Code >= 400" {
+		t.Error("Expected a Retry-After not to change the error; Got: ", err, "; With Cause: ", err.Cause())
+	}
+}
+
+// TestRetryBackoff tests that the backoff doubles, is capped at MaxRetryBackoff, and is shortened by up to half
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	for attempt, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 7: MaxRetryBackoff, 100: MaxRetryBackoff} {
+		for i := 0; i < 100; i++ {
+			if wait := retryBackoff(time.Second, attempt); wait > max || wait < max/2 {
+				t.Fatal("Expected a backoff between ", max/2, " and ", max, "; Got: ", wait, "; For attempt: ", attempt)
+			}
+		}
+	}
+	if wait := retryBackoff(0, 3); wait != 0 {
+		t.Error("Expected no backoff; Got: ", wait)
+	}
+}
+
+// TestParseRetryAfter tests parsing Retry-After headers in seconds and as http dates
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	if after, ok := parseRetryAfter("120"); !ok || after != 2*time.Minute {
+		t.Error("Expected 2m; Got: ", after, ok)
+	}
+	if after, ok := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || after < 59*time.Minute || after > time.Hour {
+		t.Error("Expected about 1h; Got: ", after, ok)
+	}
+	if after, ok := parseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)); !ok || after != 0 {
+		t.Error("Expected 0 for a past date; Got: ", after, ok)
+	}
+	for _, header := range []string{"", "-1", "soon"} {
+		if after, ok := parseRetryAfter(header); ok {
+			t.Error("Expected no Retry-After; Got: ", after, "; For: ", header)
+		}
+	}
+}
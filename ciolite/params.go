@@ -4,101 +4,363 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
-// formValues returns valid FormValues for CIO
-func formValues(cioFormValueParams interface{}) url.Values {
+// FormValueMarshaler is implemented by parameter types that encode themselves.
+// An empty string is treated as the zero value (omitted if the field is omitempty).
+type FormValueMarshaler interface {
+	MarshalFormValue() (string, error)
+}
+
+var (
+	formValueMarshalerType = reflect.TypeOf((*FormValueMarshaler)(nil)).Elem()
+	timeType               = reflect.TypeOf(time.Time{})
+	durationType           = reflect.TypeOf(time.Duration(0))
+)
+
+// formValues returns valid FormValues for CIO.
+// Supported field types are strings, bools (sent as 0 or 1), ints, uints, floats, time.Time (sent as a unix timestamp),
+// time.Duration (sent as seconds), FormValueMarshalers, pointers to any of these (nil is omitted, so that ex: false
+// can be sent explicitly), slices of any of these (sent as repeated keys, or joined by commas with the "comma" tag option),
+// and structs (embedded structs are flattened, other fields' keys are prefixed as parent[child]).
+func formValues(cioFormValueParams interface{}) (url.Values, error) {
 
 	// Values
 	values := url.Values{}
 
 	// If uninitialized, return empty url.Values
 	if cioFormValueParams == nil {
-		return values
+		return values, nil
 	}
 
-	// dynamically iterate through struct fields
 	refVal := reflect.ValueOf(cioFormValueParams)
-	refType := reflect.TypeOf(cioFormValueParams)
-	for i, numFields := 0, refVal.NumField(); i < numFields; i++ {
-		fieldValue := refVal.Field(i)
-		fieldType := refType.Field(i)
-
-		// dynamically choose how to fill the values based on field type
-		// and set the key to the json tag name
-		switch fieldValue.Kind() {
-
-		case reflect.String:
-			v := fieldValue.String()
-			if len(v) > 0 || !jsonOmitempty(fieldType) {
-				values.Set(jsonName(fieldType), v)
+	for refVal.Kind() == reflect.Ptr {
+		if refVal.IsNil() {
+			return values, nil
+		}
+		refVal = refVal.Elem()
+	}
+	if refVal.Kind() != reflect.Struct {
+		return values, errors.Errorf("Unexpected parameters type: %s", refVal.Type())
+	}
+
+	encoder, err := cachedStructEncoder(refVal.Type())
+	if err != nil {
+		return values, err
+	}
+	return values, encoder.encode(values, "", refVal)
+}
+
+// queryString returns a query string
+func queryString(cioQueryValueParams interface{}) (string, error) {
+
+	// Encode parameters
+	values, err := formValues(cioQueryValueParams)
+	if err != nil {
+		return "", err
+	}
+	encoded := values.Encode()
+	if encoded == "" {
+		return encoded, nil
+	}
+
+	// Format
+	return fmt.Sprintf("?%s", encoded), nil
+}
+
+// structEncoder encodes the fields of a struct type
+type structEncoder struct {
+	fields []fieldEncoder
+}
+
+// fieldEncoder encodes a single struct field
+type fieldEncoder struct {
+	index     int
+	name      string
+	omitempty bool
+	comma     bool
+	pointer   bool
+
+	// rules are parsed from the field's valid tag
+	rules []validationRule
+
+	// nested is set for struct fields, and flatten for embedded ones
+	nested  *structEncoder
+	flatten bool
+
+	// encode returns the field's values, and false if the field holds its zero value
+	encode func(v reflect.Value) ([]string, bool, error)
+}
+
+// encoderCache holds the encoder (or error) of every struct type seen, so that types are only reflected on once
+var encoderCache = struct {
+	sync.RWMutex
+	encoders map[reflect.Type]*structEncoder
+	errors   map[reflect.Type]error
+}{
+	encoders: map[reflect.Type]*structEncoder{},
+	errors:   map[reflect.Type]error{},
+}
+
+// cachedStructEncoder returns the cached encoder of the struct type, building it the first time
+func cachedStructEncoder(t reflect.Type) (*structEncoder, error) {
+	encoderCache.RLock()
+	encoder, err := encoderCache.encoders[t], encoderCache.errors[t]
+	encoderCache.RUnlock()
+	if encoder != nil || err != nil {
+		return encoder, err
+	}
+
+	encoder, err = newStructEncoder(t)
+
+	encoderCache.Lock()
+	if err != nil {
+		encoderCache.errors[t] = err
+	} else {
+		encoderCache.encoders[t] = encoder
+	}
+	encoderCache.Unlock()
+
+	return encoder, err
+}
+
+// newStructEncoder builds the encoder of the struct type
+func newStructEncoder(t reflect.Type) (*structEncoder, error) {
+	encoder := &structEncoder{}
+
+	for i, numFields := 0, t.NumField(); i < numFields; i++ {
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			// unexported
+			continue
+		}
+
+		field := fieldEncoder{index: i}
+
+		// Embedded structs are flattened into their parent (unless they are named in a json tag)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && len(sf.Tag.Get("json")) == 0 {
+			nested, err := newStructEncoder(sf.Type)
+			if err != nil {
+				return nil, err
 			}
+			field.nested, field.flatten = nested, true
+			encoder.fields = append(encoder.fields, field)
+			continue
+		}
 
-		case reflect.Bool:
-			v := fieldValue.Bool()
-			if v || !jsonOmitempty(fieldType) {
-				// boolean values are set to 0 or 1
-				if v {
-					values.Set(jsonName(fieldType), "1")
-				} else {
-					values.Set(jsonName(fieldType), "0")
-				}
+		name, err := jsonName(sf)
+		if err != nil {
+			return nil, err
+		}
+		if name == "-" {
+			continue
+		}
+		field.name = name
+		field.omitempty = jsonOmitempty(sf)
+		field.comma = jsonTagContains(jsonTagOptions(sf), "comma")
+		field.pointer = sf.Type.Kind() == reflect.Ptr
+		if field.rules, err = parseValidTag(sf.Tag.Get("valid")); err != nil {
+			return nil, errors.Wrapf(err, "Parameter %s", sf.Name)
+		}
+
+		// Nested structs are prefixed by their parent's name
+		if sf.Type.Kind() == reflect.Struct && sf.Type != timeType && !sf.Type.Implements(formValueMarshalerType) {
+			nested, err := newStructEncoder(sf.Type)
+			if err != nil {
+				return nil, err
 			}
+			field.nested = nested
+			encoder.fields = append(encoder.fields, field)
+			continue
+		}
 
-		case reflect.Int:
-			v := fieldValue.Int()
-			if v != 0 || !jsonOmitempty(fieldType) {
-				values.Set(jsonName(fieldType), fmt.Sprintf("%d", v))
+		field.encode, err = newValuesEncoder(sf.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Parameter %s", sf.Name)
+		}
+		encoder.fields = append(encoder.fields, field)
+	}
+
+	return encoder, nil
+}
+
+// encode sets the values of the struct's fields, prefixing their keys with prefix[...] if it is set
+func (encoder *structEncoder) encode(values url.Values, prefix string, v reflect.Value) error {
+	for _, field := range encoder.fields {
+		fieldValue := v.Field(field.index)
+
+		name := field.name
+		if len(prefix) > 0 && !field.flatten {
+			name = prefix + "[" + name + "]"
+		} else if field.flatten {
+			name = prefix
+		}
+
+		if field.nested != nil {
+			if err := field.nested.encode(values, name, fieldValue); err != nil {
+				return err
 			}
+			continue
+		}
+
+		// nil pointers are always omitted
+		if field.pointer && fieldValue.IsNil() {
+			continue
+		}
+
+		encoded, nonZero, err := field.encode(fieldValue)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to encode parameter %s", name)
+		}
+		if !nonZero && field.omitempty {
+			continue
+		}
 
+		switch {
+		case field.comma:
+			values.Set(name, strings.Join(encoded, ","))
+		case len(encoded) == 0:
+			if !field.omitempty {
+				values.Set(name, "")
+			}
 		default:
-			panic("Unexpected parameter type: " + fieldValue.Kind().String())
+			for _, e := range encoded {
+				values.Add(name, e)
+			}
 		}
 	}
-
-	return values
+	return nil
 }
 
-// queryString returns a query string
-func queryString(cioQueryValueParams interface{}) string {
+// newValuesEncoder returns the encoder of a field type
+func newValuesEncoder(t reflect.Type) (func(v reflect.Value) ([]string, bool, error), error) {
 
-	// Encode parameters
-	encoded := formValues(cioQueryValueParams).Encode()
-	if encoded == "" {
-		return encoded
+	switch {
+	case t.Implements(formValueMarshalerType):
+		return func(v reflect.Value) ([]string, bool, error) {
+			if v.Kind() == reflect.Ptr && v.IsNil() {
+				return nil, false, nil
+			}
+			s, err := v.Interface().(FormValueMarshaler).MarshalFormValue()
+			return []string{s}, len(s) > 0, err
+		}, nil
+
+	case t.Kind() == reflect.Ptr:
+		elem, err := newValuesEncoder(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		// A set pointer is always sent, even if it points to a zero value
+		return func(v reflect.Value) ([]string, bool, error) {
+			if v.IsNil() {
+				return nil, false, nil
+			}
+			encoded, _, err := elem(v.Elem())
+			return encoded, true, err
+		}, nil
+
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		elem, err := newValuesEncoder(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) ([]string, bool, error) {
+			var encoded []string
+			for i := 0; i < v.Len(); i++ {
+				e, _, err := elem(v.Index(i))
+				if err != nil {
+					return nil, false, err
+				}
+				encoded = append(encoded, e...)
+			}
+			return encoded, len(encoded) > 0, nil
+		}, nil
+
+	case t == timeType:
+		return func(v reflect.Value) ([]string, bool, error) {
+			tm := v.Interface().(time.Time)
+			if tm.IsZero() {
+				return []string{"0"}, false, nil
+			}
+			return []string{strconv.FormatInt(tm.Unix(), 10)}, true, nil
+		}, nil
+
+	case t == durationType:
+		return func(v reflect.Value) ([]string, bool, error) {
+			d := time.Duration(v.Int())
+			return []string{strconv.FormatInt(int64(d/time.Second), 10)}, d != 0, nil
+		}, nil
 	}
 
-	// Format
-	return fmt.Sprintf("?%s", encoded)
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value) ([]string, bool, error) {
+			s := v.String()
+			return []string{s}, len(s) > 0, nil
+		}, nil
+
+	case reflect.Bool:
+		// boolean values are set to 0 or 1
+		return func(v reflect.Value) ([]string, bool, error) {
+			if v.Bool() {
+				return []string{"1"}, true, nil
+			}
+			return []string{"0"}, false, nil
+		}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value) ([]string, bool, error) {
+			i := v.Int()
+			return []string{strconv.FormatInt(i, 10)}, i != 0, nil
+		}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(v reflect.Value) ([]string, bool, error) {
+			i := v.Uint()
+			return []string{strconv.FormatUint(i, 10)}, i != 0, nil
+		}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) ([]string, bool, error) {
+			f := v.Float()
+			return []string{strconv.FormatFloat(f, 'f', -1, t.Bits())}, f != 0, nil
+		}, nil
+	}
+
+	return nil, errors.Errorf("Unexpected parameter type: %s", t)
 }
 
 // jsonName returns the json name based on the json tag of the struct field
-func jsonName(sf reflect.StructField) string {
+func jsonName(sf reflect.StructField) (string, error) {
 	jsonTag := sf.Tag.Get("json")
 	indexComma := strings.Index(jsonTag, ",")
 	if len(jsonTag) == 0 || indexComma == 0 {
-		// Impossible situation: they all have a name tag
-		panic(fmt.Sprintf("Parameter %s missing json name tag", sf.Name))
+		return "", errors.Errorf("Parameter %s missing json name tag", sf.Name)
 	}
 	if indexComma > 0 {
-		return jsonTag[:indexComma]
+		return jsonTag[:indexComma], nil
 	}
-	return jsonTag
+	return jsonTag, nil
 }
 
-// jsonOmitempty returns true if json tags of this field include "omitempty"
-func jsonOmitempty(sf reflect.StructField) bool {
+// jsonTagOptions returns the options of the json tag of the struct field (after the name tag)
+func jsonTagOptions(sf reflect.StructField) string {
 	jsonTag := sf.Tag.Get("json")
-	indexComma := strings.Index(jsonTag, ",")
-	if len(jsonTag) == 0 || indexComma == 0 {
-		// Impossible situation: they all have a name tag
-		panic(fmt.Sprintf("Parameter %s missing json name tag", sf.Name))
-	}
-	if indexComma > 0 {
-		return jsonTagContains(jsonTag[indexComma+1:], "omitempty")
+	if indexComma := strings.Index(jsonTag, ","); indexComma >= 0 {
+		return jsonTag[indexComma+1:]
 	}
-	return false
+	return ""
+}
+
+// jsonOmitempty returns true if json tags of this field include "omitempty"
+func jsonOmitempty(sf reflect.StructField) bool {
+	return jsonTagContains(jsonTagOptions(sf), "omitempty")
 }
 
 // jsonTagContains returns true of the JSON tag options (after the name tag)
@@ -1,9 +1,15 @@
 package ciolite
 
 import (
+	"io"
+	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 // TestFormValues tests that the form values function returns the correct url.Values
@@ -42,7 +48,10 @@ func TestFormValuesAndQueryString(t *testing.T) {
 		"string_full":           []string{"hello world"},
 	}
 
-	formValues := formValues(params)
+	formValues, err := formValues(params)
+	if err != nil {
+		t.Error("Expected no error; Got: ", err)
+	}
 
 	if !reflect.DeepEqual(formValues, expectedFormValues) {
 		t.Error("Expected form values: ", expectedFormValues, "; Got: ", formValues)
@@ -50,9 +59,165 @@ func TestFormValuesAndQueryString(t *testing.T) {
 
 	expectedQueryString := "?bool_always_include=0&bool_true=1&int_always_include=0&int_large=8194723&string_always_include=&string_full=hello+world"
 
-	queryString := queryString(params)
+	queryString, err := queryString(params)
+	if err != nil {
+		t.Error("Expected no error; Got: ", err)
+	}
 
 	if queryString != expectedQueryString {
 		t.Error("Expected query string: ", expectedQueryString, "; Got: ", queryString)
 	}
 }
+
+// testStatus is a FormValueMarshaler
+type testStatus int
+
+// MarshalFormValue returns the status name
+func (s testStatus) MarshalFormValue() (string, error) {
+	switch s {
+	case 0:
+		return "", nil
+	case 1:
+		return "OK", nil
+	}
+	return "", errors.New("unknown status")
+}
+
+// TestFormValuesRichTypes tests pointers, slices, times, durations, marshalers, and nested structs
+func TestFormValuesRichTypes(t *testing.T) {
+	t.Parallel()
+
+	type Page struct {
+		Limit  int `json:"limit,omitempty"`
+		Offset int `json:"offset,omitempty"`
+	}
+
+	f, yes := false, true
+	var zero int64
+	params := struct {
+		Page
+		BoolFalse  *bool         `json:"bool_false,omitempty"`
+		BoolTrue   *bool         `json:"bool_true"`
+		BoolNil    *bool         `json:"bool_nil"`
+		Int64Zero  *int64        `json:"int64_zero,omitempty"`
+		Int64      int64         `json:"int64,omitempty"`
+		Uint       uint          `json:"uint,omitempty"`
+		Repeated   []string      `json:"repeated,omitempty"`
+		Comma      []int         `json:"comma,omitempty,comma"`
+		EmptyComma []int         `json:"empty_comma,omitempty,comma"`
+		Since      time.Time     `json:"since,omitempty"`
+		Before     time.Time     `json:"before,omitempty"`
+		Timeout    time.Duration `json:"timeout,omitempty"`
+		Status     testStatus    `json:"status,omitempty"`
+		StatusZero testStatus    `json:"status_zero,omitempty"`
+		Filter     struct {
+			From string `json:"from,omitempty"`
+		} `json:"filter"`
+		Skipped string `json:"-"`
+		private string
+	}{
+		Page:      Page{Limit: 10},
+		BoolFalse: &f,
+		BoolTrue:  &yes,
+		Int64Zero: &zero,
+		Int64:     1 << 40,
+		Uint:      7,
+		Repeated:  []string{"a", "b"},
+		Comma:     []int{1, 2, 3},
+		Since:     time.Unix(1500000000, 0),
+		Timeout:   90 * time.Second,
+		Status:    1,
+		Skipped:   "skipped",
+		private:   "private",
+	}
+	params.Filter.From = "jane@example.com"
+
+	expected := url.Values{
+		"limit":        []string{"10"},
+		"bool_false":   []string{"0"},
+		"bool_true":    []string{"1"},
+		"int64_zero":   []string{"0"},
+		"int64":        []string{"1099511627776"},
+		"uint":         []string{"7"},
+		"repeated":     []string{"a", "b"},
+		"comma":        []string{"1,2,3"},
+		"since":        []string{"1500000000"},
+		"timeout":      []string{"90"},
+		"status":       []string{"OK"},
+		"filter[from]": []string{"jane@example.com"},
+	}
+
+	actual, err := formValues(params)
+	if err != nil || !reflect.DeepEqual(actual, expected) {
+		t.Error("Expected form values: ", expected, "; Got: ", actual, "; With Error: ", err)
+	}
+
+	// Pointers to params are encoded too, and nil ones are empty
+	actual, err = formValues(&params)
+	if err != nil || !reflect.DeepEqual(actual, expected) {
+		t.Error("Expected form values: ", expected, "; Got: ", actual, "; With Error: ", err)
+	}
+	var nilParams *GetUsersParams
+	if actual, err = formValues(nilParams); err != nil || len(actual) != 0 {
+		t.Error("Expected empty form values; Got: ", actual, "; With Error: ", err)
+	}
+
+	// Marshaler errors are returned
+	params.Status = 2
+	if _, err = formValues(params); err == nil || !strings.Contains(err.Error(), "unknown status") {
+		t.Error("Expected marshaler error; Got: ", err)
+	}
+}
+
+// TestFormValuesErrors tests that unsupported parameters return errors instead of panicking
+func TestFormValuesErrors(t *testing.T) {
+	t.Parallel()
+
+	unsupported := struct {
+		Values map[string]string `json:"values"`
+	}{}
+	if _, err := formValues(unsupported); err == nil || !strings.Contains(err.Error(), "Unexpected parameter type") {
+		t.Error("Expected unsupported type error; Got: ", err)
+	}
+
+	untagged := struct {
+		Name string
+	}{}
+	if _, err := formValues(untagged); err == nil || !strings.Contains(err.Error(), "missing json name tag") {
+		t.Error("Expected missing tag error; Got: ", err)
+	}
+
+	// The error is cached along with the encoder
+	if _, err := queryString(untagged); err == nil {
+		t.Error("Expected missing tag error from cache")
+	}
+
+	if _, err := formValues("not a struct"); err == nil {
+		t.Error("Expected an error for a non-struct")
+	}
+}
+
+// TestSimulatedInvalidParams tests that requests with invalid params return a RequestError without being sent
+func TestSimulatedInvalidParams(t *testing.T) {
+	t.Parallel()
+
+	cioLite, _, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/lite/users", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Unexpected request")
+		_, err := io.WriteString(w, `[]`)
+		Must(err)
+	})
+
+	request := ClientRequest{
+		Method:      "GET",
+		Path:        "/lite/users",
+		QueryValues: struct{ Bad chan int }{},
+	}
+	var response []GetUsersResponse
+	err := cioLite.doFormRequest(request, &response)
+	if _, ok := err.(RequestError); !ok || ErrorMethod(err) != "GET" || !strings.Contains(err.Error(), "Invalid query values") {
+		t.Error("Expected RequestError; Got: ", err)
+	}
+}
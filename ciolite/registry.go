@@ -0,0 +1,142 @@
+package ciolite
+
+// Registry of CioLite clients, for using several CIO apps (api keys) at once
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Registry holds named CioLite clients, one per CIO app (ex: per region or per product),
+// which share the Template's settings (ex: HTTPClient and its transport, Limiter, and hooks).
+// Tenants can be routed to an app, and callbacks are validated with the right app's secret.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	// Template is copied into every client when it is added (changing it later does not change existing clients).
+	// Its api key and secret are ignored.
+	Template CioLite
+
+	// DefaultApp, if set, is used for tenants that have not been routed to an app
+	DefaultApp string
+
+	mu      sync.RWMutex
+	apps    map[string]CioLite
+	tenants map[string]string
+}
+
+// NewRegistry returns an empty Registry, whose clients will share a single *http.Client with DefaultRequestTimeout
+func NewRegistry() *Registry {
+	return &Registry{
+		Template: CioLite{
+			Host:       DefaultHost,
+			HTTPClient: &http.Client{Timeout: DefaultRequestTimeout},
+		},
+	}
+}
+
+// Add adds (or replaces) the app with this name, and returns its client
+func (registry *Registry) Add(name string, key string, secret string) CioLite {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	client := registry.Template
+	client.apiKey = key
+	client.apiSecret = secret
+
+	if registry.apps == nil {
+		registry.apps = map[string]CioLite{}
+	}
+	registry.apps[name] = client
+	return client
+}
+
+// Remove removes the app with this name, along with the tenants routed to it
+func (registry *Registry) Remove(name string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	delete(registry.apps, name)
+	for tenant, app := range registry.tenants {
+		if app == name {
+			delete(registry.tenants, tenant)
+		}
+	}
+}
+
+// App returns the client of the app with this name
+func (registry *Registry) App(name string) (CioLite, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	client, ok := registry.apps[name]
+	return client, ok
+}
+
+// Apps returns the names of all apps, sorted
+func (registry *Registry) Apps() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	names := make([]string, 0, len(registry.apps))
+	for name := range registry.apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Route routes a tenant (ex: a customer or user id of your own) to the app with this name, which must have been added
+func (registry *Registry) Route(tenant string, app string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.apps[app]; !ok {
+		return errors.Errorf("CIO: Unknown app %s for tenant %s", app, tenant)
+	}
+	if registry.tenants == nil {
+		registry.tenants = map[string]string{}
+	}
+	registry.tenants[tenant] = app
+	return nil
+}
+
+// ForTenant returns the name and client of the app the tenant is routed to, or of the DefaultApp
+func (registry *Registry) ForTenant(tenant string) (string, CioLite, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	app, ok := registry.tenants[tenant]
+	if !ok {
+		app = registry.DefaultApp
+	}
+	if len(app) == 0 {
+		return "", CioLite{}, errors.Errorf("CIO: Tenant %s is not routed to any app", tenant)
+	}
+	client, ok := registry.apps[app]
+	if !ok {
+		return "", CioLite{}, errors.Errorf("CIO: Unknown app %s for tenant %s", app, tenant)
+	}
+	return app, client, nil
+}
+
+// ValidateCallback returns true if this Webhook Callback or User Account Status Callback authenticates
+// with the secret of the app with this name (ex: the app the callback url was registered for)
+func (registry *Registry) ValidateCallback(app string, token string, signature string, timestamp int) bool {
+	client, ok := registry.App(app)
+	return ok && client.ValidateCallback(token, signature, timestamp)
+}
+
+// CallbackApp returns the name of the app whose secret this Webhook Callback or User Account Status Callback
+// authenticates with, for when the callback url does not say which app it is for.
+// Returns false if it does not authenticate with any app.
+func (registry *Registry) CallbackApp(token string, signature string, timestamp int) (string, bool) {
+	for _, name := range registry.Apps() {
+		if registry.ValidateCallback(name, token, signature, timestamp) {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,114 @@
+package ciolite
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// countingLimiter is a Limiter that counts its waits
+type countingLimiter struct {
+	mu    sync.Mutex
+	waits int
+}
+
+// Wait counts the wait
+func (limiter *countingLimiter) Wait() {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.waits++
+}
+
+// TestSimulatedRegistry tests that a Registry's clients use their own api key, and share the Template's settings
+func TestSimulatedRegistry(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	mux.HandleFunc("/lite/users/u1", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, key := range []string{"us-key", "eu-key"} {
+			if strings.Contains(r.Header.Get("Authorization"), `oauth_consumer_key="`+key+`"`) {
+				keys = append(keys, key)
+			}
+		}
+		Must(json.NewEncoder(w).Encode(GetUsersResponse{ID: "u1"}))
+	})
+
+	limiter := &countingLimiter{}
+	registry := NewRegistry()
+	registry.Template = cioLite
+	registry.Template.Limiter = limiter
+	registry.Add("us", "us-key", "us-secret")
+	registry.Add("eu", "eu-key", "eu-secret")
+	registry.DefaultApp = "us"
+	Must(registry.Route("acme", "eu"))
+
+	if err := registry.Route("acme", "asia"); err == nil {
+		t.Error("Expected an error routing to an unknown app")
+	}
+	if apps := registry.Apps(); !reflect.DeepEqual(apps, []string{"eu", "us"}) {
+		t.Error("Expected: [eu us]; Got: ", apps)
+	}
+
+	for _, tenant := range []string{"acme", "other"} {
+		_, client, err := registry.ForTenant(tenant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = client.GetUser("u1"); err != nil {
+			t.Error("Expected no error; Got: ", err, "; With Log: ", logger.String())
+		}
+	}
+
+	expected := []string{"eu-key", "us-key"}
+	if !reflect.DeepEqual(keys, expected) || limiter.waits != 2 {
+		t.Error("Expected: ", expected, " with 2 waits; Got: ", keys, " with ", limiter.waits, " waits")
+	}
+
+	registry.DefaultApp = ""
+	if _, _, err := registry.ForTenant("other"); err == nil {
+		t.Error("Expected an error for a tenant without an app")
+	}
+}
+
+// TestRegistryCallbacks tests validating callbacks with the right app's secret
+func TestRegistryCallbacks(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.Add("us", "us-key", "us-secret")
+	registry.Add("eu", "eu-key", "eu-secret")
+
+	timestamp := 1500000000
+	signature := hashHmac(sha256.New, strconv.Itoa(timestamp)+"token", "eu-secret")
+
+	if !registry.ValidateCallback("eu", "token", signature, timestamp) {
+		t.Error("Expected the callback to validate for eu")
+	}
+	if registry.ValidateCallback("us", "token", signature, timestamp) || registry.ValidateCallback("asia", "token", signature, timestamp) {
+		t.Error("Expected the callback to not validate for other apps")
+	}
+	if app, ok := registry.CallbackApp("token", signature, timestamp); !ok || app != "eu" {
+		t.Error("Expected: eu; Got: ", app, ok)
+	}
+	if app, ok := registry.CallbackApp("token", "bad", timestamp); ok {
+		t.Error("Expected no app; Got: ", app)
+	}
+
+	registry.Remove("eu")
+	if _, ok := registry.CallbackApp("token", signature, timestamp); ok {
+		t.Error("Expected no app after removing it")
+	}
+}
@@ -3,10 +3,11 @@ package ciolite
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,8 +15,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-// clientRequest defines information that can be used to make a request
-type clientRequest struct {
+// ClientRequest defines information that can be used to make a request.
+// UserID and AccountLabel are only passed along to the hooks (ex: for logging).
+type ClientRequest struct {
 	Method       string
 	Path         string
 	FormValues   interface{}
@@ -24,20 +26,51 @@ type clientRequest struct {
 	AccountLabel string
 }
 
-// doFormRequest makes the actual request
-func (cio CioLite) doFormRequest(request clientRequest, result interface{}) error {
+// DoFormRequest signs and makes a request to any CIO api (ex: the 2.0 api, see package cio),
+// unmarshaling the response into result, with the same params encoding, validation, hooks, and errors as all CIO Lite calls.
+func (cio CioLite) DoFormRequest(request ClientRequest, result interface{}) error {
+	return cio.doFormRequest(request, result)
+}
+
+// doFormRequest makes the actual request.
+// The params are validated first, and a ValidationError is returned (without sending anything) if they are invalid.
+func (cio CioLite) doFormRequest(request ClientRequest, result interface{}) error {
 
 	// url.QueryEscape turns spaces into +, and we need to turn them into %20
 	// but we can't get rid of url.QueryEscape because it turns / into %2F for delimited folder names
 	escapedPath := strings.Replace(request.Path, "+", "%20", -1)
 
+	// Validate the params, before sending anything
+	if err := validateParams(request.QueryValues); err != nil {
+		return err
+	}
+	if err := validateParams(request.FormValues); err != nil {
+		return err
+	}
+
 	// Construct the url
-	cioURL := cio.Host + escapedPath + queryString(request.QueryValues)
+	query, err := queryString(request.QueryValues)
+	if err != nil {
+		return RequestError{errors.Wrap(err, "CIO: Invalid query values"), ErrorMetaData{Method: request.Method, URL: cio.Host + escapedPath}}
+	}
+	cioURL := cio.Host + escapedPath + query
 
 	// Construct the body
-	bodyValues := formValues(request.FormValues)
+	bodyValues, err := formValues(request.FormValues)
+	if err != nil {
+		return RequestError{errors.Wrap(err, "CIO: Invalid form values"), ErrorMetaData{Method: request.Method, URL: cioURL}}
+	}
 	bodyString := bodyValues.Encode()
 
+	// Fail fast while a circuit is open (ex: the account's IMAP server is down)
+	var circuitKeys []string
+	if cio.CircuitBreaker != nil {
+		circuitKeys = cio.CircuitBreaker.keys(request)
+		if err := cio.CircuitBreaker.allow(circuitKeys...); err != nil {
+			return RequestError{err, ErrorMetaData{Method: request.Method, URL: cioURL}}
+		}
+	}
+
 	// Before-Request Hook Function (logging)
 	if cio.PreRequestHook != nil {
 		cio.PreRequestHook(request.UserID, request.AccountLabel, request.Method, cioURL, redactBodyValues(bodyValues))
@@ -46,25 +79,32 @@ func (cio CioLite) doFormRequest(request clientRequest, result interface{}) erro
 	var (
 		statusCode int
 		resBody    string
-		err        error
 	)
 
 	beforeAll := time.Now().UTC()
 	for i := 1; ; i++ {
+		waitLimiter(cio.Limiter)
 		beforeAttempt := time.Now().UTC()
 		statusCode, resBody, err = cio.createAndSendRequest(request, cioURL, bodyString, bodyValues, result)
+		// Streamed elements can not be taken back, so a stream is not retried once elements have been passed on
+		if streamStarted(result) {
+			break
+		}
 		// After-Request Hook Function (logging)
 		if cio.PostRequestShouldRetryHook == nil || !cio.PostRequestShouldRetryHook(i, request.UserID, request.AccountLabel, request.Method, cioURL, statusCode, resBody, beforeAttempt, beforeAll, err) {
 			break
 		}
 	}
 
+	for _, key := range circuitKeys {
+		cio.CircuitBreaker.record(key, err)
+	}
 	return err
 }
 
 // createAndSendRequest creates the body io.Reader, the *http.Request, and sends the request, logging the response.
 // Returns the status code, the response body, and any error
-func (cio CioLite) createAndSendRequest(request clientRequest, cioURL string, bodyString string, bodyValues url.Values, result interface{}) (int, string, error) {
+func (cio CioLite) createAndSendRequest(request ClientRequest, cioURL string, bodyString string, bodyValues url.Values, result interface{}) (int, string, error) {
 
 	var bodyReader io.Reader
 	if len(bodyString) > 0 {
@@ -82,7 +122,7 @@ func (cio CioLite) createAndSendRequest(request clientRequest, cioURL string, bo
 }
 
 // createRequest creates the *http.Request object
-func (cio CioLite) createRequest(request clientRequest, cioURL string, bodyReader io.Reader, bodyValues url.Values) (*http.Request, error) {
+func (cio CioLite) createRequest(request ClientRequest, cioURL string, bodyReader io.Reader, bodyValues url.Values) (*http.Request, error) {
 	// Construct the request
 	httpReq, err := http.NewRequest(request.Method, cioURL, bodyReader)
 	if err != nil {
@@ -97,7 +137,10 @@ func (cio CioLite) createRequest(request clientRequest, cioURL string, bodyReade
 	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	httpReq.Header.Set("Accept", "application/json")
 	httpReq.Header.Set("Accept-Charset", "utf-8")
-	httpReq.Header.Set("User-Agent", "Golang CIO Library")
+	httpReq.Header.Set("User-Agent", cio.userAgent())
+	if cio.Compression {
+		httpReq.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
 	httpReq.Header.Set("Authorization", client.AuthorizationHeader(nil, request.Method, httpReq.URL, bodyValues))
 
 	return httpReq, nil
@@ -119,18 +162,34 @@ func (cio CioLite) sendRequest(httpReq *http.Request, result interface{}, cioURL
 		}
 	}()
 
-	resBody, err := ioutil.ReadAll(res.Body)
+	// Streamed json arrays are decoded element by element, rather than read whole (errors are read whole)
+	if stream, ok := result.(*streamResult); ok && res.StatusCode < 400 {
+		return res.StatusCode, "", cio.decodeStream(res, stream, httpReq.Method, cioURL)
+	}
+
+	resBody, err := cio.readResponseBody(res)
 	resBodyString := string(resBody)
+	if IsResponseTooLarge(err) {
+		return res.StatusCode, "", RequestError{err, ErrorMetaData{Method: httpReq.Method, URL: cioURL, StatusCode: res.StatusCode}}
+	}
 	if err != nil {
 		return res.StatusCode, resBodyString, RequestError{errors.Wrap(err, "CIO: Could not read response"), ErrorMetaData{Method: httpReq.Method, URL: cioURL, StatusCode: res.StatusCode, Payload: resBodyString}}
 	}
 
 	// Unmarshal result
-	err = json.Unmarshal(resBody, &result)
+	if raw, ok := result.(rawResponse); ok && res.StatusCode < 300 {
+		err = raw.unmarshalRaw(resBody)
+	} else {
+		err = json.Unmarshal(resBody, &result)
+	}
 
 	// Return own error if Status Code >= 400
 	if res.StatusCode >= 400 {
-		return res.StatusCode, resBodyString, RequestError{errors.New("CIO: Status Code >= 400"), ErrorMetaData{Method: httpReq.Method, URL: cioURL, StatusCode: res.StatusCode, Payload: resBodyString}}
+		statusErr := errors.New("CIO: Status Code >= 400")
+		if after, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok && res.StatusCode == 429 {
+			statusErr = retryAfterError{statusErr, after}
+		}
+		return res.StatusCode, resBodyString, RequestError{statusErr, ErrorMetaData{Method: httpReq.Method, URL: cioURL, StatusCode: res.StatusCode, Payload: resBodyString}}
 	}
 
 	// Return Unmarshal error (if any) if Status Code is < 400
@@ -140,6 +199,11 @@ func (cio CioLite) sendRequest(httpReq *http.Request, result interface{}, cioURL
 	return res.StatusCode, resBodyString, nil
 }
 
+// rawResponse is a result that unmarshals successful responses itself, as they may not be json
+type rawResponse interface {
+	unmarshalRaw(body []byte) error
+}
+
 // redactBodyValues returns a copy of the body values redacted
 func redactBodyValues(bodyValues url.Values) url.Values {
 
@@ -165,3 +229,55 @@ func redactBodyValues(bodyValues url.Values) url.Values {
 
 	return redactedValues
 }
+
+// retryAfterError is the error of a 429 response with a Retry-After header, kept for withRetry.
+// It prints and unwraps (with errors.Cause) exactly as the error it wraps.
+type retryAfterError struct {
+	error
+	after time.Duration
+}
+
+// Cause returns the wrapped error
+func (e retryAfterError) Cause() error {
+	return e.error
+}
+
+// Format formats the wrapped error, keeping its stacktrace
+func (e retryAfterError) Format(s fmt.State, verb rune) {
+	if formatter, ok := e.error.(fmt.Formatter); ok {
+		formatter.Format(s, verb)
+		return
+	}
+	_, _ = io.WriteString(s, e.Error())
+}
+
+// retryAfter returns how long the response of a failed request asked to wait before retrying, if it did
+func retryAfter(err error) (time.Duration, bool) {
+	if requestErr, ok := err.(RequestError); ok {
+		if afterErr, ok := requestErr.Err.(retryAfterError); ok {
+			return afterErr.after, true
+		}
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an http date
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if len(header) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+	if after := time.Until(date); after > 0 {
+		return after, true
+	}
+	return 0, true
+}
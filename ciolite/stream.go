@@ -0,0 +1,70 @@
+package ciolite
+
+// Streaming decoding of json array responses, one element at a time
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// streamResult is passed as the result of doFormRequest to decode a json array response element by element,
+// instead of reading the whole response first
+type streamResult struct {
+	// next returns a pointer to decode the next element into, and a func to call once it has been decoded
+	next func() (interface{}, func() error)
+
+	// started is set once an element has been decoded, after which the request can not be retried
+	started bool
+}
+
+// streamStarted returns true if the result is a stream, and elements have already been passed on
+func streamStarted(result interface{}) bool {
+	stream, ok := result.(*streamResult)
+	return ok && stream.started
+}
+
+// decodeStream decodes a json array response, passing on each element as soon as it is decoded.
+// Errors returned by the callback are returned as is.
+func (cio CioLite) decodeStream(res *http.Response, stream *streamResult, method string, cioURL string) error {
+	metaData := ErrorMetaData{Method: method, URL: cioURL, StatusCode: res.StatusCode}
+
+	body, closeBody, err := cio.responseBody(res)
+	if err != nil {
+		return RequestError{errors.Wrap(err, "CIO: Could not read response"), metaData}
+	}
+	defer closeBody()
+
+	decoder := json.NewDecoder(body)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return streamDecodeError(err, metaData)
+	}
+
+	for decoder.More() {
+		element, yield := stream.next()
+		if err := decoder.Decode(element); err != nil {
+			return streamDecodeError(err, metaData)
+		}
+		stream.started = true
+		if err := yield(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return streamDecodeError(err, metaData)
+	}
+	return nil
+}
+
+// streamDecodeError returns the RequestError for a stream that could not be decoded
+func streamDecodeError(err error, metaData ErrorMetaData) error {
+	if IsResponseTooLarge(err) {
+		return RequestError{err, metaData}
+	}
+	if err == nil {
+		err = errors.New("expected a json array")
+	}
+	return RequestError{errors.Wrap(err, "CIO: Could not unmarshal payload"), metaData}
+}
@@ -0,0 +1,157 @@
+package ciolite
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TestSimulatedStreamUserEmailAccountsFolderMessages tests streaming a listing of messages with a simulated server
+func TestSimulatedStreamUserEmailAccountsFolderMessages(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages", func(w http.ResponseWriter, r *http.Request) {
+		Must(json.NewEncoder(w).Encode([]GetUsersEmailAccountFolderMessagesResponse{
+			{MessageID: "<1@example.com>", Subject: "One"},
+			{MessageID: "<2@example.com>", Subject: "Two"},
+			{MessageID: "<3@example.com>", Subject: "Three"},
+		}))
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/Object/messages", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"type": "error"}`))
+		Must(err)
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/Missing/messages", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"type": "error", "value": "Folder not found"}`))
+		Must(err)
+	})
+
+	// Every message, in order
+	var subjects []string
+	err := cioLite.StreamUserEmailAccountsFolderMessages("u1", "0", "INBOX", GetUserEmailAccountsFolderMessageParams{}, func(message GetUsersEmailAccountFolderMessagesResponse) error {
+		subjects = append(subjects, message.Subject)
+		return nil
+	})
+	expected := []string{"One", "Two", "Three"}
+	if err != nil || !reflect.DeepEqual(subjects, expected) {
+		t.Error("Expected: ", expected, "; Got: ", subjects, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+
+	// The callback's error stops the stream
+	stop := errors.New("stop")
+	subjects = nil
+	err = cioLite.StreamUserEmailAccountsFolderMessages("u1", "0", "INBOX", GetUserEmailAccountsFolderMessageParams{}, func(message GetUsersEmailAccountFolderMessagesResponse) error {
+		subjects = append(subjects, message.Subject)
+		return stop
+	})
+	if err != stop || len(subjects) != 1 {
+		t.Error("Expected the stream to stop after one message; Got: ", subjects, "; With Error: ", err)
+	}
+
+	// Not an array, and error responses
+	noop := func(GetUsersEmailAccountFolderMessagesResponse) error { return nil }
+	if err = cioLite.StreamUserEmailAccountsFolderMessages("u1", "0", "Object", GetUserEmailAccountsFolderMessageParams{}, noop); err == nil || !strings.Contains(err.Error(), "json array") {
+		t.Error("Expected an error for a response that is not an array; Got: ", err)
+	}
+	if err = cioLite.StreamUserEmailAccountsFolderMessages("u1", "0", "Missing", GetUserEmailAccountsFolderMessageParams{}, noop); ErrorStatusCode(err) != 404 || !strings.Contains(ErrorPayload(err), "Folder not found") {
+		t.Error("Expected a 404 with its payload; Got: ", err)
+	}
+
+	// The size limit applies to the whole stream
+	cioLite.MaxResponseBytes = 100
+	subjects = nil
+	err = cioLite.StreamUserEmailAccountsFolderMessages("u1", "0", "INBOX", GetUserEmailAccountsFolderMessageParams{}, func(message GetUsersEmailAccountFolderMessagesResponse) error {
+		subjects = append(subjects, message.Subject)
+		return nil
+	})
+	if !IsResponseTooLarge(err) || len(subjects) == 3 {
+		t.Error("Expected a response too large error; Got: ", subjects, "; With Error: ", err)
+	}
+}
+
+// TestSimulatedStreamUserEmailAccountsMessages tests streaming a listing of messages of an email account with a simulated server
+func TestSimulatedStreamUserEmailAccountsMessages(t *testing.T) {
+	t.Parallel()
+
+	cioLite, logger, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/messages", func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`[{"message_id": "<1@example.com>", "folders": ["INBOX"]}, {"message_id": "<2@example.com>", "folders": ["Sent"]}]`))
+		Must(err)
+	})
+
+	var folders []string
+	err := cioLite.StreamUserEmailAccountsMessages("u1", "0", GetUserEmailAccountsMessageParams{}, func(message GetUsersEmailAccountMessagesResponse) error {
+		folders = append(folders, message.Folders...)
+		return nil
+	})
+	expected := []string{"INBOX", "Sent"}
+	if err != nil || !reflect.DeepEqual(folders, expected) {
+		t.Error("Expected: ", expected, "; Got: ", folders, "; With Error: ", err, "; With Log: ", logger.String())
+	}
+}
+
+// TestSimulatedStreamNotRetried tests that a stream that failed after passing on elements is neither retried nor passed to the retry hook
+func TestSimulatedStreamNotRetried(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+		hooked   = map[string]int{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		if strings.Contains(r.URL.Path, "Truncated") {
+			_, err := w.Write([]byte(`[{"subject": "One"}, {"subject": `))
+			Must(err)
+			return
+		}
+		_, err := w.Write([]byte(`{"type": "error"}`))
+		Must(err)
+	})
+	hook := func(attempt int, userID string, label string, method string, url string, statusCode int, responseBody string, beforeAttempt time.Time, beforeAll time.Time, err error) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		hooked[strings.SplitN(url[strings.Index(url, "/lite/"):], "?", 2)[0]]++
+		return err != nil && attempt < 3
+	}
+	cioLite, testServer := NewTestCioLiteServer(mux, WithPostRequestShouldRetryHook(hook))
+	defer testServer.Close()
+
+	// Once an element has been passed on, the stream fails without calling the hook
+	path := "/lite/users/u1/email_accounts/0/folders/Truncated/messages"
+	var subjects []string
+	err := cioLite.StreamUserEmailAccountsFolderMessages("u1", "0", "Truncated", GetUserEmailAccountsFolderMessageParams{}, func(message GetUsersEmailAccountFolderMessagesResponse) error {
+		subjects = append(subjects, message.Subject)
+		return nil
+	})
+	mu.Lock()
+	if err == nil || len(subjects) != 1 || requests[path] != 1 || hooked[path] != 0 {
+		t.Error("Expected a single attempt without calling the hook; Got: ", requests[path], " attempts, ", hooked[path], " hook calls, ", subjects, "; With Error: ", err)
+	}
+	mu.Unlock()
+
+	// Before any element has been passed on, the hook is called and can retry
+	path = "/lite/users/u1/email_accounts/0/folders/Object/messages"
+	err = cioLite.StreamUserEmailAccountsFolderMessages("u1", "0", "Object", GetUserEmailAccountsFolderMessageParams{}, func(GetUsersEmailAccountFolderMessagesResponse) error { return nil })
+	mu.Lock()
+	if err == nil || requests[path] != 3 || hooked[path] != 3 {
+		t.Error("Expected 3 attempts and hook calls; Got: ", requests[path], " attempts, ", hooked[path], " hook calls; With Error: ", err)
+	}
+	mu.Unlock()
+}
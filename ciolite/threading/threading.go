@@ -0,0 +1,374 @@
+// Package threading groups ciolite messages into conversation trees,
+// using the algorithm described by Jamie Zawinski (https://www.jwz.org/doc/threading.html).
+package threading
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// Container is a single node of a conversation tree.
+// MessageID has no angle brackets, and Message is nil if the message was referenced
+// by another message, but has not been added itself.
+type Container struct {
+	MessageID string
+	Message   *ciolite.Message
+
+	Parent   *Container
+	Children []*Container
+}
+
+// Threader builds conversation trees from messages, which can be added at any time and in any order
+// (for example as webhook callbacks arrive). It is not safe for concurrent use.
+type Threader struct {
+	byID map[string]*node
+	noID int
+}
+
+// node is the internal, unpruned link graph that Threads are built from
+type node struct {
+	id       string
+	message  *ciolite.Message
+	parent   *node
+	children []*node
+}
+
+// New returns an empty Threader
+func New() *Threader {
+	return &Threader{byID: map[string]*node{}}
+}
+
+// Thread returns the conversation trees of the messages
+func Thread(messages []ciolite.Message) []*Container {
+	threader := New()
+	for _, message := range messages {
+		threader.Add(message)
+	}
+	return threader.Threads()
+}
+
+// AddFolderMessage adds a message returned by GetUserEmailAccountsFolderMessages
+func (threader *Threader) AddFolderMessage(message ciolite.GetUsersEmailAccountFolderMessagesResponse) {
+	threader.Add(message.Message())
+}
+
+// AddWebhookMessage adds the message received by a webhook callback
+func (threader *Threader) AddWebhookMessage(message ciolite.WebhookMessageData) {
+	threader.Add(message.Message())
+}
+
+// Add adds a message, linking it to its parent and ancestors through its References and InReplyTo.
+// Adding a message with the same MessageID again replaces the earlier copy (for example with newer flags).
+func (threader *Threader) Add(message ciolite.Message) {
+
+	id := normalizeID(message.MessageID)
+	if len(id) == 0 {
+		threader.noID++
+		id = "\x00" + strconv.Itoa(threader.noID)
+	}
+
+	current := threader.node(id)
+	current.message = &message
+
+	// Link the references together, from the root down, without changing any existing links
+	references := messageReferences(message)
+	var previous *node
+	for _, reference := range references {
+		referenced := threader.node(reference)
+		if previous != nil && referenced.parent == nil && !referenced.isAncestorOf(previous) {
+			previous.adopt(referenced)
+		}
+		previous = referenced
+	}
+
+	// The last reference is this message's parent, replacing any earlier guess
+	if previous != nil && previous != current && !current.isAncestorOf(previous) {
+		if current.parent != nil {
+			current.parent.disown(current)
+		}
+		previous.adopt(current)
+	}
+}
+
+// Len returns the number of messages that were added
+func (threader *Threader) Len() int {
+	count := 0
+	for _, n := range threader.byID {
+		if n.message != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Threads returns the current conversation trees, most recently active first.
+// Empty containers are pruned where possible, and root messages without references are grouped
+// by their subject (ignoring prefixes such as "Re:" and "Fwd:").
+// The returned trees are a snapshot, and are not changed by later calls to Add.
+func (threader *Threader) Threads() []*Container {
+
+	// Build the pruned root set
+	var roots []*Container
+	for _, n := range threader.byID {
+		if n.parent == nil {
+			roots = append(roots, prune(n, nil)...)
+		}
+	}
+
+	// Oldest first, so that subject grouping does not depend on the map order
+	sort.Slice(roots, func(i, j int) bool {
+		a, b := roots[i].Earliest(), roots[j].Earliest()
+		if a != b {
+			return a < b
+		}
+		return roots[i].MessageID < roots[j].MessageID
+	})
+	roots = groupBySubject(roots)
+
+	for _, root := range roots {
+		sortContainers(root.Children)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		a, b := roots[i].Latest(), roots[j].Latest()
+		if a != b {
+			return a > b
+		}
+		return roots[i].MessageID < roots[j].MessageID
+	})
+
+	return roots
+}
+
+// node returns the node for the message id, creating an empty one if necessary
+func (threader *Threader) node(id string) *node {
+	n, ok := threader.byID[id]
+	if !ok {
+		n = &node{id: id}
+		threader.byID[id] = n
+	}
+	return n
+}
+
+// adopt makes child a child of n
+func (n *node) adopt(child *node) {
+	child.parent = n
+	n.children = append(n.children, child)
+}
+
+// disown removes child from the children of n
+func (n *node) disown(child *node) {
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			break
+		}
+	}
+	child.parent = nil
+}
+
+// isAncestorOf returns true if n is other, or any ancestor of other
+func (n *node) isAncestorOf(other *node) bool {
+	for ; other != nil; other = other.parent {
+		if other == n {
+			return true
+		}
+	}
+	return false
+}
+
+// prune copies the node into Containers, dropping empty nodes:
+// an empty node without children is removed, and an empty node is replaced by its children
+// unless that would promote more than one child to the root set.
+func prune(n *node, parent *Container) []*Container {
+
+	container := &Container{MessageID: n.id, Message: n.message, Parent: parent}
+	if strings.HasPrefix(n.id, "\x00") {
+		container.MessageID = ""
+	}
+
+	var children []*Container
+	for _, child := range n.children {
+		children = append(children, prune(child, container)...)
+	}
+
+	if n.message == nil && (len(children) == 0 || parent != nil || len(children) == 1) {
+		for _, child := range children {
+			child.Parent = parent
+		}
+		return children
+	}
+
+	container.Children = children
+	return []*Container{container}
+}
+
+// groupBySubject merges root containers that have the same base subject
+func groupBySubject(roots []*Container) []*Container {
+
+	bySubject := map[string]*Container{}
+	var grouped []*Container
+
+	// Prefer non-replies as the container for a subject
+	sort.SliceStable(roots, func(i, j int) bool {
+		return !isReply(roots[i].Subject()) && isReply(roots[j].Subject())
+	})
+
+	for _, root := range roots {
+		subject := BaseSubject(root.Subject())
+		existing, ok := bySubject[subject]
+		if len(subject) == 0 || !ok {
+			if len(subject) > 0 {
+				bySubject[subject] = root
+			}
+			grouped = append(grouped, root)
+			continue
+		}
+
+		switch {
+		case existing.Message == nil && root.Message == nil:
+			// Both empty: merge the children
+			for _, child := range root.Children {
+				child.Parent = existing
+			}
+			existing.Children = append(existing.Children, root.Children...)
+		case existing.Message == nil || (!isReply(existing.Subject()) && isReply(root.Subject())):
+			// Make the reply a child of the original (or the empty container)
+			root.Parent = existing
+			existing.Children = append(existing.Children, root)
+		default:
+			// Neither is a reply of the other, so they become siblings under a new empty container
+			group := &Container{Children: []*Container{existing, root}}
+			existing.Parent, root.Parent = group, group
+			for i := range grouped {
+				if grouped[i] == existing {
+					grouped[i] = group
+				}
+			}
+			bySubject[subject] = group
+		}
+	}
+
+	return grouped
+}
+
+// sortContainers sorts the containers (and their descendants) by the date they were sent, oldest first
+func sortContainers(containers []*Container) {
+	sort.SliceStable(containers, func(i, j int) bool {
+		return containers[i].Earliest() < containers[j].Earliest()
+	})
+	for _, c := range containers {
+		sortContainers(c.Children)
+	}
+}
+
+// Subject returns the subject of this message, or of its first descendant with a message
+func (c *Container) Subject() string {
+	if c.Message != nil {
+		return c.Message.Subject
+	}
+	for _, child := range c.Children {
+		if subject := child.Subject(); len(subject) > 0 {
+			return subject
+		}
+	}
+	return ""
+}
+
+// Walk calls fn for this container and all of its descendants, depth first
+func (c *Container) Walk(fn func(container *Container, depth int)) {
+	c.walk(fn, 0)
+}
+
+// walk calls fn for this container and all of its descendants
+func (c *Container) walk(fn func(container *Container, depth int), depth int) {
+	fn(c, depth)
+	for _, child := range c.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// Messages returns every message within this tree, depth first
+func (c *Container) Messages() []ciolite.Message {
+	var messages []ciolite.Message
+	c.Walk(func(container *Container, depth int) {
+		if container.Message != nil {
+			messages = append(messages, *container.Message)
+		}
+	})
+	return messages
+}
+
+// Len returns the number of messages within this tree
+func (c *Container) Len() int {
+	return len(c.Messages())
+}
+
+// Latest returns the latest SentAt within this tree
+func (c *Container) Latest() ciolite.UnixTime {
+	var latest ciolite.UnixTime
+	c.Walk(func(container *Container, depth int) {
+		if container.Message != nil && container.Message.SentAt > latest {
+			latest = container.Message.SentAt
+		}
+	})
+	return latest
+}
+
+// Earliest returns the earliest non-zero SentAt within this tree
+func (c *Container) Earliest() ciolite.UnixTime {
+	var earliest ciolite.UnixTime
+	c.Walk(func(container *Container, depth int) {
+		if container.Message != nil && container.Message.SentAt > 0 && (earliest == 0 || container.Message.SentAt < earliest) {
+			earliest = container.Message.SentAt
+		}
+	})
+	return earliest
+}
+
+// subjectPrefix matches reply and forward prefixes, such as "Re:", "RE[2]:", "Fwd:", "Fw:", "Aw:", "Sv:"
+var subjectPrefix = regexp.MustCompile(`(?i)^\s*((re|fwd?|aw|sv|antw)(\[\d+\]|\(\d+\))?\s*:\s*)+`)
+
+// BaseSubject returns the subject without any reply or forward prefixes, lowercased and trimmed
+func BaseSubject(subject string) string {
+	return strings.ToLower(strings.TrimSpace(subjectPrefix.ReplaceAllString(subject, "")))
+}
+
+// isReply returns true if the subject has a reply or forward prefix
+func isReply(subject string) bool {
+	return subjectPrefix.MatchString(subject)
+}
+
+// messageReferences returns the normalized References, followed by InReplyTo if it is not already the last reference
+func messageReferences(message ciolite.Message) []string {
+	var references []string
+	for _, reference := range message.References {
+		for _, id := range strings.Fields(reference) {
+			if id = normalizeID(id); len(id) > 0 {
+				references = append(references, id)
+			}
+		}
+	}
+	if inReplyTo := normalizeID(message.InReplyTo); len(inReplyTo) > 0 &&
+		(len(references) == 0 || references[len(references)-1] != inReplyTo) {
+		references = append(references, inReplyTo)
+	}
+
+	// Drop any reference to the message itself
+	self := normalizeID(message.MessageID)
+	filtered := references[:0]
+	for _, reference := range references {
+		if reference != self {
+			filtered = append(filtered, reference)
+		}
+	}
+	return filtered
+}
+
+// normalizeID trims whitespace and the surrounding angle brackets from a message id
+func normalizeID(id string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">")
+}
@@ -0,0 +1,136 @@
+package threading
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// treeString returns an indented outline of the threads, using the message ids (or "-" for empty containers)
+func treeString(threads []*Container) string {
+	var lines []string
+	for _, thread := range threads {
+		thread.Walk(func(container *Container, depth int) {
+			id := "-"
+			if container.Message != nil {
+				id = container.MessageID
+			}
+			lines = append(lines, strings.Repeat("  ", depth)+id)
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestThreadReferences tests threading by References and InReplyTo, including a missing parent
+func TestThreadReferences(t *testing.T) {
+	t.Parallel()
+
+	threads := Thread([]ciolite.Message{
+		{MessageID: "<c@x>", Subject: "Re: Plan", References: []string{"<a@x> <b@x>"}, SentAt: 30},
+		{MessageID: "<a@x>", Subject: "Plan", SentAt: 10},
+		{MessageID: "<d@x>", Subject: "Re: Plan", InReplyTo: "<a@x>", SentAt: 40},
+		{MessageID: "<f@x>", Subject: "Lunch?", References: []string{"<e@x>"}, SentAt: 5},
+		{MessageID: "<g@x>", Subject: "Lunch?", InReplyTo: "<e@x>", SentAt: 6},
+	})
+
+	// <b@x> was never seen, so <c@x> is promoted into its place under <a@x>,
+	// while the unseen <e@x> stays as an empty root that keeps both of its replies together
+	expected := strings.Join([]string{
+		"a@x",
+		"  c@x",
+		"  d@x",
+		"-",
+		"  f@x",
+		"  g@x",
+	}, "\n")
+	if tree := treeString(threads); tree != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, tree)
+	}
+	if len(threads) != 2 || threads[0].Len() != 3 || threads[0].Latest() != 40 || threads[1].Subject() != "Lunch?" {
+		t.Error("Expected 2 threads, with 3 messages in the first; Got: ", threads)
+	}
+}
+
+// TestThreadSubjectFallback tests grouping messages without references by their subject
+func TestThreadSubjectFallback(t *testing.T) {
+	t.Parallel()
+
+	threads := Thread([]ciolite.Message{
+		{MessageID: "<2@x>", Subject: "RE: Invoice 42", SentAt: 20},
+		{MessageID: "<3@x>", Subject: "Fwd: re: invoice 42", SentAt: 30},
+		{MessageID: "<1@x>", Subject: "Invoice 42", SentAt: 10},
+		{MessageID: "<4@x>", Subject: "Hello", SentAt: 1},
+		{MessageID: "<5@x>", Subject: "Hello", SentAt: 2},
+	})
+
+	expected := strings.Join([]string{
+		"1@x",
+		"  2@x",
+		"  3@x",
+		"-",
+		"  4@x",
+		"  5@x",
+	}, "\n")
+	if tree := treeString(threads); tree != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, tree)
+	}
+
+	for subject, expected := range map[string]string{
+		"Re: Re: Hi":      "hi",
+		"FW: RE[2]: Hi ":  "hi",
+		"AW:Hi":           "hi",
+		"Regarding costs": "regarding costs",
+	} {
+		if base := BaseSubject(subject); base != expected {
+			t.Error("Expected base subject of ", subject, ": ", expected, "; Got: ", base)
+		}
+	}
+}
+
+// TestThreaderIncremental tests adding messages out of order, as webhooks would deliver them
+func TestThreaderIncremental(t *testing.T) {
+	t.Parallel()
+
+	threader := New()
+
+	// A reply arrives before its parent
+	var reply ciolite.WebhookMessageData
+	reply.MessageID = "<r@x>"
+	reply.Subject = "Re: Status"
+	reply.References = []string{"<p@x>"}
+	reply.Date = 20
+	threader.AddWebhookMessage(reply)
+
+	if tree := treeString(threader.Threads()); tree != "r@x" {
+		t.Error("Expected the reply alone; Got: ", tree)
+	}
+
+	threader.AddFolderMessage(ciolite.GetUsersEmailAccountFolderMessagesResponse{MessageID: "<p@x>", Subject: "Status", SentAt: 10})
+
+	// A message referencing its own descendant must not create a loop
+	threader.Add(ciolite.Message{MessageID: "<p@x>", Subject: "Status", References: []string{"<r@x>"}, SentAt: 10})
+
+	threads := threader.Threads()
+	if tree := treeString(threads); tree != "p@x\n  r@x" {
+		t.Error("Expected the reply under its parent; Got: ", tree)
+	}
+	if threader.Len() != 2 {
+		t.Error("Expected 2 messages; Got: ", threader.Len())
+	}
+
+	// Earlier snapshots are not changed by later additions
+	threader.Add(ciolite.Message{MessageID: "<s@x>", InReplyTo: "<r@x>", SentAt: 30})
+	if tree := treeString(threads); tree != "p@x\n  r@x" {
+		t.Error("Expected the earlier snapshot to be unchanged; Got: ", tree)
+	}
+
+	var ids []string
+	for _, message := range threader.Threads()[0].Messages() {
+		ids = append(ids, message.MessageID)
+	}
+	if expected := []string{"<p@x>", "<r@x>", "<s@x>"}; !reflect.DeepEqual(ids, expected) {
+		t.Error("Expected: ", expected, "; Got: ", ids, "; Tree: ", treeString(threader.Threads()))
+	}
+}
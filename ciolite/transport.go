@@ -0,0 +1,136 @@
+package ciolite
+
+// Response decompression and size limits, and a transport preset for high-fanout workloads
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ResponseTooLargeError is the cause of the RequestError returned when a response is larger than MaxResponseBytes
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+// Error returns the limit that was exceeded
+func (e ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("CIO: Response is larger than the limit of %d bytes", e.Limit)
+}
+
+// IsResponseTooLarge returns true if the error was caused by a response larger than MaxResponseBytes
+func IsResponseTooLarge(err error) bool {
+	_, ok := errors.Cause(err).(ResponseTooLargeError)
+	return ok
+}
+
+// readResponseBody reads the (decompressed) response body, up to MaxResponseBytes
+func (cio CioLite) readResponseBody(res *http.Response) ([]byte, error) {
+	body, closeBody, err := cio.responseBody(res)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody()
+	return ioutil.ReadAll(body)
+}
+
+// responseBody returns a reader of the (decompressed) response body, which fails with a ResponseTooLargeError
+// after MaxResponseBytes, along with a func that closes any decompressor
+func (cio CioLite) responseBody(res *http.Response) (io.Reader, func(), error) {
+	var (
+		body      io.Reader = res.Body
+		closeBody           = func() {}
+	)
+
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(body)
+		if err == io.EOF {
+			return bytes.NewReader(nil), closeBody, nil
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "CIO: Could not decompress gzip response")
+		}
+		body, closeBody = gzipReader, func() { _ = gzipReader.Close() }
+	case "deflate":
+		deflateReader, err := newDeflateReader(body)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "CIO: Could not decompress deflate response")
+		}
+		body, closeBody = deflateReader, func() { _ = deflateReader.Close() }
+	}
+
+	if cio.MaxResponseBytes > 0 {
+		body = &maxBytesReader{reader: body, limit: cio.MaxResponseBytes, remaining: cio.MaxResponseBytes}
+	}
+	return body, closeBody, nil
+}
+
+// maxBytesReader fails with a ResponseTooLargeError once more than limit bytes have been read
+type maxBytesReader struct {
+	reader    io.Reader
+	limit     int64
+	remaining int64
+}
+
+// Read reads up to one byte past the limit, to know if it was exceeded
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ResponseTooLargeError{Limit: r.limit}
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, ResponseTooLargeError{Limit: r.limit}
+	}
+	return n, err
+}
+
+// newDeflateReader returns a reader of a deflate response, which servers send either zlib wrapped (as the spec says) or raw
+func newDeflateReader(body io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// NewHighFanoutTransport returns an *http.Transport tuned for many concurrent requests to CIO
+// (ex: syncing many accounts at once): keep-alives, up to maxIdleConnsPerHost idle connections kept open per host
+// (instead of http.DefaultMaxIdleConnsPerHost), and HTTP/2 when the server supports it.
+func NewHighFanoutTransport(maxIdleConnsPerHost int) *http.Transport {
+	if maxIdleConnsPerHost < 1 {
+		maxIdleConnsPerHost = 100
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConnsPerHost * 2,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
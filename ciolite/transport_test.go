@@ -0,0 +1,91 @@
+package ciolite
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestSimulatedCompression tests that compressed responses are asked for and decompressed
+func TestSimulatedCompression(t *testing.T) {
+	t.Parallel()
+
+	encoders := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"zlib": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"flate": func(w io.Writer) io.WriteCloser {
+			flateWriter, err := flate.NewWriter(w, flate.DefaultCompression)
+			Must(err)
+			return flateWriter
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip, deflate" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/lite/users/")
+		encoding := name
+		if name != "gzip" {
+			encoding = "deflate"
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		encoder := encoders[name](w)
+		Must(json.NewEncoder(encoder).Encode(GetUsersResponse{ID: name}))
+		Must(encoder.Close())
+	})
+
+	cioLite, testServer := NewTestCioLiteServer(mux, WithCompression())
+	defer testServer.Close()
+
+	for name := range encoders {
+		user, err := cioLite.GetUser(name)
+		if err != nil || user.ID != name {
+			t.Error("Expected user ", name, "; Got: ", user, "; With Error: ", err)
+		}
+	}
+}
+
+// TestSimulatedMaxResponseBytes tests that responses larger than MaxResponseBytes fail
+func TestSimulatedMaxResponseBytes(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		Must(json.NewEncoder(w).Encode(GetUsersResponse{ID: strings.TrimPrefix(r.URL.Path, "/lite/users/")}))
+	})
+
+	cioLite, testServer := NewTestCioLiteServer(mux, WithMaxResponseBytes(64))
+	defer testServer.Close()
+
+	if user, err := cioLite.GetUser("small"); err != nil || user.ID != "small" {
+		t.Error("Expected a small response; Got: ", user, "; With Error: ", err)
+	}
+
+	_, err := cioLite.GetUser(strings.Repeat("x", 100))
+	if !IsResponseTooLarge(err) || ErrorStatusCode(err) != 200 {
+		t.Error("Expected a response too large error; Got: ", err)
+	}
+}
+
+// TestNewHighFanoutTransport tests the transport preset
+func TestNewHighFanoutTransport(t *testing.T) {
+	t.Parallel()
+
+	transport := NewHighFanoutTransport(50)
+	if transport.MaxIdleConnsPerHost != 50 || !transport.ForceAttemptHTTP2 || transport.DisableKeepAlives {
+		t.Error("Expected a transport tuned for high fanout; Got: ", transport)
+	}
+
+	cioLite, err := New("key", "secret", WithTimeout(5), WithHighFanoutTransport(0))
+	if err != nil || cioLite.HTTPClient.Timeout != 5 || cioLite.HTTPClient.Transport.(*http.Transport).MaxIdleConnsPerHost != 100 {
+		t.Error("Expected the transport to be set, keeping the timeout; Got: ", cioLite.HTTPClient, "; With Error: ", err)
+	}
+}
@@ -0,0 +1,142 @@
+package ciolite
+
+// Typed timestamps and enums of response fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// UnixTime is a unix timestamp (in seconds), as sent and received by CIO.
+// Zero means not set: it is also what false, null, and "" unmarshal to,
+// as CIO uses false for timestamps that have not happened (see ExpiresMixed).
+type UnixTime int64
+
+// NewUnixTime returns the UnixTime of t, or zero if t is the zero time.Time
+func NewUnixTime(t time.Time) UnixTime {
+	if t.IsZero() {
+		return 0
+	}
+	return UnixTime(t.Unix())
+}
+
+// Time returns the time.Time, or the zero time.Time if not set
+func (t UnixTime) Time() time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t), 0)
+}
+
+// IsZero returns true if the timestamp is not set
+func (t UnixTime) IsZero() bool {
+	return t == 0
+}
+
+// MarshalJSON allows UnixTime to implement json.Marshaler
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(t), 10)), nil
+}
+
+// UnmarshalJSON allows UnixTime to implement json.Unmarshaler.
+// Accepts ints, floats (truncated), numeric strings, and false or null (as zero).
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("false")) || bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte(`""`)) {
+		*t = 0
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return errors.Wrapf(err, "Unable to unmarshal unix timestamp: %s", data)
+	}
+	s := number.String()
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*t = UnixTime(i)
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to unmarshal unix timestamp: %s", data)
+	}
+	*t = UnixTime(f)
+	return nil
+}
+
+// MarshalFormValue allows UnixTime to be used in params (zero is treated as empty)
+func (t UnixTime) MarshalFormValue() (string, error) {
+	if t == 0 {
+		return "", nil
+	}
+	return strconv.FormatInt(int64(t), 10), nil
+}
+
+// AccountStatus is the status of an email account
+type AccountStatus string
+
+// AccountStatus values
+const (
+	AccountStatusOK                   AccountStatus = "OK"
+	AccountStatusInvalidCredentials   AccountStatus = "INVALID_CREDENTIALS"
+	AccountStatusConnectionImpossible AccountStatus = "CONNECTION_IMPOSSIBLE"
+	AccountStatusNoAccessToAllMail    AccountStatus = "NO_ACCESS_TO_ALL_MAIL"
+	AccountStatusTempDisabled         AccountStatus = "TEMP_DISABLED"
+	AccountStatusDisabled             AccountStatus = "DISABLED"
+)
+
+// IsOK returns true if CIO can access the account
+func (status AccountStatus) IsOK() bool {
+	return status == AccountStatusOK
+}
+
+// IsDisabled returns true if the account is (temporarily or not) disabled
+func (status AccountStatus) IsDisabled() bool {
+	return status == AccountStatusDisabled || status == AccountStatusTempDisabled
+}
+
+// NeedsReauthentication returns true if the account's credentials must be updated
+func (status AccountStatus) NeedsReauthentication() bool {
+	return status == AccountStatusInvalidCredentials
+}
+
+// AccountType is the type of an email account (or of a discovered server)
+type AccountType string
+
+// AccountType values
+const (
+	AccountTypeIMAP       AccountType = "imap"
+	AccountTypeGmail      AccountType = "gmail"
+	AccountTypeGoogleApps AccountType = "googleapps"
+	AccountTypeOffice365  AccountType = "office365"
+)
+
+// IsGoogle returns true for Gmail and Google Apps accounts
+func (accountType AccountType) IsGoogle() bool {
+	return accountType == AccountTypeGmail || accountType == AccountTypeGoogleApps
+}
+
+// IsMicrosoft returns true for Office 365 accounts
+func (accountType AccountType) IsMicrosoft() bool {
+	return accountType == AccountTypeOffice365
+}
+
+// AuthenticationType is how CIO authenticates to an email account
+type AuthenticationType string
+
+// AuthenticationType values
+const (
+	AuthenticationTypePassword AuthenticationType = "password"
+	AuthenticationTypeOAuth1   AuthenticationType = "oauth1"
+	AuthenticationTypeOAuth2   AuthenticationType = "oauth2"
+)
+
+// IsOAuth returns true if the account is authenticated with an oauth provider, rather than a password
+func (authType AuthenticationType) IsOAuth() bool {
+	return strings.Contains(strings.ToLower(string(authType)), "oauth")
+}
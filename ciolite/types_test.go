@@ -0,0 +1,92 @@
+package ciolite
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestUnixTime tests the json encoding/decoding and conversions of UnixTime
+func TestUnixTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		json     string
+		expected UnixTime
+	}{
+		{`1464742490`, 1464742490},
+		{`1464742490.75`, 1464742490},
+		{`"1464742490"`, 1464742490},
+		{`false`, 0},
+		{`null`, 0},
+		{`""`, 0},
+		{`0`, 0},
+	}
+	for _, test := range tests {
+		var actual struct {
+			Date UnixTime `json:"date"`
+		}
+		if err := json.Unmarshal([]byte(`{"date": `+test.json+`}`), &actual); err != nil || actual.Date != test.expected {
+			t.Errorf("Expected %d for %s; Got: %d (%v)", test.expected, test.json, actual.Date, err)
+		}
+	}
+
+	for _, invalid := range []string{`true`, `"soon"`, `{}`} {
+		var actual UnixTime
+		if err := json.Unmarshal([]byte(invalid), &actual); err == nil {
+			t.Errorf("Expected an error for %s; Got: %d", invalid, actual)
+		}
+	}
+
+	b, err := json.Marshal(map[string]UnixTime{"date": 1464742490})
+	if err != nil || string(b) != `{"date":1464742490}` {
+		t.Errorf("Unexpected json: %s (%v)", b, err)
+	}
+
+	tm := time.Date(2016, 6, 1, 0, 54, 50, 0, time.UTC)
+	if NewUnixTime(tm) != 1464742490 || !UnixTime(1464742490).Time().Equal(tm) {
+		t.Error("Expected UnixTime to convert to and from ", tm)
+	}
+	if !UnixTime(0).Time().IsZero() || NewUnixTime(time.Time{}) != 0 || !UnixTime(0).IsZero() {
+		t.Error("Expected zero UnixTime to convert to and from the zero time.Time")
+	}
+
+	// Zero is omitted from params
+	values, err := formValues(struct {
+		Before UnixTime `json:"before,omitempty"`
+		After  UnixTime `json:"after,omitempty"`
+	}{After: 1464742490})
+	if err != nil || values.Encode() != "after=1464742490" {
+		t.Errorf("Unexpected form values: %s (%v)", values.Encode(), err)
+	}
+}
+
+// TestAccountEnums tests the helpers of AccountStatus, AccountType, and AuthenticationType
+func TestAccountEnums(t *testing.T) {
+	t.Parallel()
+
+	if !AccountStatusOK.IsOK() || AccountStatusTempDisabled.IsOK() || AccountStatus("ok").IsOK() {
+		t.Error("Unexpected AccountStatus.IsOK")
+	}
+	if !AccountStatusTempDisabled.IsDisabled() || !AccountStatusDisabled.IsDisabled() || AccountStatusInvalidCredentials.IsDisabled() {
+		t.Error("Unexpected AccountStatus.IsDisabled")
+	}
+	if !AccountStatusInvalidCredentials.NeedsReauthentication() || AccountStatusConnectionImpossible.NeedsReauthentication() {
+		t.Error("Unexpected AccountStatus.NeedsReauthentication")
+	}
+	if !AccountTypeGoogleApps.IsGoogle() || AccountTypeIMAP.IsGoogle() {
+		t.Error("Unexpected AccountType.IsGoogle")
+	}
+	if !AccountTypeOffice365.IsMicrosoft() || AccountTypeOffice365.IsGoogle() || AccountTypeIMAP.IsMicrosoft() {
+		t.Error("Unexpected AccountType.IsMicrosoft")
+	}
+	if !AuthenticationTypeOAuth2.IsOAuth() || !AuthenticationType("OAUTH1").IsOAuth() || AuthenticationTypePassword.IsOAuth() {
+		t.Error("Unexpected AuthenticationType.IsOAuth")
+	}
+
+	var account GetUsersEmailAccountsResponse
+	Must(json.Unmarshal([]byte(`{"status": "OK", "type": "imap", "authentication_type": "oauth2"}`), &account))
+	if !account.Status.IsOK() || account.Type != AccountTypeIMAP || !account.AuthenticationType.IsOAuth() {
+		t.Errorf("Unexpected account: %+v", account)
+	}
+}
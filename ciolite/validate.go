@@ -0,0 +1,183 @@
+package ciolite
+
+// Validation of params structs, declared with valid tags, before any request is sent
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValidationFailure is a single param that failed a rule
+type ValidationFailure struct {
+	// Field is the param's name, as sent to CIO (ex: callback_url)
+	Field string
+
+	// Rule is the rule that failed (ex: required, url, email, in)
+	Rule string
+
+	Value string
+}
+
+// ValidationError is returned, before any request is sent, when params fail their valid tags.
+// Supported rules (comma separated) are: required, url, email, and in(a|b|c).
+type ValidationError struct {
+	// Params is the name of the params type (ex: CreateUserWebhookParams)
+	Params string
+
+	Failures []ValidationFailure
+}
+
+// Error returns every failure
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		switch failure.Rule {
+		case "required":
+			messages[i] = failure.Field + " is required"
+		case "in":
+			messages[i] = failure.Field + " has an invalid value: " + failure.Value
+		default:
+			messages[i] = failure.Field + " is not a valid " + failure.Rule + ": " + failure.Value
+		}
+	}
+	return "CIO: Invalid " + e.Params + ": " + strings.Join(messages, "; ")
+}
+
+// validationRule is a single parsed rule of a valid tag
+type validationRule struct {
+	name    string
+	allowed []string
+}
+
+// parseValidTag parses the rules of a valid tag
+func parseValidTag(tag string) ([]validationRule, error) {
+	var rules []validationRule
+	for len(tag) > 0 {
+		var rule string
+		// in(...) may not contain commas, so the next comma ends the rule
+		if i := strings.Index(tag, ","); i >= 0 {
+			rule, tag = tag[:i], tag[i+1:]
+		} else {
+			rule, tag = tag, ""
+		}
+		rule = strings.TrimSpace(rule)
+
+		switch {
+		case rule == "required", rule == "url", rule == "email":
+			rules = append(rules, validationRule{name: rule})
+		case strings.HasPrefix(rule, "in(") && strings.HasSuffix(rule, ")"):
+			rules = append(rules, validationRule{name: "in", allowed: strings.Split(rule[3:len(rule)-1], "|")})
+		case len(rule) == 0:
+		default:
+			return nil, errors.Errorf("Unknown validation rule: %s", rule)
+		}
+	}
+	return rules, nil
+}
+
+// validateParams validates the params against their valid tags, returning a ValidationError if any fail
+func validateParams(params interface{}) error {
+	if params == nil {
+		return nil
+	}
+	refVal := reflect.ValueOf(params)
+	for refVal.Kind() == reflect.Ptr {
+		if refVal.IsNil() {
+			return nil
+		}
+		refVal = refVal.Elem()
+	}
+	if refVal.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// Params that cannot be encoded are reported when they are encoded
+	encoder, err := cachedStructEncoder(refVal.Type())
+	if err != nil {
+		return nil
+	}
+
+	validationErr := ValidationError{Params: refVal.Type().Name()}
+	encoder.validate(refVal, "", &validationErr)
+	if len(validationErr.Failures) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// validate checks the struct's fields against their rules, adding failures to the error
+func (encoder *structEncoder) validate(v reflect.Value, prefix string, validationErr *ValidationError) {
+	for _, field := range encoder.fields {
+		fieldValue := v.Field(field.index)
+
+		name := field.name
+		if len(prefix) > 0 && !field.flatten {
+			name = prefix + "[" + name + "]"
+		} else if field.flatten {
+			name = prefix
+		}
+
+		if field.nested != nil {
+			field.nested.validate(fieldValue, name, validationErr)
+			continue
+		}
+		if len(field.rules) == 0 {
+			continue
+		}
+
+		var (
+			values  []string
+			nonZero bool
+		)
+		if !field.pointer || !fieldValue.IsNil() {
+			// Encoding errors are reported when the params are encoded
+			encoded, isNonZero, err := field.encode(fieldValue)
+			if err != nil {
+				continue
+			}
+			values, nonZero = encoded, isNonZero || field.pointer
+		}
+
+		for _, rule := range field.rules {
+			if rule.name == "required" {
+				if !nonZero {
+					validationErr.Failures = append(validationErr.Failures, ValidationFailure{Field: name, Rule: rule.name})
+				}
+				continue
+			}
+			// Other rules only apply to values that are set
+			if !nonZero {
+				continue
+			}
+			for _, value := range values {
+				if !rule.valid(value) {
+					validationErr.Failures = append(validationErr.Failures, ValidationFailure{Field: name, Rule: rule.name, Value: value})
+				}
+			}
+		}
+	}
+}
+
+// valid returns true if the value passes the rule
+func (rule validationRule) valid(value string) bool {
+	switch rule.name {
+	case "url":
+		u, err := url.ParseRequestURI(value)
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "in":
+		for _, allowed := range rule.allowed {
+			if value == allowed {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package ciolite
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestValidateParams tests the valid tags of params structs
+func TestValidateParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		params   interface{}
+		expected []ValidationFailure
+	}{
+		{CreateUserWebhookParams{CallbackURL: "https://example.com/hook", BodyType: "text/html"}, nil},
+		{CreateUserWebhookParams{}, []ValidationFailure{{Field: "callback_url", Rule: "required"}}},
+		{CreateUserWebhookParams{CallbackURL: "example.com/hook", BodyType: "html"}, []ValidationFailure{
+			{Field: "callback_url", Rule: "url", Value: "example.com/hook"},
+			{Field: "body_type", Rule: "in", Value: "html"},
+		}},
+		{CreateConnectTokenParams{CallbackURL: "https://example.com", Email: "Jane <jane@example.com>"}, []ValidationFailure{
+			{Field: "email", Rule: "email", Value: "Jane <jane@example.com>"},
+		}},
+		{GetDiscoveryParams{Email: "jane@example.com"}, nil},
+		{GetDiscoveryParams{}, []ValidationFailure{{Field: "email", Rule: "required"}}},
+		{MoveUserEmailAccountFolderMessageParams{}, []ValidationFailure{{Field: "new_folder_id", Rule: "required"}}},
+		{GetUserEmailAccountsFolderMessageParams{IncludeHeaders: "raw"}, nil},
+		{&GetUserEmailAccountsMessageParams{IncludeHeaders: "2"}, []ValidationFailure{{Field: "include_headers", Rule: "in", Value: "2"}}},
+		{ModifyUserEmailAccountsFolderMessageFlagsParams{Seen: "1", Draft: "true"}, []ValidationFailure{{Field: "draft", Rule: "in", Value: "true"}}},
+		{GetUsersParams{}, nil},
+	}
+
+	for _, test := range tests {
+		err := validateParams(test.params)
+		if test.expected == nil {
+			if err != nil {
+				t.Errorf("Expected no error for %+v; Got: %v", test.params, err)
+			}
+			continue
+		}
+		validationErr, ok := err.(ValidationError)
+		if !ok || !reflect.DeepEqual(validationErr.Failures, test.expected) {
+			t.Errorf("Expected failures %+v for %+v; Got: %v", test.expected, test.params, err)
+		}
+	}
+
+	// Pointers are required to be set, but may point to a zero value
+	type params struct {
+		Active *bool `json:"active" valid:"required"`
+	}
+	if err := validateParams(params{}); err == nil || err.Error() != "CIO: Invalid params: active is required" {
+		t.Error("Expected required error; Got: ", err)
+	}
+	f := false
+	if err := validateParams(params{Active: &f}); err != nil {
+		t.Error("Expected no error; Got: ", err)
+	}
+
+	// Unknown rules are reported when encoding
+	unknown := struct {
+		Name string `json:"name" valid:"uppercase"`
+	}{}
+	if _, err := formValues(unknown); err == nil || !strings.Contains(err.Error(), "Unknown validation rule: uppercase") {
+		t.Error("Expected unknown rule error; Got: ", err)
+	}
+}
+
+// TestSimulatedValidationError tests that invalid params are not sent
+func TestSimulatedValidationError(t *testing.T) {
+	t.Parallel()
+
+	cioLite, _, testServer, mux := NewTestCioLiteWithLoggerAndTestServer(t)
+	defer testServer.Close()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Unexpected request: ", r.URL.Path)
+	})
+
+	_, err := cioLite.CreateConnectToken(CreateConnectTokenParams{})
+	if validationErr, ok := err.(ValidationError); !ok || validationErr.Params != "CreateConnectTokenParams" {
+		t.Error("Expected ValidationError; Got: ", err)
+	}
+
+	_, err = cioLite.GetUserEmailAccountsFolderMessages("u1", "0", "INBOX", GetUserEmailAccountsFolderMessageParams{BodyType: "text"})
+	if _, ok := err.(ValidationError); !ok || !strings.Contains(err.Error(), "body_type has an invalid value: text") {
+		t.Error("Expected ValidationError; Got: ", err)
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// paramsFunc applies the -p params to a params struct
+type paramsFunc func(dst interface{}) error
+
+// command is a single resource action
+type command struct {
+	resource string
+	action   string
+	args     []string
+	help     string
+	run      func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error)
+}
+
+// findCommand returns the command for the resource and action
+func findCommand(resource string, action string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.resource == resource && cmd.action == action {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// commands are every resource action, in the order they are listed in the usage
+var commands = []command{
+
+	// Users
+	{"users", "list", nil, "List users (params: email, status, status_ok, limit, offset)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUsersParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUsers(params)
+	}},
+	{"users", "get", []string{"user"}, "Get a user", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUser(a[0])
+	}},
+	{"users", "create", nil, "Create a user (and optionally its first email account)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateUserParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateUser(params)
+	}},
+	{"users", "modify", []string{"user"}, "Modify a user (params: first_name, last_name)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.ModifyUserParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.ModifyUser(a[0], params)
+	}},
+	{"users", "delete", []string{"user"}, "Delete a user", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteUser(a[0])
+	}},
+
+	// Email accounts
+	{"accounts", "list", []string{"user"}, "List a user's email accounts (params: status, status_ok)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUserEmailAccountsParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccounts(a[0], params)
+	}},
+	{"accounts", "get", []string{"user", "label"}, "Get an email account", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUserEmailAccount(a[0], a[1])
+	}},
+	{"accounts", "create", []string{"user"}, "Create an email account", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateUserParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateUserEmailAccount(a[0], params)
+	}},
+	{"accounts", "modify", []string{"user", "label"}, "Modify an email account", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.ModifyUserEmailAccountParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.ModifyUserEmailAccount(a[0], a[1], params)
+	}},
+	{"accounts", "delete", []string{"user", "label"}, "Delete an email account", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteUserEmailAccount(a[0], a[1])
+	}},
+
+	// Folders
+	{"folders", "list", []string{"user", "label"}, "List folders (params: include_names_only)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUserEmailAccountsFoldersParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolders(a[0], a[1], params)
+	}},
+	{"folders", "get", []string{"user", "label", "folder"}, "Get a folder (params: delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.EmailAccountFolderDelimiterParam
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountFolder(a[0], a[1], a[2], params)
+	}},
+	{"folders", "create", []string{"user", "label", "folder"}, "Create a folder (params: delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.EmailAccountFolderDelimiterParam
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateUserEmailAccountFolder(a[0], a[1], a[2], params)
+	}},
+	{"folders", "modify", []string{"user", "label", "folder"}, "Rename a folder (params: new_folder_id, delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.RenameUserEmailAccountFolderParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.RenameUserEmailAccountFolder(a[0], a[1], a[2], params)
+	}},
+	{"folders", "delete", []string{"user", "label", "folder"}, "Delete a folder (params: delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.EmailAccountFolderDelimiterParam
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.DeleteUserEmailAccountFolder(a[0], a[1], a[2], params)
+	}},
+
+	// Messages
+	{"messages", "list", []string{"user", "label", "folder"}, "List messages in a folder (params: limit, offset, include_body, include_flags, ...)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUserEmailAccountsFolderMessageParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolderMessages(a[0], a[1], a[2], params)
+	}},
+	{"messages", "get", []string{"user", "label", "folder", "message"}, "Get a message", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUserEmailAccountsFolderMessageParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountFolderMessage(a[0], a[1], a[2], a[3], params)
+	}},
+	{"messages", "modify", []string{"user", "label", "folder", "message"}, "Modify a message's flags (params: seen, answered, flagged, deleted, draft, add_keywords, remove_keywords)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.ModifyUserEmailAccountsFolderMessageFlagsParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.ModifyUserEmailAccountsFolderMessageFlags(a[0], a[1], a[2], a[3], params)
+	}},
+	{"messages", "move", []string{"user", "label", "folder", "message"}, "Move a message (params: new_folder_id, delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.MoveUserEmailAccountFolderMessageParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.MoveUserEmailAccountFolderMessage(a[0], a[1], a[2], a[3], params)
+	}},
+	{"messages", "body", []string{"user", "label", "folder", "message"}, "Get a message's bodies (params: type, delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUserEmailAccountsFolderMessageBodyParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolderMessageBody(a[0], a[1], a[2], a[3], params)
+	}},
+	{"messages", "headers", []string{"user", "label", "folder", "message"}, "Get a message's headers (params: raw, delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUserEmailAccountsFolderMessageHeadersParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolderMessageHeaders(a[0], a[1], a[2], a[3], params)
+	}},
+	{"messages", "flags", []string{"user", "label", "folder", "message"}, "Get a message's flags (params: delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.EmailAccountFolderDelimiterParam
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolderMessageFlags(a[0], a[1], a[2], a[3], params)
+	}},
+
+	// Raw messages
+	{"raw", "get", []string{"user", "label", "folder", "message"}, "Write the raw RFC-822 message (params: delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.EmailAccountFolderDelimiterParam
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolderMessageRaw(a[0], a[1], a[2], a[3], params)
+	}},
+
+	// Attachments
+	{"attachments", "list", []string{"user", "label", "folder", "message"}, "List a message's attachments (params: delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.EmailAccountFolderDelimiterParam
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolderMessageAttachments(a[0], a[1], a[2], a[3], params)
+	}},
+	{"attachments", "get", []string{"user", "label", "folder", "message", "attachment"}, "Get an attachment (params: delimiter)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.GetUserEmailAccountsFolderMessageAttachmentParam
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.GetUserEmailAccountsFolderMessageAttachment(a[0], a[1], a[2], a[3], a[4], params)
+	}},
+
+	// App webhooks
+	{"webhooks", "list", nil, "List app webhooks", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetWebhooks()
+	}},
+	{"webhooks", "get", []string{"webhook"}, "Get an app webhook", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetWebhook(a[0])
+	}},
+	{"webhooks", "create", nil, "Create an app webhook (params: callback_url, filter_*, ...)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateUserWebhookParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateWebhook(params)
+	}},
+	{"webhooks", "modify", []string{"webhook"}, "Modify an app webhook (params: active)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.ModifyUserWebhookParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.ModifyWebhook(a[0], params)
+	}},
+	{"webhooks", "delete", []string{"webhook"}, "Delete an app webhook", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteWebhookAccount(a[0])
+	}},
+
+	// User webhooks
+	{"user-webhooks", "list", []string{"user"}, "List a user's webhooks", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUserWebhooks(a[0])
+	}},
+	{"user-webhooks", "get", []string{"user", "webhook"}, "Get a user's webhook", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUserWebhook(a[0], a[1])
+	}},
+	{"user-webhooks", "create", []string{"user"}, "Create a user webhook (params: callback_url, filter_*, ...)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateUserWebhookParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateUserWebhook(a[0], params)
+	}},
+	{"user-webhooks", "modify", []string{"user", "webhook"}, "Modify a user webhook (params: active)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.ModifyUserWebhookParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.ModifyUserWebhook(a[0], a[1], params)
+	}},
+	{"user-webhooks", "delete", []string{"user", "webhook"}, "Delete a user webhook", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteUserWebhookAccount(a[0], a[1])
+	}},
+
+	// Connect tokens, at the app level
+	{"connect-tokens", "list", nil, "List app connect tokens", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetConnectTokens()
+	}},
+	{"connect-tokens", "get", []string{"token"}, "Get an app connect token", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetConnectToken(a[0])
+	}},
+	{"connect-tokens", "create", nil, "Create an app connect token (params: callback_url, email, ...)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateConnectTokenParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateConnectToken(params)
+	}},
+	{"connect-tokens", "delete", []string{"token"}, "Delete an app connect token", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteConnectToken(a[0])
+	}},
+
+	// Connect tokens, at the user level
+	{"user-connect-tokens", "list", []string{"user"}, "List a user's connect tokens", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUserConnectTokens(a[0])
+	}},
+	{"user-connect-tokens", "get", []string{"user", "token"}, "Get a user's connect token", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUserConnectToken(a[0], a[1])
+	}},
+	{"user-connect-tokens", "create", []string{"user"}, "Create a user connect token (params: callback_url, email, ...)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateConnectTokenParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateUserConnectToken(a[0], params)
+	}},
+	{"user-connect-tokens", "delete", []string{"user", "token"}, "Delete a user's connect token", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteUserConnectToken(a[0], a[1])
+	}},
+
+	// Connect tokens, at the email account level
+	{"account-connect-tokens", "list", []string{"user", "label"}, "List an email account's connect tokens", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUserEmailAccountConnectTokens(a[0], a[1])
+	}},
+	{"account-connect-tokens", "get", []string{"user", "label", "token"}, "Get an email account's connect token", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetUserEmailAccountConnectToken(a[0], a[1], a[2])
+	}},
+	{"account-connect-tokens", "create", []string{"user", "label"}, "Create an email account connect token (params: callback_url, ...)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateConnectTokenParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateUserEmailAccountConnectToken(a[0], a[1], params)
+	}},
+	{"account-connect-tokens", "delete", []string{"user", "label", "token"}, "Delete an email account's connect token", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteUserEmailAccountConnectToken(a[0], a[1], a[2])
+	}},
+
+	// OAuth providers
+	{"oauth-providers", "list", nil, "List oauth providers", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetOAuthProviders()
+	}},
+	{"oauth-providers", "get", []string{"key"}, "Get an oauth provider", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetOAuthProvider(a[0])
+	}},
+	{"oauth-providers", "create", nil, "Create an oauth provider (params: type, provider_consumer_key, provider_consumer_secret)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateOAuthProviderParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateOAuthProvider(params)
+	}},
+	{"oauth-providers", "delete", []string{"key"}, "Delete an oauth provider", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteOAuthProvider(a[0])
+	}},
+
+	// Discovery
+	{"discovery", "get", []string{"email"}, "Discover the imap settings for an email address", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetDiscovery(ciolite.GetDiscoveryParams{Email: a[0]})
+	}},
+
+	// Status callback url
+	{"status-callback-url", "get", nil, "Get the app status callback url", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.GetStatusCallbackURL()
+	}},
+	{"status-callback-url", "create", nil, "Set the app status callback url (params: status_callback_url)", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		var params ciolite.CreateStatusCallbackURLParams
+		if err := p(&params); err != nil {
+			return nil, err
+		}
+		return c.CreateStatusCallbackURL(params)
+	}},
+	{"status-callback-url", "delete", nil, "Delete the app status callback url", func(c ciolite.Interface, a []string, p paramsFunc) (interface{}, error) {
+		return c.DeleteStatusCallbackURL()
+	}},
+}
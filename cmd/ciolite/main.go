@@ -0,0 +1,145 @@
+// Command ciolite is a command line tool for inspecting and managing Context.IO Lite users and accounts.
+//
+// Usage:
+//
+//	ciolite [-config file] [-format json|table] [-host url] <resource> <action> [-p key=value ...] [args...]
+//
+// The configuration is read from the -config json or yaml file ({"key": "...", "secret": "...", "host": "..."}, see ciolite.LoadConfig),
+// or else from the CIO_API_KEY and CIO_API_SECRET (or CONTEXTIO_API_KEY and CONTEXTIO_API_SECRET) and other CIO_API_*
+// environment variables (see ciolite.ConfigFromEnv), or else from ~/.ciolite.json. Run "ciolite help" to list every resource and action.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/contextio/contextio-go/ciolite"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Getenv, os.Stdout, os.Stderr, nil); err != nil {
+		fmt.Fprintln(os.Stderr, "ciolite:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command line, writing results to stdout and usage to stderr.
+// If client is nil, one is created from the environment or config file.
+func run(args []string, getenv func(string) string, stdout io.Writer, stderr io.Writer, client ciolite.Interface) error {
+
+	flags := flag.NewFlagSet("ciolite", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "json or yaml config file with key, secret, and host (default: the environment, or else ~/.ciolite.json)")
+	format := flags.String("format", "json", "output format: json or table")
+	host := flags.String("host", "", "api host, overriding the config")
+	flags.Usage = func() { usage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 || flags.Arg(0) == "help" {
+		usage(stderr, flags)
+		return nil
+	}
+	if flags.NArg() < 2 {
+		return errors.Errorf("Missing action for %s", flags.Arg(0))
+	}
+	if *format != "json" && *format != "table" {
+		return errors.Errorf("Unknown format: %s", *format)
+	}
+
+	cmd, ok := findCommand(flags.Arg(0), flags.Arg(1))
+	if !ok {
+		return errors.Errorf("Unknown command: %s %s", flags.Arg(0), flags.Arg(1))
+	}
+
+	// Action flags: repeated -p key=value params
+	var params paramList
+	actionFlags := flag.NewFlagSet(cmd.resource+" "+cmd.action, flag.ContinueOnError)
+	actionFlags.SetOutput(stderr)
+	actionFlags.Var(&params, "p", "request parameter as key=value (may be repeated)")
+	actionFlags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ciolite %s %s [-p key=value ...] %s\n%s\n", cmd.resource, cmd.action, argsUsage(cmd.args), cmd.help)
+	}
+	if err := actionFlags.Parse(flags.Args()[2:]); err != nil {
+		return err
+	}
+	if actionFlags.NArg() != len(cmd.args) {
+		actionFlags.Usage()
+		return errors.Errorf("Expected %d arguments, got %d", len(cmd.args), actionFlags.NArg())
+	}
+
+	if client == nil {
+		cioLite, err := newClient(*configPath, *host, getenv)
+		if err != nil {
+			return err
+		}
+		client = cioLite
+	}
+
+	result, err := cmd.run(client, actionFlags.Args(), params.apply)
+	if err != nil {
+		return err
+	}
+
+	// Raw messages are written as they are
+	if raw, ok := result.(ciolite.GetUserEmailAccountsFolderMessageRawResponse); ok {
+		_, err = io.WriteString(stdout, string(raw))
+		return errors.Wrap(err, "Unable to write output")
+	}
+	if *format == "table" {
+		return writeTable(stdout, result)
+	}
+	return writeJSON(stdout, result)
+}
+
+// newClient returns a client configured from the config file if there is one, or else from the environment,
+// or else from ~/.ciolite.json, with the host overridden if set
+func newClient(configPath string, host string, getenv func(string) string) (ciolite.CioLite, error) {
+
+	var (
+		config ciolite.Config
+		err    error
+	)
+	if len(configPath) > 0 {
+		config, err = ciolite.LoadConfig(configPath)
+	} else if config, err = ciolite.ConfigFromEnv(getenv); err != nil && len(getenv("HOME")) > 0 {
+		defaultPath := filepath.Join(getenv("HOME"), ".ciolite.json")
+		if _, statErr := os.Stat(defaultPath); statErr == nil {
+			config, err = ciolite.LoadConfig(defaultPath)
+		}
+	}
+	if err != nil {
+		return ciolite.CioLite{}, errors.Wrap(err, "Missing or invalid credentials: set CIO_API_KEY and CIO_API_SECRET, or use -config")
+	}
+
+	if len(host) > 0 {
+		config.Host = host
+	}
+	return config.New()
+}
+
+// usage writes the usage and the list of commands
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: ciolite [flags] <resource> <action> [-p key=value ...] [args...]")
+	fmt.Fprintln(w, "\nFlags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-40s %s\n", strings.TrimSpace(cmd.resource+" "+cmd.action+" "+argsUsage(cmd.args)), cmd.help)
+	}
+}
+
+// argsUsage returns the positional arguments as <name> placeholders
+func argsUsage(args []string) string {
+	var names []string
+	for _, arg := range args {
+		names = append(names, "<"+arg+">")
+	}
+	return strings.Join(names, " ")
+}
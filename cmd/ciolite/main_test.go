@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/contextio/contextio-go/ciolite"
+)
+
+// TestSimulatedRun tests running commands against a simulated server
+func TestSimulatedRun(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("email") != "jane@example.com" || r.URL.Query().Get("limit") != "5" {
+			http.Error(w, `{"type": "error", "value": "bad query"}`, http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `[{"id": "u1", "email_addresses": ["jane@example.com"], "first_name": "Jane", "created": 1500000000}]`)
+	})
+	mux.HandleFunc("/lite/users/u1/email_accounts/0/folders/INBOX/messages/<1@x>/raw", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "Subject: Hi\r\n\r\nHello\r\n")
+	})
+	cioLite, testServer := ciolite.NewTestCioLiteServer(mux)
+	defer testServer.Close()
+
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"-format", "table", "users", "list", "-p", "email=jane@example.com", "-p", "limit=5"}, os.Getenv, &stdout, &stderr, cioLite)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if err != nil || len(lines) != 2 || !strings.HasPrefix(lines[0], "ID ") || !strings.Contains(lines[1], "Jane") || !strings.Contains(lines[1], "1500000000") {
+		t.Error("Expected a table with a header and one user; Got: ", stdout.String(), "; With Error: ", err)
+	}
+
+	stdout.Reset()
+	err = run([]string{"raw", "get", "u1", "0", "INBOX", "<1@x>"}, os.Getenv, &stdout, &stderr, cioLite)
+	if err != nil || stdout.String() != "Subject: Hi\r\n\r\nHello\r\n" {
+		t.Error("Expected the raw message; Got: ", stdout.String(), "; With Error: ", err)
+	}
+
+	for _, args := range [][]string{
+		{"users", "list", "-p", "nope=1"},
+		{"users", "list", "-p", "limit=many"},
+		{"users", "get"},
+		{"users", "explode"},
+	} {
+		if err = run(args, os.Getenv, &stdout, &stderr, cioLite); err == nil {
+			t.Error("Expected an error for: ", args)
+		}
+	}
+}
+
+// TestNewClient tests configuring the client from the environment, from the default config file, and from -config
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	// Answers with the consumer key the request was signed with
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lite/users/", func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		start := strings.Index(authorization, `oauth_consumer_key="`) + len(`oauth_consumer_key="`)
+		io.WriteString(w, `{"id": "`+authorization[start:start+strings.Index(authorization[start:], `"`)]+`"}`)
+	})
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	consumerKey := func(cioLite ciolite.CioLite) string {
+		user, err := cioLite.GetUser("u1")
+		if err != nil {
+			t.Error(err)
+		}
+		return user.ID
+	}
+
+	env := map[string]string{"CIO_API_KEY": "k", "CIO_API_SECRET": "s", "CIO_API_HOST": testServer.URL}
+	getenv := func(key string) string { return env[key] }
+	if cioLite, err := newClient("", "", getenv); err != nil || consumerKey(cioLite) != "k" {
+		t.Error("Expected credentials from the environment; With Error: ", err)
+	}
+
+	dir, err := ioutil.TempDir("", "ciolite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	env = map[string]string{"HOME": dir}
+	if _, err = newClient("", "", getenv); err == nil {
+		t.Error("Expected a missing credentials error")
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, ".ciolite.json"), []byte(`{"key": "fk", "secret": "fs", "host": "https://example.com"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cioLite, err := newClient("", testServer.URL, getenv); err != nil || consumerKey(cioLite) != "fk" {
+		t.Error("Expected credentials from the default config file, with the host overridden; With Error: ", err)
+	}
+
+	// -config is preferred over the environment, and may be yaml
+	yamlPath := filepath.Join(dir, "ciolite.yaml")
+	if err = ioutil.WriteFile(yamlPath, []byte("key: yk\nsecret: ys\nhost: "+testServer.URL+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env = map[string]string{"CIO_API_KEY": "k", "CIO_API_SECRET": "s"}
+	if cioLite, err := newClient(yamlPath, "", getenv); err != nil || consumerKey(cioLite) != "yk" || cioLite.Host != testServer.URL {
+		t.Error("Expected credentials from the yaml config file; With Error: ", err)
+	}
+	if _, err = newClient(filepath.Join(dir, "missing.json"), "", getenv); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
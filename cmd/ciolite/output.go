@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// paramList collects repeated -p key=value flags
+type paramList []string
+
+// String returns the params, for the flag package
+func (p *paramList) String() string {
+	return strings.Join(*p, ",")
+}
+
+// Set adds a key=value param
+func (p *paramList) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return errors.Errorf("Param must be key=value: %s", value)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+// apply sets the fields of the params struct dst, matching each key to a field's json name
+func (p paramList) apply(dst interface{}) error {
+
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		fields[jsonName(t.Field(i))] = i
+	}
+
+	for _, param := range p {
+		parts := strings.SplitN(param, "=", 2)
+		key, value := parts[0], parts[1]
+
+		i, ok := fields[key]
+		if !ok {
+			var names []string
+			for name := range fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return errors.Errorf("Unknown param %s; expected one of: %s", key, strings.Join(names, ", "))
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return errors.Errorf("Param %s must be true or false: %s", key, value)
+			}
+			field.SetBool(b)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return errors.Errorf("Param %s must be a number: %s", key, value)
+			}
+			field.SetInt(int64(n))
+		default:
+			return errors.Errorf("Param %s cannot be set from the command line", key)
+		}
+	}
+	return nil
+}
+
+// jsonName returns the json name of the struct field
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if len(name) == 0 {
+		return field.Name
+	}
+	return name
+}
+
+// writeJSON writes the result as indented json
+func writeJSON(w io.Writer, result interface{}) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Unable to marshal output")
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return errors.Wrap(err, "Unable to write output")
+}
+
+// writeTable writes the result (a struct, or a slice of structs) as a table,
+// with a column for every field that is a string, number, or bool
+func writeTable(w io.Writer, result interface{}) error {
+
+	v := reflect.ValueOf(result)
+	rows := []reflect.Value{v}
+	if v.Kind() == reflect.Slice {
+		rows = nil
+		for i := 0; i < v.Len(); i++ {
+			rows = append(rows, v.Index(i))
+		}
+	}
+
+	elemType := v.Type()
+	if elemType.Kind() == reflect.Slice {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return writeJSON(w, result)
+	}
+
+	var columns []int
+	var names []string
+	for i := 0; i < elemType.NumField(); i++ {
+		switch elemType.Field(i).Type.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+			columns = append(columns, i)
+			names = append(names, strings.ToUpper(jsonName(elemType.Field(i))))
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(names, "\t"))
+	for _, row := range rows {
+		var cells []string
+		for _, i := range columns {
+			cells = append(cells, fmt.Sprint(row.Field(i).Interface()))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return errors.Wrap(tw.Flush(), "Unable to write output")
+}